@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IAttendanceExceptionController interface {
+	GetExceptions(c echo.Context) error
+	ResolveException(c echo.Context) error
+	DetectAnomalies(c echo.Context) error
+	GetHolidays(c echo.Context) error
+	CreateHoliday(c echo.Context) error
+	DeleteHoliday(c echo.Context) error
+}
+
+type attendanceExceptionController struct {
+	aeu usecase.IAttendanceExceptionUsecase
+}
+
+func NewAttendanceExceptionController(aeu usecase.IAttendanceExceptionUsecase) IAttendanceExceptionController {
+	return &attendanceExceptionController{aeu}
+}
+
+// GetExceptions は ?status=open|resolved&department= で絞り込む
+func (aec *attendanceExceptionController) GetExceptions(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	exceptionsRes, err := aec.aeu.GetExceptions(uint(userId.(float64)), c.QueryParam("status"), c.QueryParam("department"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, exceptionsRes)
+}
+
+func (aec *attendanceExceptionController) ResolveException(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("exceptionId")
+	exceptionId, _ := strconv.Atoi(id)
+
+	req := struct {
+		Note string `json:"note"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	exceptionRes, err := aec.aeu.ResolveException(uint(userId.(float64)), uint(exceptionId), req.Note)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, exceptionRes)
+}
+
+// DetectAnomalies は定期実行を待たずに検知を実行する
+func (aec *attendanceExceptionController) DetectAnomalies(c echo.Context) error {
+	detected, err := aec.aeu.DetectAnomalies(time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]int64{"detected": detected})
+}
+
+func (aec *attendanceExceptionController) GetHolidays(c echo.Context) error {
+	holidaysRes, err := aec.aeu.GetHolidays()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, holidaysRes)
+}
+
+func (aec *attendanceExceptionController) CreateHoliday(c echo.Context) error {
+	req := struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	if err != nil {
+		return model.NewBadRequestError("invalid_date", "invalid date format")
+	}
+	holidayRes, err := aec.aeu.CreateHoliday(model.Holiday{Date: date, Name: req.Name})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, holidayRes)
+}
+
+func (aec *attendanceExceptionController) DeleteHoliday(c echo.Context) error {
+	id := c.Param("holidayId")
+	holidayId, _ := strconv.Atoi(id)
+
+	if err := aec.aeu.DeleteHoliday(uint(holidayId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
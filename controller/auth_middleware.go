@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"go-rest-api/usecase"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// IAuthMiddleware は JWT の検証後に呼び出し元のユーザーを読み込んで権限を確認するミドルウェア
+type IAuthMiddleware interface {
+	RequireActive(next echo.HandlerFunc) echo.HandlerFunc
+	RequireRole(roles ...string) echo.MiddlewareFunc
+}
+
+type authMiddleware struct {
+	uu usecase.IUserUsecase
+}
+
+func NewAuthMiddleware(uu usecase.IUserUsecase) IAuthMiddleware {
+	return &authMiddleware{uu}
+}
+
+// RequireActive は無効化・削除されたユーザーのトークンを、有効期限内であっても拒否する
+func (am *authMiddleware) RequireActive(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user := c.Get("user").(*jwt.Token)
+		claims := user.Claims.(jwt.MapClaims)
+		userId := claims["user_id"]
+
+		if err := am.uu.CheckActive(uint(userId.(float64))); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+// RequireRole は呼び出し元のロールが roles のいずれかである場合のみ次のハンドラーを呼ぶ
+func (am *authMiddleware) RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get("user").(*jwt.Token)
+			claims := user.Claims.(jwt.MapClaims)
+			userId := claims["user_id"]
+
+			if err := am.uu.Authorize(uint(userId.(float64)), roles...); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
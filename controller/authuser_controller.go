@@ -28,11 +28,11 @@ func NewAuthUserController(auu usecase.IAuthUserUsecase) IAuthUserController {
 func (auc *authUserController) SignUp(c echo.Context) error {
 	authUser := model.AuthUser{}
 	if err := c.Bind(&authUser); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	authUserRes, err := auc.auu.SignUp(authUser)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusCreated, authUserRes)
 }
@@ -40,11 +40,11 @@ func (auc *authUserController) SignUp(c echo.Context) error {
 func (auc *authUserController) LogIn(c echo.Context) error {
 	authUser := model.AuthUser{}
 	if err := c.Bind(&authUser); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	tokenString, err := auc.auu.Login(authUser)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	cookie := new(http.Cookie)
 	cookie.Name = "token"
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IBillingController interface {
+	GetBillingReport(c echo.Context) error
+}
+
+type billingController struct {
+	bu usecase.IBillingUsecase
+}
+
+func NewBillingController(bu usecase.IBillingUsecase) IBillingController {
+	return &billingController{bu}
+}
+
+// writeBillingCSV は請求明細を1行1明細の CSV で返す
+func writeBillingCSV(c echo.Context, report model.BillingReportResponse) error {
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="billing-%s-%s.csv"`, report.From.Format("20060102"), report.To.AddDate(0, 0, -1).Format("20060102")))
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	header := []string{"client", "period_from", "period_to", "project_code", "project_name", "role", "hourly_rate", "billable_hours", "non_billable_hours", "amount"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	formatFloat := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	for _, invoice := range report.Invoices {
+		for _, line := range invoice.Lines {
+			record := []string{
+				invoice.Client,
+				invoice.PeriodFrom.Format("2006-01-02"),
+				invoice.PeriodTo.AddDate(0, 0, -1).Format("2006-01-02"),
+				line.ProjectCode,
+				line.ProjectName,
+				line.Role,
+				formatFloat(line.HourlyRate),
+				formatFloat(line.BillableHours),
+				formatFloat(line.NonBillableHours),
+				formatFloat(line.Amount),
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (bc *billingController) GetBillingReport(c echo.Context) error {
+	// ?from=YYYY-MM-DD&to=YYYY-MM-DD（to を含む）。省略時は今月
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, 0)
+	if v := c.QueryParam("from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return model.NewBadRequestError("invalid_date", "invalid from format")
+		}
+		from = d
+	}
+	if v := c.QueryParam("to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return model.NewBadRequestError("invalid_date", "invalid to format")
+		}
+		to = d.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		return model.NewBadRequestError("invalid_range", "to must not be before from")
+	}
+
+	report, err := bc.bu.GetBillingReport(from, to, c.QueryParam("client"))
+	if err != nil {
+		return err
+	}
+	if c.QueryParam("format") == "csv" {
+		return writeBillingCSV(c, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IEmploymentTypeController interface {
+	GetAllEmploymentTypes(c echo.Context) error
+	GetEmploymentTypeById(c echo.Context) error
+	CreateEmploymentType(c echo.Context) error
+	UpdateEmploymentType(c echo.Context) error
+	DeleteEmploymentType(c echo.Context) error
+}
+
+type employmentTypeController struct {
+	etu usecase.IEmploymentTypeUsecase
+}
+
+func NewEmploymentTypeController(etu usecase.IEmploymentTypeUsecase) IEmploymentTypeController {
+	return &employmentTypeController{etu}
+}
+
+func (etc *employmentTypeController) GetAllEmploymentTypes(c echo.Context) error {
+	typesRes, err := etc.etu.GetAllEmploymentTypes()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, typesRes)
+}
+
+func (etc *employmentTypeController) GetEmploymentTypeById(c echo.Context) error {
+	id := c.Param("typeId")
+	typeId, _ := strconv.Atoi(id)
+	typeRes, err := etc.etu.GetEmploymentTypeById(uint(typeId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, typeRes)
+}
+
+func (etc *employmentTypeController) CreateEmploymentType(c echo.Context) error {
+	employmentType := model.EmploymentType{}
+	if err := c.Bind(&employmentType); err != nil {
+		return err
+	}
+	typeRes, err := etc.etu.CreateEmploymentType(employmentType)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, typeRes)
+}
+
+func (etc *employmentTypeController) UpdateEmploymentType(c echo.Context) error {
+	id := c.Param("typeId")
+	typeId, _ := strconv.Atoi(id)
+
+	employmentType := model.EmploymentType{}
+	if err := c.Bind(&employmentType); err != nil {
+		return err
+	}
+	typeRes, err := etc.etu.UpdateEmploymentType(employmentType, uint(typeId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, typeRes)
+}
+
+func (etc *employmentTypeController) DeleteEmploymentType(c echo.Context) error {
+	id := c.Param("typeId")
+	typeId, _ := strconv.Atoi(id)
+
+	if err := etc.etu.DeleteEmploymentType(uint(typeId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"go-rest-api/model"
+	"net/http"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+)
+
+const mimeApplicationProblemJSON = "application/problem+json"
+
+var errorKindStatus = map[string]int{
+	model.ErrorKindBadRequest:   http.StatusBadRequest,
+	model.ErrorKindUnauthorized: http.StatusUnauthorized,
+	model.ErrorKindForbidden:    http.StatusForbidden,
+	model.ErrorKindNotFound:     http.StatusNotFound,
+	model.ErrorKindConflict:     http.StatusConflict,
+	model.ErrorKindInvalid:      http.StatusUnprocessableEntity,
+}
+
+// HTTPErrorHandler はハンドラーが返したエラーを RFC 7807 の problem+json で返す。
+// 想定外のエラーは内部の情報を含むことがあるため、内容はログにだけ残す
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	problem := toProblem(err)
+	if problem.Status >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+	problem.Instance = c.Request().URL.Path
+	c.Response().Header().Set(echo.HeaderContentType, mimeApplicationProblemJSON)
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(problem.Status)
+	} else {
+		err = c.JSON(problem.Status, problem)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+func toProblem(err error) model.ProblemDetails {
+	var domainErr *model.DomainError
+	var verrs validation.Errors
+	var verr validation.Error
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.As(err, &domainErr):
+		status, ok := errorKindStatus[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		return newProblem(status, domainErr.Code, domainErr.Message)
+	case errors.As(err, &verrs):
+		problem := newProblem(http.StatusUnprocessableEntity, "validation_failed", "request has invalid fields")
+		problem.Errors = fieldErrors("", verrs)
+		return problem
+	case errors.As(err, &verr):
+		return newProblem(http.StatusUnprocessableEntity, "validation_failed", verr.Error())
+	case errors.As(err, &httpErr):
+		detail := ""
+		if msg, ok := httpErr.Message.(string); ok {
+			detail = msg
+		}
+		return newProblem(httpErr.Code, statusCode(httpErr.Code), detail)
+	}
+	return newProblem(http.StatusInternalServerError, "internal_error", "")
+}
+
+func newProblem(status int, code string, detail string) model.ProblemDetails {
+	return model.ProblemDetails{
+		Type:   "/problems/" + strings.ReplaceAll(code, "_", "-"),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// statusCode は echo が返す HTTP エラーのステータスから安定したコードを作る（例: 405 → method_not_allowed）
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return fmt.Sprintf("http_%d", status)
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
+
+// fieldErrors は入れ子になった検証エラーを "rates.0.role" のような項目名で平らにする
+func fieldErrors(prefix string, verrs validation.Errors) []model.ProblemFieldError {
+	keys := make([]string, 0, len(verrs))
+	for k := range verrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := []model.ProblemFieldError{}
+	for _, k := range keys {
+		field := k
+		if prefix != "" {
+			field = prefix + "." + k
+		}
+		var nested validation.Errors
+		var verr validation.Error
+		switch err := verrs[k]; {
+		case errors.As(err, &nested):
+			res = append(res, fieldErrors(field, nested)...)
+		case errors.As(err, &verr):
+			res = append(res, model.ProblemFieldError{Field: field, Code: verr.Code(), Message: verr.Error()})
+		default:
+			res = append(res, model.ProblemFieldError{Field: field, Code: "validation_invalid", Message: err.Error()})
+		}
+	}
+	return res
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IHolidayWorkRequestController interface {
+	GetMyRequests(c echo.Context) error
+	GetRequests(c echo.Context) error
+	CreateRequest(c echo.Context) error
+	ApproveRequest(c echo.Context) error
+	RejectRequest(c echo.Context) error
+	DeleteRequest(c echo.Context) error
+}
+
+type holidayWorkRequestController struct {
+	hu usecase.IHolidayWorkRequestUsecase
+}
+
+func NewHolidayWorkRequestController(hu usecase.IHolidayWorkRequestUsecase) IHolidayWorkRequestController {
+	return &holidayWorkRequestController{hu}
+}
+
+func (hc *holidayWorkRequestController) GetMyRequests(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	requestsRes, err := hc.hu.GetMyRequests(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, requestsRes)
+}
+
+// GetRequests は ?status=pending|approved|rejected&department= で絞り込む
+func (hc *holidayWorkRequestController) GetRequests(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	requestsRes, err := hc.hu.GetRequests(uint(userId.(float64)), c.QueryParam("status"), c.QueryParam("department"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, requestsRes)
+}
+
+// CreateRequest は {"date": "YYYY-MM-DD", "reason": ...} で申請する
+func (hc *holidayWorkRequestController) CreateRequest(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := struct {
+		Date   string `json:"date"`
+		Reason string `json:"reason"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	if err != nil {
+		return model.NewBadRequestError("invalid_date", "invalid date format")
+	}
+	requestRes, err := hc.hu.CreateRequest(model.HolidayWorkRequest{
+		UserID: uint(userId.(float64)),
+		Date:   date,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, requestRes)
+}
+
+func (hc *holidayWorkRequestController) ApproveRequest(c echo.Context) error {
+	return hc.reviewRequest(c, true)
+}
+
+func (hc *holidayWorkRequestController) RejectRequest(c echo.Context) error {
+	return hc.reviewRequest(c, false)
+}
+
+func (hc *holidayWorkRequestController) reviewRequest(c echo.Context, approve bool) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	requestId, err := strconv.Atoi(c.Param("requestId"))
+	if err != nil {
+		return model.NewBadRequestError("invalid_request_id", "invalid request id")
+	}
+
+	requestRes, err := hc.hu.ReviewRequest(uint(userId.(float64)), uint(requestId), approve)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, requestRes)
+}
+
+func (hc *holidayWorkRequestController) DeleteRequest(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	requestId, err := strconv.Atoi(c.Param("requestId"))
+	if err != nil {
+		return model.NewBadRequestError("invalid_request_id", "invalid request id")
+	}
+
+	if err := hc.hu.DeleteRequest(uint(userId.(float64)), uint(requestId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IInvitationController interface {
+	GetPendingInvitations(c echo.Context) error
+	CreateInvitation(c echo.Context) error
+	ResendInvitation(c echo.Context) error
+	CancelInvitation(c echo.Context) error
+	ImportUsers(c echo.Context) error
+	AcceptInvitation(c echo.Context) error
+}
+
+type invitationController struct {
+	iu usecase.IInvitationUsecase
+}
+
+func NewInvitationController(iu usecase.IInvitationUsecase) IInvitationController {
+	return &invitationController{iu}
+}
+
+func (ic *invitationController) GetPendingInvitations(c echo.Context) error {
+	invitationsRes, err := ic.iu.GetPendingInvitations()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, invitationsRes)
+}
+
+func (ic *invitationController) CreateInvitation(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	floatUserId, ok := claims["user_id"].(float64)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+	userId := uint(floatUserId)
+
+	invitation := model.Invitation{}
+	if err := c.Bind(&invitation); err != nil {
+		return err
+	}
+	invitation.InvitedBy = &userId
+	invitationRes, err := ic.iu.CreateInvitation(invitation)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, invitationRes)
+}
+
+func (ic *invitationController) ResendInvitation(c echo.Context) error {
+	id := c.Param("invitationId")
+	invitationId, _ := strconv.Atoi(id)
+
+	invitationRes, err := ic.iu.ResendInvitation(uint(invitationId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, invitationRes)
+}
+
+func (ic *invitationController) CancelInvitation(c echo.Context) error {
+	id := c.Param("invitationId")
+	invitationId, _ := strconv.Atoi(id)
+
+	if err := ic.iu.CancelInvitation(uint(invitationId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ic *invitationController) ImportUsers(c echo.Context) error {
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+
+	// multipart の file フィールド、または text/csv のリクエストボディを受け付ける
+	var body io.Reader = c.Request().Body
+	if file, err := c.FormFile("file"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			return model.NewBadRequestError("invalid_file", "failed to open uploaded file: %v", err)
+		}
+		defer src.Close()
+		body = src
+	}
+
+	importRes, err := ic.iu.ImportUsersCSV(body, dryRun)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, importRes)
+}
+
+func (ic *invitationController) AcceptInvitation(c echo.Context) error {
+	type AcceptInvitationRequest struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	var req AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	userRes, err := ic.iu.AcceptInvitation(req.Token, req.Password)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, userRes)
+}
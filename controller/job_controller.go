@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IJobController interface {
+	GetJobs(c echo.Context) error
+	GetRuns(c echo.Context) error
+	TriggerJob(c echo.Context) error
+	UpdateJob(c echo.Context) error
+}
+
+type jobController struct {
+	ju usecase.IJobUsecase
+}
+
+func NewJobController(ju usecase.IJobUsecase) IJobController {
+	return &jobController{ju}
+}
+
+func (jc *jobController) GetJobs(c echo.Context) error {
+	jobsRes, err := jc.ju.GetJobs()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, jobsRes)
+}
+
+func (jc *jobController) GetRuns(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	runsRes, err := jc.ju.GetRuns(c.Param("name"), limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, runsRes)
+}
+
+func (jc *jobController) TriggerJob(c echo.Context) error {
+	if err := jc.ju.TriggerJob(c.Param("name")); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (jc *jobController) UpdateJob(c echo.Context) error {
+	req := struct {
+		Enabled *bool `json:"enabled"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Enabled == nil {
+		return model.NewBadRequestError("enabled_required", "enabled is required")
+	}
+	jobRes, err := jc.ju.SetEnabled(c.Param("name"), *req.Enabled)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, jobRes)
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ILeaveController interface {
+	GetLeaves(c echo.Context) error
+	GetBalance(c echo.Context) error
+	CreateLeave(c echo.Context) error
+	DeleteLeave(c echo.Context) error
+}
+
+type leaveController struct {
+	lu usecase.ILeaveUsecase
+}
+
+func NewLeaveController(lu usecase.ILeaveUsecase) ILeaveController {
+	return &leaveController{lu}
+}
+
+// GetLeaves は ?from=&to=（YYYY-MM-DD）で期間を絞り込む
+func (lc *leaveController) GetLeaves(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return err
+	}
+	leavesRes, err := lc.lu.GetLeaves(uint(userId.(float64)), from, to)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, leavesRes)
+}
+
+func (lc *leaveController) GetBalance(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	balanceRes, err := lc.lu.GetBalance(uint(userId.(float64)), time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, balanceRes)
+}
+
+func (lc *leaveController) CreateLeave(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := struct {
+		Date string  `json:"date"`
+		Days float64 `json:"days"`
+		Note string  `json:"note"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	if err != nil {
+		return model.NewBadRequestError("invalid_date", "invalid date format")
+	}
+	leaveRes, err := lc.lu.CreateLeave(model.LeaveRecord{UserID: uint(userId.(float64)), Date: date, Days: req.Days, Note: req.Note})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, leaveRes)
+}
+
+func (lc *leaveController) DeleteLeave(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("leaveId")
+	leaveId, err := strconv.Atoi(id)
+	if err != nil {
+		return model.NewBadRequestError("invalid_leave_id", "invalid leaveId")
+	}
+
+	if err := lc.lu.DeleteLeave(uint(userId.(float64)), uint(leaveId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
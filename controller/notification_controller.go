@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type INotificationController interface {
+	GetNotifications(c echo.Context) error
+	CountUnread(c echo.Context) error
+	MarkRead(c echo.Context) error
+	MarkAllRead(c echo.Context) error
+	GetSettings(c echo.Context) error
+	UpdateSettings(c echo.Context) error
+}
+
+type notificationController struct {
+	nu usecase.INotificationUsecase
+}
+
+func NewNotificationController(nu usecase.INotificationUsecase) INotificationController {
+	return &notificationController{nu}
+}
+
+// リマインダーのタイミングを省略（null）すると既定値に戻る
+type notificationSettingsRequest struct {
+	Locale                  string                         `json:"locale"`
+	WebhookURL              string                         `json:"webhook_url"`
+	ClockInReminderMinutes  *int                           `json:"clock_in_reminder_minutes"`
+	ClockOutReminderMinutes *int                           `json:"clock_out_reminder_minutes"`
+	Preferences             []model.NotificationPreference `json:"preferences"`
+}
+
+// GetNotifications は ?unread=true で未読のみに絞り込む
+func (nc *notificationController) GetNotifications(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	notificationsRes, err := nc.nu.GetNotifications(uint(userId.(float64)), c.QueryParam("unread") == "true")
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, notificationsRes)
+}
+
+func (nc *notificationController) CountUnread(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	count, err := nc.nu.CountUnread(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]int64{"unread": count})
+}
+
+func (nc *notificationController) MarkRead(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("notificationId")
+	notificationId, _ := strconv.Atoi(id)
+
+	if err := nc.nu.MarkRead(uint(userId.(float64)), uint(notificationId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (nc *notificationController) MarkAllRead(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	if err := nc.nu.MarkAllRead(uint(userId.(float64))); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (nc *notificationController) GetSettings(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	settingsRes, err := nc.nu.GetSettings(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, settingsRes)
+}
+
+func (nc *notificationController) UpdateSettings(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := notificationSettingsRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	setting := model.NotificationSetting{
+		UserID:                  uint(userId.(float64)),
+		Locale:                  req.Locale,
+		WebhookURL:              req.WebhookURL,
+		ClockInReminderMinutes:  req.ClockInReminderMinutes,
+		ClockOutReminderMinutes: req.ClockOutReminderMinutes,
+	}
+	settingsRes, err := nc.nu.UpdateSettings(setting, req.Preferences)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, settingsRes)
+}
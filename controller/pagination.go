@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"encoding/json"
+	"go-rest-api/model"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parsePageQuery は cursor と limit を読み取る。limit を省略した場合は既定の件数を返す
+func parsePageQuery(c echo.Context) (model.PageQuery, error) {
+	page := model.PageQuery{Cursor: c.QueryParam("cursor"), Limit: model.DefaultPageLimit}
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > model.MaxPageLimit {
+			return page, model.NewBadRequestError("invalid_limit", "limit must be between 1 and %d", model.MaxPageLimit)
+		}
+		page.Limit = limit
+	}
+	return page, nil
+}
+
+// parseDateRange は from, to（YYYY-MM-DD）を読み取る。to はその日を含む（翌日0時未満）
+func parseDateRange(c echo.Context) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if v := c.QueryParam("from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return nil, nil, model.NewBadRequestError("invalid_date", "invalid from format")
+		}
+		from = &d
+	}
+	if v := c.QueryParam("to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return nil, nil, model.NewBadRequestError("invalid_date", "invalid to format")
+		}
+		d = d.AddDate(0, 0, 1)
+		to = &d
+	}
+	if from != nil && to != nil && !to.After(*from) {
+		return nil, nil, model.NewBadRequestError("invalid_range", "to must not be before from")
+	}
+	return from, to, nil
+}
+
+// pageResponse は一覧のレスポンスを作る。fields が指定された場合は各要素をその項目だけに絞る
+func pageResponse(c echo.Context, data interface{}, page model.PageInfo) (model.PageResponse, error) {
+	fields := c.QueryParam("fields")
+	if fields == "" {
+		return model.PageResponse{Data: data, Page: page}, nil
+	}
+	known := jsonFieldNames(reflect.TypeOf(data).Elem())
+	selected := []string{}
+	for _, f := range strings.Split(fields, ",") {
+		f = strings.TrimSpace(f)
+		if !known[f] {
+			return model.PageResponse{}, model.NewBadRequestError("invalid_fields", "unknown field: %s", f)
+		}
+		selected = append(selected, f)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return model.PageResponse{}, err
+	}
+	items := []map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &items); err != nil {
+		return model.PageResponse{}, err
+	}
+	res := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		res[i] = map[string]json.RawMessage{}
+		for _, f := range selected {
+			if v, ok := item[f]; ok {
+				res[i][f] = v
+			}
+		}
+	}
+	return model.PageResponse{Data: res, Page: page}, nil
+}
+
+// jsonFieldNames はレスポンスの構造体が JSON に出力する項目名を返す
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		if name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
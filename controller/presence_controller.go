@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-rest-api/usecase"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IPresenceController interface {
+	GetSnapshot(c echo.Context) error
+	StreamPresence(c echo.Context) error
+}
+
+type presenceController struct {
+	pu usecase.IPresenceUsecase
+}
+
+func NewPresenceController(pu usecase.IPresenceUsecase) IPresenceController {
+	return &presenceController{pu}
+}
+
+// writeSSE は Server-Sent Events の1イベントを書き込んで送信する
+func writeSSE(c echo.Context, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.Response(), "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
+
+func (pc *presenceController) GetSnapshot(c echo.Context) error {
+	snapshot, err := pc.pu.GetSnapshot(c.QueryParam("department"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
+
+// StreamPresence は接続時に現在の在席状況（snapshot）を送り、以降は変化（presence）を送り続ける
+func (pc *presenceController) StreamPresence(c echo.Context) error {
+	department := c.QueryParam("department")
+	// 取りこぼしを防ぐため、スナップショットを取得する前に購読を始める
+	events, unsubscribe := pc.pu.Subscribe(department)
+	defer unsubscribe()
+
+	snapshot, err := pc.pu.GetSnapshot(department)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	c.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+	if err := writeSSE(c, "snapshot", snapshot); err != nil {
+		return nil
+	}
+
+	// プロキシに切断されないよう定期的にコメント行を送る
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case event, ok := <-events:
+			// サーバーの停止中はハブが閉じられるため、クライアントの切断を待たずに終える
+			if !ok {
+				return nil
+			}
+			if err := writeSSE(c, "presence", event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Response(), ": ping\n\n"); err != nil {
+				return nil
+			}
+			c.Response().Flush()
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IPrivacyController interface {
+	ExportMyData(c echo.Context) error
+	ExportUserData(c echo.Context) error
+	EraseUserData(c echo.Context) error
+}
+
+type privacyController struct {
+	pu usecase.IPrivacyUsecase
+}
+
+func NewPrivacyController(pu usecase.IPrivacyUsecase) IPrivacyController {
+	return &privacyController{pu}
+}
+
+// writeExportArchive はデータ種別ごとに JSON ファイルを分けた zip を返す
+func writeExportArchive(c echo.Context, export model.PersonalDataExport) error {
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="personal-data-%d-%s.zip"`, export.Profile.ID, export.ExportedAt.Format("20060102")))
+	c.Response().WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(c.Response())
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"export.json", export},
+		{"profile.json", export.Profile},
+		{"attendance_records.json", export.AttendanceRecords},
+		{"tasks.json", export.Tasks},
+		{"time_entries.json", export.TimeEntries},
+		{"reporting_lines.json", export.ReportingLines},
+		{"invitations.json", export.Invitations},
+		{"timesheets.json", export.Timesheets},
+		{"task_comments.json", export.TaskComments},
+		{"task_activities.json", export.TaskActivities},
+		{"notifications.json", export.Notifications},
+		{"leave_records.json", export.LeaveRecords},
+		{"holiday_work_requests.json", export.HolidayWorkRequests},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (pc *privacyController) ExportMyData(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	floatUserId, ok := claims["user_id"].(float64)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+	userId := uint(floatUserId)
+
+	export, err := pc.pu.ExportPersonalData(userId)
+	if err != nil {
+		return err
+	}
+	return writeExportArchive(c, export)
+}
+
+func (pc *privacyController) ExportUserData(c echo.Context) error {
+	id := c.Param("userId")
+	userId, _ := strconv.Atoi(id)
+
+	export, err := pc.pu.ExportPersonalData(uint(userId))
+	if err != nil {
+		return err
+	}
+	return writeExportArchive(c, export)
+}
+
+func (pc *privacyController) EraseUserData(c echo.Context) error {
+	id := c.Param("userId")
+	userId, _ := strconv.Atoi(id)
+
+	if err := pc.pu.ErasePersonalData(uint(userId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IProjectController interface {
+	GetAllProjects(c echo.Context) error
+	GetProjectById(c echo.Context) error
+	CreateProject(c echo.Context) error
+	UpdateProject(c echo.Context) error
+	DeleteProject(c echo.Context) error
+	ReplaceRates(c echo.Context) error
+	ReplaceMembers(c echo.Context) error
+	GetBurndown(c echo.Context) error
+	GetBudgetAlerts(c echo.Context) error
+}
+
+type projectController struct {
+	pu  usecase.IProjectUsecase
+	pbu usecase.IProjectBudgetUsecase
+}
+
+func NewProjectController(pu usecase.IProjectUsecase, pbu usecase.IProjectBudgetUsecase) IProjectController {
+	return &projectController{pu, pbu}
+}
+
+func (pc *projectController) GetAllProjects(c echo.Context) error {
+	projectsRes, err := pc.pu.GetAllProjects()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectsRes)
+}
+
+func (pc *projectController) GetProjectById(c echo.Context) error {
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	projectRes, err := pc.pu.GetProjectById(uint(projectId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectRes)
+}
+
+func (pc *projectController) CreateProject(c echo.Context) error {
+	project := model.Project{}
+	if err := c.Bind(&project); err != nil {
+		return err
+	}
+	projectRes, err := pc.pu.CreateProject(project)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, projectRes)
+}
+
+func (pc *projectController) UpdateProject(c echo.Context) error {
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	project := model.Project{}
+	if err := c.Bind(&project); err != nil {
+		return err
+	}
+	projectRes, err := pc.pu.UpdateProject(project, uint(projectId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectRes)
+}
+
+func (pc *projectController) DeleteProject(c echo.Context) error {
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	if err := pc.pu.DeleteProject(uint(projectId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (pc *projectController) ReplaceRates(c echo.Context) error {
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	rates := []model.ProjectRate{}
+	if err := c.Bind(&rates); err != nil {
+		return err
+	}
+	projectRes, err := pc.pu.ReplaceRates(uint(projectId), rates)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectRes)
+}
+
+func (pc *projectController) ReplaceMembers(c echo.Context) error {
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	members := []model.ProjectMember{}
+	if err := c.Bind(&members); err != nil {
+		return err
+	}
+	projectRes, err := pc.pu.ReplaceMembers(uint(projectId), members)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectRes)
+}
+
+func (pc *projectController) GetBurndown(c echo.Context) error {
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	// ?weeks=N で消化ペースの算出に使う週数を指定する
+	weeks := 0
+	if v := c.QueryParam("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return model.NewBadRequestError("invalid_weeks", "invalid weeks")
+		}
+		weeks = n
+	}
+	burndownRes, err := pc.pbu.GetBurndown(uint(projectId), weeks)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, burndownRes)
+}
+
+func (pc *projectController) GetBudgetAlerts(c echo.Context) error {
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	alertsRes, err := pc.pbu.GetBudgetAlerts(uint(projectId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, alertsRes)
+}
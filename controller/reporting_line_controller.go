@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IReportingLineController interface {
+	GetTeam(c echo.Context) error
+	GetReportingLines(c echo.Context) error
+	CreateReportingLine(c echo.Context) error
+	EndReportingLine(c echo.Context) error
+	DeleteReportingLine(c echo.Context) error
+}
+
+type reportingLineController struct {
+	rlu usecase.IReportingLineUsecase
+}
+
+func NewReportingLineController(rlu usecase.IReportingLineUsecase) IReportingLineController {
+	return &reportingLineController{rlu}
+}
+
+func (rlc *reportingLineController) GetTeam(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	floatUserId, ok := claims["user_id"].(float64)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+	userId := uint(floatUserId)
+
+	// date 未指定の場合は当日のチーム状況を返す
+	date := time.Now()
+	if dateParam := c.QueryParam("date"); dateParam != "" {
+		d, err := time.ParseInLocation("2006-01-02", dateParam, time.Local)
+		if err != nil {
+			return model.NewBadRequestError("invalid_date", "invalid date format")
+		}
+		date = d
+	}
+
+	members, err := rlc.rlu.GetTeam(userId, date)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+func (rlc *reportingLineController) GetReportingLines(c echo.Context) error {
+	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	if err != nil {
+		return model.NewBadRequestError("invalid_user_id", "invalid user_id")
+	}
+	lines, err := rlc.rlu.GetReportingLines(uint(userId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, lines)
+}
+
+func (rlc *reportingLineController) CreateReportingLine(c echo.Context) error {
+	line := model.ReportingLine{}
+	if err := c.Bind(&line); err != nil {
+		return err
+	}
+	lineRes, err := rlc.rlu.CreateReportingLine(line)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, lineRes)
+}
+
+func (rlc *reportingLineController) EndReportingLine(c echo.Context) error {
+	id := c.Param("lineId")
+	lineId, err := strconv.Atoi(id)
+	if err != nil {
+		return model.NewBadRequestError("invalid_line_id", "invalid lineId")
+	}
+
+	type EndReportingLineRequest struct {
+		EffectiveTo time.Time `json:"effective_to"`
+	}
+
+	var req EndReportingLineRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.EffectiveTo.IsZero() {
+		req.EffectiveTo = time.Now()
+	}
+
+	if err := rlc.rlu.EndReportingLine(uint(lineId), req.EffectiveTo); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (rlc *reportingLineController) DeleteReportingLine(c echo.Context) error {
+	id := c.Param("lineId")
+	lineId, err := strconv.Atoi(id)
+	if err != nil {
+		return model.NewBadRequestError("invalid_line_id", "invalid lineId")
+	}
+
+	if err := rlc.rlu.DeleteReportingLine(uint(lineId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IScimController interface {
+	ListUsers(c echo.Context) error
+	GetUser(c echo.Context) error
+	CreateUser(c echo.Context) error
+	ReplaceUser(c echo.Context) error
+	PatchUser(c echo.Context) error
+	DeleteUser(c echo.Context) error
+	ListGroups(c echo.Context) error
+	GetGroup(c echo.Context) error
+	CreateGroup(c echo.Context) error
+	ReplaceGroup(c echo.Context) error
+	PatchGroup(c echo.Context) error
+	DeleteGroup(c echo.Context) error
+}
+
+type scimController struct {
+	su usecase.IScimUsecase
+}
+
+func NewScimController(su usecase.IScimUsecase) IScimController {
+	return &scimController{su}
+}
+
+const mimeApplicationScimJSON = "application/scim+json"
+
+func scimJSON(c echo.Context, status int, body interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, mimeApplicationScimJSON)
+	c.Response().WriteHeader(status)
+	return json.NewEncoder(c.Response()).Encode(body)
+}
+
+func scimErrorJSON(c echo.Context, err error) error {
+	status := http.StatusInternalServerError
+	scimType := ""
+	var verrs validation.Errors
+	switch {
+	case errors.Is(err, usecase.ErrScimNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrScimConflict):
+		status = http.StatusConflict
+		scimType = "uniqueness"
+	case errors.Is(err, usecase.ErrScimInvalidFilter):
+		status = http.StatusBadRequest
+		scimType = "invalidFilter"
+	case errors.Is(err, usecase.ErrScimInvalidSyntax):
+		status = http.StatusBadRequest
+		scimType = "invalidSyntax"
+	case errors.Is(err, usecase.ErrScimInvalidPath):
+		status = http.StatusBadRequest
+		scimType = "invalidPath"
+	case errors.Is(err, usecase.ErrScimInvalidValue), errors.As(err, &verrs):
+		status = http.StatusBadRequest
+		scimType = "invalidValue"
+	}
+	// 想定外のエラーは内部の情報を含むことがあるため、内容はログにだけ残す
+	detail := err.Error()
+	if status == http.StatusInternalServerError {
+		c.Logger().Error(err)
+		detail = http.StatusText(status)
+	}
+	return scimJSON(c, status, model.ScimError{
+		Schemas:  []string{model.ScimErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// application/scim+json は echo の Bind が JSON として扱わないため直接デコードする
+func bindScim(c echo.Context, v interface{}) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: malformed JSON: %v", usecase.ErrScimInvalidSyntax, err)
+	}
+	return nil
+}
+
+func scimLocation(c echo.Context, resource string, id string) string {
+	return c.Scheme() + "://" + c.Request().Host + "/scim/v2/" + resource + "/" + url.PathEscape(id)
+}
+
+func scimUserId(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		return 0, usecase.ErrScimNotFound
+	}
+	return uint(id), nil
+}
+
+func scimGroupName(c echo.Context) string {
+	name, err := url.PathUnescape(c.Param("groupId"))
+	if err != nil {
+		return c.Param("groupId")
+	}
+	return name
+}
+
+func (sc *scimController) ListUsers(c echo.Context) error {
+	startIndex, _ := strconv.Atoi(c.QueryParam("startIndex"))
+	count, _ := strconv.Atoi(c.QueryParam("count"))
+	listRes, err := sc.su.ListUsers(c.QueryParam("filter"), startIndex, count)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	for i := range listRes.Resources.([]model.ScimUser) {
+		u := &listRes.Resources.([]model.ScimUser)[i]
+		u.Meta.Location = scimLocation(c, "Users", u.ID)
+	}
+	return scimJSON(c, http.StatusOK, listRes)
+}
+
+func (sc *scimController) GetUser(c echo.Context) error {
+	userId, err := scimUserId(c)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes, err := sc.su.GetUser(userId)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes.Meta.Location = scimLocation(c, "Users", userRes.ID)
+	return scimJSON(c, http.StatusOK, userRes)
+}
+
+func (sc *scimController) CreateUser(c echo.Context) error {
+	scimUser := model.ScimUser{}
+	if err := bindScim(c, &scimUser); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes, err := sc.su.CreateUser(scimUser)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes.Meta.Location = scimLocation(c, "Users", userRes.ID)
+	c.Response().Header().Set(echo.HeaderLocation, userRes.Meta.Location)
+	return scimJSON(c, http.StatusCreated, userRes)
+}
+
+func (sc *scimController) ReplaceUser(c echo.Context) error {
+	userId, err := scimUserId(c)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	scimUser := model.ScimUser{}
+	if err := bindScim(c, &scimUser); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes, err := sc.su.ReplaceUser(userId, scimUser)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes.Meta.Location = scimLocation(c, "Users", userRes.ID)
+	return scimJSON(c, http.StatusOK, userRes)
+}
+
+func (sc *scimController) PatchUser(c echo.Context) error {
+	userId, err := scimUserId(c)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	patch := model.ScimPatchRequest{}
+	if err := bindScim(c, &patch); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes, err := sc.su.PatchUser(userId, patch)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	userRes.Meta.Location = scimLocation(c, "Users", userRes.ID)
+	return scimJSON(c, http.StatusOK, userRes)
+}
+
+func (sc *scimController) DeleteUser(c echo.Context) error {
+	userId, err := scimUserId(c)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	if err := sc.su.DeleteUser(userId); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sc *scimController) ListGroups(c echo.Context) error {
+	startIndex, _ := strconv.Atoi(c.QueryParam("startIndex"))
+	count, _ := strconv.Atoi(c.QueryParam("count"))
+	listRes, err := sc.su.ListGroups(c.QueryParam("filter"), startIndex, count)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	for i := range listRes.Resources.([]model.ScimGroup) {
+		g := &listRes.Resources.([]model.ScimGroup)[i]
+		g.Meta.Location = scimLocation(c, "Groups", g.ID)
+	}
+	return scimJSON(c, http.StatusOK, listRes)
+}
+
+func (sc *scimController) GetGroup(c echo.Context) error {
+	groupRes, err := sc.su.GetGroup(scimGroupName(c))
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	groupRes.Meta.Location = scimLocation(c, "Groups", groupRes.ID)
+	return scimJSON(c, http.StatusOK, groupRes)
+}
+
+func (sc *scimController) CreateGroup(c echo.Context) error {
+	group := model.ScimGroup{}
+	if err := bindScim(c, &group); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	groupRes, err := sc.su.CreateGroup(group)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	groupRes.Meta.Location = scimLocation(c, "Groups", groupRes.ID)
+	c.Response().Header().Set(echo.HeaderLocation, groupRes.Meta.Location)
+	return scimJSON(c, http.StatusCreated, groupRes)
+}
+
+func (sc *scimController) ReplaceGroup(c echo.Context) error {
+	group := model.ScimGroup{}
+	if err := bindScim(c, &group); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	groupRes, err := sc.su.ReplaceGroup(scimGroupName(c), group)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	groupRes.Meta.Location = scimLocation(c, "Groups", groupRes.ID)
+	return scimJSON(c, http.StatusOK, groupRes)
+}
+
+func (sc *scimController) PatchGroup(c echo.Context) error {
+	patch := model.ScimPatchRequest{}
+	if err := bindScim(c, &patch); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	groupRes, err := sc.su.PatchGroup(scimGroupName(c), patch)
+	if err != nil {
+		return scimErrorJSON(c, err)
+	}
+	groupRes.Meta.Location = scimLocation(c, "Groups", groupRes.ID)
+	return scimJSON(c, http.StatusOK, groupRes)
+}
+
+func (sc *scimController) DeleteGroup(c echo.Context) error {
+	if err := sc.su.DeleteGroup(scimGroupName(c)); err != nil {
+		return scimErrorJSON(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
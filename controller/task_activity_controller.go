@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ITaskActivityController interface {
+	GetComments(c echo.Context) error
+	CreateComment(c echo.Context) error
+	UpdateComment(c echo.Context) error
+	DeleteComment(c echo.Context) error
+	GetActivity(c echo.Context) error
+}
+
+type taskActivityController struct {
+	tau usecase.ITaskActivityUsecase
+}
+
+func NewTaskActivityController(tau usecase.ITaskActivityUsecase) ITaskActivityController {
+	return &taskActivityController{tau}
+}
+
+func (tac *taskActivityController) GetComments(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	commentsRes, err := tac.tau.GetComments(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, commentsRes)
+}
+
+func (tac *taskActivityController) CreateComment(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	comment := model.TaskComment{}
+	if err := c.Bind(&comment); err != nil {
+		return err
+	}
+	comment.ID = 0
+	comment.TaskID = uint(taskId)
+	comment.AuthorID = uint(userId.(float64))
+	commentRes, err := tac.tau.CreateComment(comment)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, commentRes)
+}
+
+func (tac *taskActivityController) UpdateComment(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	taskId, _ := strconv.Atoi(c.Param("taskId"))
+	commentId, _ := strconv.Atoi(c.Param("commentId"))
+
+	comment := model.TaskComment{}
+	if err := c.Bind(&comment); err != nil {
+		return err
+	}
+	comment.ID = uint(commentId)
+	comment.TaskID = uint(taskId)
+	comment.AuthorID = uint(userId.(float64))
+	commentRes, err := tac.tau.UpdateComment(comment)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, commentRes)
+}
+
+func (tac *taskActivityController) DeleteComment(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	taskId, _ := strconv.Atoi(c.Param("taskId"))
+	commentId, _ := strconv.Atoi(c.Param("commentId"))
+
+	if err := tac.tau.DeleteComment(uint(userId.(float64)), uint(taskId), uint(commentId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (tac *taskActivityController) GetActivity(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	activityRes, err := tac.tau.GetActivity(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, activityRes)
+}
@@ -5,6 +5,7 @@ import (
 	"go-rest-api/usecase"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
@@ -16,6 +17,9 @@ type ITaskController interface {
 	CreateTask(c echo.Context) error
 	UpdateTask(c echo.Context) error
 	DeleteTask(c echo.Context) error
+	GetBoardTasks(c echo.Context) error
+	CreateBoardTask(c echo.Context) error
+	ClaimTask(c echo.Context) error
 }
 
 type taskController struct {
@@ -26,16 +30,77 @@ func NewTaskController(tu usecase.ITaskUsecase) ITaskController {
 	return &taskController{tu}
 }
 
+// parseTaskFilter はクエリパラメータからタスクの絞り込み条件を組み立てる
+func parseTaskFilter(c echo.Context) (model.TaskFilter, error) {
+	filter := model.TaskFilter{
+		Status:   c.QueryParam("status"),
+		Priority: c.QueryParam("priority"),
+		Sort:     c.QueryParam("sort"),
+	}
+	if v := c.QueryParam("assignee_id"); v != "" {
+		assigneeId, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, model.NewBadRequestError("invalid_assignee_id", "invalid assignee_id")
+		}
+		id := uint(assigneeId)
+		filter.AssigneeID = &id
+	}
+	if v := c.QueryParam("project_id"); v != "" {
+		projectId, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, model.NewBadRequestError("invalid_project_id", "invalid project_id")
+		}
+		id := uint(projectId)
+		filter.ProjectID = &id
+	}
+	// due_to はその日を含む（翌日0時未満）
+	if v := c.QueryParam("due_from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, model.NewBadRequestError("invalid_date", "invalid due_from format")
+		}
+		filter.DueFrom = &d
+	}
+	if v := c.QueryParam("due_to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, model.NewBadRequestError("invalid_date", "invalid due_to format")
+		}
+		d = d.AddDate(0, 0, 1)
+		filter.DueTo = &d
+	}
+	// from, to は作成日の範囲（to を含む）
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.From = from
+	filter.To = to
+	return filter, nil
+}
+
 func (tc *taskController) GetAllTasks(c echo.Context) error {
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
 	userId := claims["user_id"]
 
-	tasksRes, err := tc.tu.GetAllTasks(uint(userId.(float64)))
+	filter, err := parseTaskFilter(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	return c.JSON(http.StatusOK, tasksRes)
+	page, err := parsePageQuery(c)
+	if err != nil {
+		return err
+	}
+	tasksRes, pageInfo, err := tc.tu.GetAllTasks(uint(userId.(float64)), filter, page)
+	if err != nil {
+		return err
+	}
+	res, err := pageResponse(c, tasksRes, pageInfo)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, res)
 }
 
 func (tc *taskController) GetTaskById(c echo.Context) error {
@@ -46,7 +111,7 @@ func (tc *taskController) GetTaskById(c echo.Context) error {
 	taskId, _ := strconv.Atoi(id)
 	taskRes, err := tc.tu.GetTaskById(uint(userId.(float64)), uint(taskId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, taskRes)
 }
@@ -58,12 +123,12 @@ func (tc *taskController) CreateTask(c echo.Context) error {
 
 	task := model.Task{}
 	if err := c.Bind(&task); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	task.UserId = uint(userId.(float64))
 	taskRes, err := tc.tu.CreateTask(task)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusCreated, taskRes)
 }
@@ -77,11 +142,11 @@ func (tc *taskController) UpdateTask(c echo.Context) error {
 
 	task := model.Task{}
 	if err := c.Bind(&task); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	taskRes, err := tc.tu.UpdateTask(task, uint(userId.(float64)), uint(taskId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, taskRes)
 }
@@ -95,7 +160,54 @@ func (tc *taskController) DeleteTask(c echo.Context) error {
 
 	err := tc.tu.DeleteTask(uint(userId.(float64)), uint(taskId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (tc *taskController) GetBoardTasks(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		return err
+	}
+	tasksRes, err := tc.tu.GetBoardTasks(uint(userId.(float64)), c.QueryParam("department"), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tasksRes)
+}
+
+func (tc *taskController) CreateBoardTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	task := model.Task{}
+	if err := c.Bind(&task); err != nil {
+		return err
+	}
+	task.UserId = uint(userId.(float64))
+	taskRes, err := tc.tu.CreateBoardTask(task)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, taskRes)
+}
+
+func (tc *taskController) ClaimTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	taskRes, err := tc.tu.ClaimTask(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, taskRes)
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ITaskRecurrenceController interface {
+	GetRecurrences(c echo.Context) error
+	GetRecurrenceById(c echo.Context) error
+	CreateRecurrence(c echo.Context) error
+	UpdateRecurrence(c echo.Context) error
+	DeleteRecurrence(c echo.Context) error
+}
+
+type taskRecurrenceController struct {
+	tru usecase.ITaskRecurrenceUsecase
+}
+
+func NewTaskRecurrenceController(tru usecase.ITaskRecurrenceUsecase) ITaskRecurrenceController {
+	return &taskRecurrenceController{tru}
+}
+
+func (trc *taskRecurrenceController) GetRecurrences(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	recurrencesRes, err := trc.tru.GetRecurrences(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, recurrencesRes)
+}
+
+func (trc *taskRecurrenceController) GetRecurrenceById(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("recurrenceId")
+	recurrenceId, _ := strconv.Atoi(id)
+
+	recurrenceRes, err := trc.tru.GetRecurrenceById(uint(userId.(float64)), uint(recurrenceId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, recurrenceRes)
+}
+
+func (trc *taskRecurrenceController) CreateRecurrence(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	recurrence := model.TaskRecurrence{}
+	if err := c.Bind(&recurrence); err != nil {
+		return err
+	}
+	recurrence.UserID = uint(userId.(float64))
+	recurrenceRes, err := trc.tru.CreateRecurrence(recurrence)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, recurrenceRes)
+}
+
+func (trc *taskRecurrenceController) UpdateRecurrence(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("recurrenceId")
+	recurrenceId, _ := strconv.Atoi(id)
+
+	recurrence := model.TaskRecurrence{}
+	if err := c.Bind(&recurrence); err != nil {
+		return err
+	}
+	recurrenceRes, err := trc.tru.UpdateRecurrence(recurrence, uint(userId.(float64)), uint(recurrenceId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, recurrenceRes)
+}
+
+func (trc *taskRecurrenceController) DeleteRecurrence(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("recurrenceId")
+	recurrenceId, _ := strconv.Atoi(id)
+
+	if err := trc.tru.DeleteRecurrence(uint(userId.(float64)), uint(recurrenceId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
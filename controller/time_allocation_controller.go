@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ITimeAllocationController interface {
+	GetDayAllocations(c echo.Context) error
+	ReplaceDayAllocations(c echo.Context) error
+}
+
+type timeAllocationController struct {
+	tau usecase.ITimeAllocationUsecase
+}
+
+func NewTimeAllocationController(tau usecase.ITimeAllocationUsecase) ITimeAllocationController {
+	return &timeAllocationController{tau}
+}
+
+func (tac *timeAllocationController) GetDayAllocations(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	floatUserId, ok := claims["user_id"].(float64)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+	userId := uint(floatUserId)
+	id := c.Param("recordId")
+	recordId, _ := strconv.Atoi(id)
+
+	allocationsRes, err := tac.tau.GetDayAllocations(userId, uint(recordId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, allocationsRes)
+}
+
+func (tac *timeAllocationController) ReplaceDayAllocations(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	floatUserId, ok := claims["user_id"].(float64)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+	userId := uint(floatUserId)
+	id := c.Param("recordId")
+	recordId, _ := strconv.Atoi(id)
+
+	allocations := []model.TimeAllocation{}
+	if err := c.Bind(&allocations); err != nil {
+		return err
+	}
+	allocationsRes, err := tac.tau.ReplaceDayAllocations(userId, uint(recordId), allocations)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, allocationsRes)
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ITimeEntryController interface {
+	GetRunningTimer(c echo.Context) error
+	StartTimer(c echo.Context) error
+	StopTimer(c echo.Context) error
+	GetEntries(c echo.Context) error
+	CreateEntry(c echo.Context) error
+	DeleteEntry(c echo.Context) error
+	SetBillable(c echo.Context) error
+}
+
+type timeEntryController struct {
+	teu usecase.ITimeEntryUsecase
+}
+
+func NewTimeEntryController(teu usecase.ITimeEntryUsecase) ITimeEntryController {
+	return &timeEntryController{teu}
+}
+
+func (tec *timeEntryController) GetRunningTimer(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	entryRes, err := tec.teu.GetRunningTimer(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, entryRes)
+}
+
+func (tec *timeEntryController) StartTimer(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	entryRes, err := tec.teu.StartTimer(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, entryRes)
+}
+
+func (tec *timeEntryController) StopTimer(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	entryRes, err := tec.teu.StopTimer(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, entryRes)
+}
+
+func (tec *timeEntryController) GetEntries(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// ?date=YYYY-MM-DD でその日、?week=YYYY-MM-DD でその日を含む週（月曜始まり）
+	var from, to time.Time
+	switch {
+	case c.QueryParam("week") != "":
+		d, err := time.ParseInLocation("2006-01-02", c.QueryParam("week"), time.Local)
+		if err != nil {
+			return model.NewBadRequestError("invalid_date", "invalid week format")
+		}
+		offset := (int(d.Weekday()) + 6) % 7
+		from = d.AddDate(0, 0, -offset)
+		to = from.AddDate(0, 0, 7)
+	default:
+		d := time.Now()
+		if c.QueryParam("date") != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", c.QueryParam("date"), time.Local)
+			if err != nil {
+				return model.NewBadRequestError("invalid_date", "invalid date format")
+			}
+			d = parsed
+		}
+		from = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.Local)
+		to = from.AddDate(0, 0, 1)
+	}
+
+	entriesRes, err := tec.teu.GetEntries(uint(userId.(float64)), from, to)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, entriesRes)
+}
+
+func (tec *timeEntryController) CreateEntry(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	entry := model.TimeEntry{}
+	if err := c.Bind(&entry); err != nil {
+		return err
+	}
+	entry.UserID = uint(userId.(float64))
+	entryRes, err := tec.teu.CreateManualEntry(entry)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, entryRes)
+}
+
+func (tec *timeEntryController) DeleteEntry(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("entryId")
+	entryId, _ := strconv.Atoi(id)
+
+	if err := tec.teu.DeleteEntry(uint(userId.(float64)), uint(entryId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (tec *timeEntryController) SetBillable(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("entryId")
+	entryId, _ := strconv.Atoi(id)
+
+	req := struct {
+		Billable bool `json:"billable"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	entryRes, err := tec.teu.SetBillable(uint(userId.(float64)), uint(entryId), req.Billable)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, entryRes)
+}
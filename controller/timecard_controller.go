@@ -15,6 +15,8 @@ import (
 type IAttendanceRecordController interface {
 	ClockIn(c echo.Context) error
 	ClockOut(c echo.Context) error
+	BreakStart(c echo.Context) error
+	BreakEnd(c echo.Context) error
 	GetAllRecords(c echo.Context) error
 	GetRecordById(c echo.Context) error
 	GetRecordByDate(c echo.Context) error
@@ -25,6 +27,7 @@ type IAttendanceRecordController interface {
 	CreateRecord(c echo.Context) error
 	UpdateRecord(c echo.Context) error
 	DeleteRecord(c echo.Context) error
+	GetDepartmentDashboard(c echo.Context) error
 }
 
 type attendanceRecordController struct {
@@ -40,7 +43,7 @@ func (arc *attendanceRecordController) ClockIn(c echo.Context) error {
 	claims := user.Claims.(jwt.MapClaims)
 	floatUserId, ok := claims["user_id"].(float64)
 	if !ok {
-		return c.JSON(http.StatusInternalServerError, "User ID is not a float64")
+		return echo.ErrUnauthorized
 	}
 	userId := uint(floatUserId)
 
@@ -50,7 +53,7 @@ func (arc *attendanceRecordController) ClockIn(c echo.Context) error {
 
 	var req ClockInRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	record := model.AttendanceRecord{
@@ -60,7 +63,7 @@ func (arc *attendanceRecordController) ClockIn(c echo.Context) error {
 
 	recordRes, err := arc.aru.CreateRecord(record)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusCreated, recordRes)
 }
@@ -69,7 +72,7 @@ func (arc *attendanceRecordController) ClockOut(c echo.Context) error {
 	claims := user.Claims.(jwt.MapClaims)
 	floatUserId, ok := claims["user_id"].(float64)
 	if !ok {
-		return c.JSON(http.StatusInternalServerError, "User ID is not a float64")
+		return echo.ErrUnauthorized
 	}
 	userId := uint(floatUserId)
 
@@ -79,7 +82,7 @@ func (arc *attendanceRecordController) ClockOut(c echo.Context) error {
 
 	var req ClockOutRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	var recordRes model.AttendanceRecordResponse
@@ -90,11 +93,52 @@ func (arc *attendanceRecordController) ClockOut(c echo.Context) error {
 
 	recordRes, err = arc.aru.UpdateRecord(model.AttendanceRecord(record), userId, uint(record.ID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, recordRes)
 
 }
+
+// BreakStart は {"time": ...} の時刻で休憩を始める
+func (arc *attendanceRecordController) BreakStart(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	type BreakRequest struct {
+		Time time.Time `json:"time"`
+	}
+	var req BreakRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	recordRes, err := arc.aru.StartBreak(uint(userId.(float64)), req.Time)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, recordRes)
+}
+
+// BreakEnd は {"time": ...} の時刻で進行中の休憩を終える
+func (arc *attendanceRecordController) BreakEnd(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	type BreakRequest struct {
+		Time time.Time `json:"time"`
+	}
+	var req BreakRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	recordRes, err := arc.aru.EndBreak(uint(userId.(float64)), req.Time)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, recordRes)
+}
+
 func ConvertResponseToRecord(response model.AttendanceRecordResponse) model.AttendanceRecord {
 	return model.AttendanceRecord{
 		ID:           response.ID,
@@ -114,42 +158,71 @@ func (arc *attendanceRecordController) GetAllRecords(c echo.Context) error {
 	claims := user.Claims.(jwt.MapClaims)
 	floatUserId, ok := claims["user_id"].(float64)
 	if !ok {
-		return c.JSON(http.StatusInternalServerError, "User ID is not a float64")
+		return echo.ErrUnauthorized
 	}
 	userId := uint(floatUserId)
 
-	recordsRes, err := arc.aru.GetAllRecords(userId)
+	// from, to は出勤日の範囲（to を含む）
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return err
+	}
+	page, err := parsePageQuery(c)
+	if err != nil {
+		return err
+	}
+	filter := model.AttendanceRecordFilter{From: from, To: to, Sort: c.QueryParam("sort")}
+	recordsRes, pageInfo, err := arc.aru.GetAllRecords(userId, filter, page)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	return c.JSON(http.StatusOK, recordsRes)
+	res, err := pageResponse(c, recordsRes, pageInfo)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, res)
 }
 
 func (arc *attendanceRecordController) GetRecordsByDepartment(c echo.Context) error {
-	// URL パラメータから部署を取得
-	department := c.QueryParam("department")
-
-	records, err := arc.aru.GetRecordsByDepartment(department)
+	from, to, err := parseDateRange(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
-
-	return c.JSON(http.StatusOK, records)
+	page, err := parsePageQuery(c)
+	if err != nil {
+		return err
+	}
+	filter := model.AttendanceRecordFilter{
+		Department:     c.QueryParam("department"),
+		EmploymentType: c.QueryParam("employment_type"),
+		From:           from,
+		To:             to,
+		Sort:           c.QueryParam("sort"),
+	}
+	records, pageInfo, err := arc.aru.GetRecordsByDepartment(filter, page)
+	if err != nil {
+		return err
+	}
+	res, err := pageResponse(c, records, pageInfo)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, res)
 }
 func (arc *attendanceRecordController) GetRecordsByDateDepartment(c echo.Context) error {
 	// URL パラメータから日付を取得
 	dateParam := c.QueryParam("date")
 	date, err := time.Parse("2006-01-02", dateParam)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, "invalid date format")
+		return model.NewBadRequestError("invalid_date", "invalid date format")
 	}
 
 	// URL パラメータから部署を取得
 	department := c.QueryParam("department")
 
-	records, err := arc.aru.GetRecordsByDateDepartment(date, department)
+	records, err := arc.aru.GetRecordsByDateDepartment(date, department, c.QueryParam("employment_type"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, records)
@@ -161,7 +234,7 @@ func (arc *attendanceRecordController) GetRecordById(c echo.Context) error {
 	claims := user.Claims.(jwt.MapClaims)
 	floatUserId, ok := claims["user_id"].(float64)
 	if !ok {
-		return c.JSON(http.StatusInternalServerError, "User ID is not a float64")
+		return echo.ErrUnauthorized
 	}
 	userId := uint(floatUserId)
 
@@ -170,7 +243,7 @@ func (arc *attendanceRecordController) GetRecordById(c echo.Context) error {
 
 	recordRes, err := arc.aru.GetRecordById(userId, uint(recordId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, recordRes)
 }
@@ -180,7 +253,7 @@ func (arc *attendanceRecordController) GetRecordByDate(c echo.Context) error {
 	claims := user.Claims.(jwt.MapClaims)
 	floatUserId, ok := claims["user_id"].(float64)
 	if !ok {
-		return c.JSON(http.StatusInternalServerError, "User ID is not a float64")
+		return echo.ErrUnauthorized
 	}
 	userId := uint(floatUserId)
 
@@ -188,12 +261,12 @@ func (arc *attendanceRecordController) GetRecordByDate(c echo.Context) error {
 
 	dateTime, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return model.NewBadRequestError("invalid_date", "invalid date format")
 	}
 	fmt.Println(dateTime)
 	recordRes, err := arc.aru.GetRecordByDate(userId, dateTime)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, recordRes)
 }
@@ -203,42 +276,55 @@ func (arc *attendanceRecordController) GetRecordsByDate(c echo.Context) error {
 	date, err := time.Parse("2006-01-02", dateParam)
 	fmt.Println(date)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, "invalid date format")
+		return model.NewBadRequestError("invalid_date", "invalid date format")
 	}
 
-	records, err := arc.aru.GetRecordsByDate(date)
+	records, err := arc.aru.GetRecordsByDate(date, c.QueryParam("employment_type"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, records)
 }
 func (arc *attendanceRecordController) GetAllUsers(c echo.Context) error {
-	users, err := arc.aru.GetAllUsers()
+	// from, to は登録日の範囲（to を含む）
+	from, to, err := parseDateRange(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
-
-	return c.JSON(http.StatusOK, users)
+	page, err := parsePageQuery(c)
+	if err != nil {
+		return err
+	}
+	filter := model.UserFilter{EmploymentType: c.QueryParam("employment_type"), From: from, To: to, Sort: c.QueryParam("sort")}
+	users, pageInfo, err := arc.aru.GetAllUsers(filter, page)
+	if err != nil {
+		return err
+	}
+	res, err := pageResponse(c, users, pageInfo)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, res)
 }
 func (arc *attendanceRecordController) CreateRecord(c echo.Context) error {
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
 	floatUserId, ok := claims["user_id"].(float64)
 	if !ok {
-		return c.JSON(http.StatusInternalServerError, "User ID is not a float64")
+		return echo.ErrUnauthorized
 	}
 	userId := uint(floatUserId)
 
 	record := model.AttendanceRecord{}
 	if err := c.Bind(&record); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	record.UserID = userId
 
 	recordRes, err := arc.aru.CreateRecord(record)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusCreated, recordRes)
 }
@@ -246,18 +332,20 @@ func (arc *attendanceRecordController) CreateRecord(c echo.Context) error {
 func (arc *attendanceRecordController) UpdateRecord(c echo.Context) error {
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
-	userId := claims["user_id"].(uint)
-	id := c.Param("recordId")
-	recordId, _ := strconv.Atoi(id)
+	userId := uint(claims["user_id"].(float64))
+	recordId, err := strconv.Atoi(c.Param("recordId"))
+	if err != nil {
+		return model.NewBadRequestError("invalid_record_id", "invalid record id")
+	}
 
 	record := model.AttendanceRecord{}
 	if err := c.Bind(&record); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	recordRes, err := arc.aru.UpdateRecord(record, userId, uint(recordId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, recordRes)
 }
@@ -265,13 +353,51 @@ func (arc *attendanceRecordController) UpdateRecord(c echo.Context) error {
 func (arc *attendanceRecordController) DeleteRecord(c echo.Context) error {
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
-	userId := claims["user_id"].(uint)
-	id := c.Param("recordId")
-	recordId, _ := strconv.Atoi(id)
-
-	err := arc.aru.DeleteRecord(userId, uint(recordId))
+	userId := uint(claims["user_id"].(float64))
+	recordId, err := strconv.Atoi(c.Param("recordId"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return model.NewBadRequestError("invalid_record_id", "invalid record id")
+	}
+
+	if err := arc.aru.DeleteRecord(userId, uint(recordId)); err != nil {
+		return err
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (arc *attendanceRecordController) GetDepartmentDashboard(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	department := c.QueryParam("department")
+	if department == "" {
+		return model.NewBadRequestError("department_required", "department is required")
+	}
+	// from, to は YYYY-MM-DD（to を含む）。省略時は今月1日から今日まで
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if v := c.QueryParam("from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return model.NewBadRequestError("invalid_date", "invalid from format")
+		}
+		from = d
+	}
+	if v := c.QueryParam("to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return model.NewBadRequestError("invalid_date", "invalid to format")
+		}
+		to = d.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		return model.NewBadRequestError("invalid_range", "to must not be before from")
+	}
+
+	dashboard, err := arc.aru.GetDepartmentDashboard(uint(userId.(float64)), department, from, to)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, dashboard)
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ITimesheetController interface {
+	GetMyTimesheets(c echo.Context) error
+	GetTimesheetById(c echo.Context) error
+	GetTimesheetsForReview(c echo.Context) error
+	SubmitTimesheet(c echo.Context) error
+	ApproveTimesheet(c echo.Context) error
+	RejectTimesheet(c echo.Context) error
+}
+
+type timesheetController struct {
+	tsu usecase.ITimesheetUsecase
+}
+
+func NewTimesheetController(tsu usecase.ITimesheetUsecase) ITimesheetController {
+	return &timesheetController{tsu}
+}
+
+type timesheetReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+func (tsc *timesheetController) GetMyTimesheets(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	timesheetsRes, err := tsc.tsu.GetMyTimesheets(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, timesheetsRes)
+}
+
+func (tsc *timesheetController) GetTimesheetById(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("timesheetId")
+	timesheetId, _ := strconv.Atoi(id)
+
+	timesheetRes, err := tsc.tsu.GetTimesheetById(uint(userId.(float64)), uint(timesheetId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, timesheetRes)
+}
+
+func (tsc *timesheetController) GetTimesheetsForReview(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	timesheetsRes, err := tsc.tsu.GetTimesheetsForReview(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, timesheetsRes)
+}
+
+func (tsc *timesheetController) SubmitTimesheet(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// week は対象週に含まれる任意の日付（YYYY-MM-DD）
+	req := struct {
+		Week string `json:"week"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	week, err := time.ParseInLocation("2006-01-02", req.Week, time.Local)
+	if err != nil {
+		return model.NewBadRequestError("invalid_date", "invalid week format")
+	}
+	timesheetRes, err := tsc.tsu.SubmitTimesheet(uint(userId.(float64)), week)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, timesheetRes)
+}
+
+func (tsc *timesheetController) ApproveTimesheet(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("timesheetId")
+	timesheetId, _ := strconv.Atoi(id)
+
+	req := timesheetReviewRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	timesheetRes, err := tsc.tsu.ApproveTimesheet(uint(userId.(float64)), uint(timesheetId), req.Comment)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, timesheetRes)
+}
+
+func (tsc *timesheetController) RejectTimesheet(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("timesheetId")
+	timesheetId, _ := strconv.Atoi(id)
+
+	req := timesheetReviewRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	timesheetRes, err := tsc.tsu.RejectTimesheet(uint(userId.(float64)), uint(timesheetId), req.Comment)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, timesheetRes)
+}
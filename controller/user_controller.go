@@ -5,6 +5,7 @@ import (
 	"go-rest-api/usecase"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -17,6 +18,12 @@ type IUserController interface {
 	CsrfToken(c echo.Context) error
 	UpdateUser(c echo.Context) error
 	DeleteUser(c echo.Context) error
+	UpdateEmployment(c echo.Context) error
+	SetEmploymentType(c echo.Context) error
+	SetRole(c echo.Context) error
+	DeactivateUser(c echo.Context) error
+	ReactivateUser(c echo.Context) error
+	PurgeUsers(c echo.Context) error
 }
 
 type userController struct {
@@ -30,11 +37,11 @@ func NewUserController(uu usecase.IUserUsecase) IUserController {
 func (uc *userController) SignUp(c echo.Context) error {
 	user := model.User{}
 	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	userRes, err := uc.uu.SignUp(user)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusCreated, userRes)
 }
@@ -42,11 +49,11 @@ func (uc *userController) SignUp(c echo.Context) error {
 func (uc *userController) LogIn(c echo.Context) error {
 	user := model.User{}
 	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	tokenString, err := uc.uu.Login(user)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	cookie := new(http.Cookie)
 	cookie.Name = "token"
@@ -85,11 +92,11 @@ func (uc *userController) CsrfToken(c echo.Context) error {
 func (uc *userController) UpdateUser(c echo.Context) error {
 	user := model.User{}
 	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	userRes, err := uc.uu.UpdateUser(user)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, userRes)
 }
@@ -97,11 +104,99 @@ func (uc *userController) UpdateUser(c echo.Context) error {
 func (uc *userController) DeleteUser(c echo.Context) error {
 	user := model.User{}
 	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	err := uc.uu.DeleteUser(user)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (uc *userController) UpdateEmployment(c echo.Context) error {
+	id := c.Param("userId")
+	userId, _ := strconv.Atoi(id)
+
+	type EmploymentRequest struct {
+		HireDate        *time.Time `json:"hire_date"`
+		TerminationDate *time.Time `json:"termination_date"`
+	}
+
+	var req EmploymentRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	userRes, err := uc.uu.UpdateEmployment(uint(userId), req.HireDate, req.TerminationDate)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, userRes)
+}
+
+func (uc *userController) SetEmploymentType(c echo.Context) error {
+	id := c.Param("userId")
+	userId, _ := strconv.Atoi(id)
+
+	type EmploymentTypeRequest struct {
+		EmploymentTypeID *uint `json:"employment_type_id"`
+	}
+
+	var req EmploymentTypeRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	userRes, err := uc.uu.SetEmploymentType(uint(userId), req.EmploymentTypeID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, userRes)
+}
+
+func (uc *userController) SetRole(c echo.Context) error {
+	id := c.Param("userId")
+	userId, _ := strconv.Atoi(id)
+
+	type RoleRequest struct {
+		Role string `json:"role"`
+	}
+
+	var req RoleRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	userRes, err := uc.uu.SetRole(uint(userId), req.Role)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, userRes)
+}
+
+func (uc *userController) DeactivateUser(c echo.Context) error {
+	id := c.Param("userId")
+	userId, _ := strconv.Atoi(id)
+
+	if err := uc.uu.DeactivateUser(uint(userId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (uc *userController) ReactivateUser(c echo.Context) error {
+	id := c.Param("userId")
+	userId, _ := strconv.Atoi(id)
+
+	if err := uc.uu.ReactivateUser(uint(userId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (uc *userController) PurgeUsers(c echo.Context) error {
+	purged, err := uc.uu.PurgeExpiredUsers(time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"purged": purged,
+	})
+}
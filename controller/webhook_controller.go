@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IWebhookController interface {
+	GetSubscriptions(c echo.Context) error
+	CreateSubscription(c echo.Context) error
+	UpdateSubscription(c echo.Context) error
+	DeleteSubscription(c echo.Context) error
+	GetDeliveries(c echo.Context) error
+	Redeliver(c echo.Context) error
+}
+
+type webhookController struct {
+	wu usecase.IWebhookUsecase
+}
+
+func NewWebhookController(wu usecase.IWebhookUsecase) IWebhookController {
+	return &webhookController{wu}
+}
+
+type webhookSubscriptionRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+}
+
+func (r webhookSubscriptionRequest) toSubscription() model.WebhookSubscription {
+	subscription := model.WebhookSubscription{
+		URL:         r.URL,
+		Description: r.Description,
+		Events:      strings.Join(r.Events, ","),
+		Active:      true,
+	}
+	if r.Active != nil {
+		subscription.Active = *r.Active
+	}
+	return subscription
+}
+
+func (wc *webhookController) GetSubscriptions(c echo.Context) error {
+	subscriptionsRes, err := wc.wu.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, subscriptionsRes)
+}
+
+func (wc *webhookController) CreateSubscription(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := uint(claims["user_id"].(float64))
+
+	req := webhookSubscriptionRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	subscription := req.toSubscription()
+	subscription.CreatedBy = &userId
+	subscriptionRes, err := wc.wu.CreateSubscription(subscription)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, subscriptionRes)
+}
+
+func (wc *webhookController) UpdateSubscription(c echo.Context) error {
+	id := c.Param("subscriptionId")
+	subscriptionId, _ := strconv.Atoi(id)
+
+	req := webhookSubscriptionRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	subscriptionRes, err := wc.wu.UpdateSubscription(req.toSubscription(), req.Active, uint(subscriptionId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, subscriptionRes)
+}
+
+func (wc *webhookController) DeleteSubscription(c echo.Context) error {
+	id := c.Param("subscriptionId")
+	subscriptionId, _ := strconv.Atoi(id)
+
+	if err := wc.wu.DeleteSubscription(uint(subscriptionId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *webhookController) GetDeliveries(c echo.Context) error {
+	id := c.Param("subscriptionId")
+	subscriptionId, _ := strconv.Atoi(id)
+
+	deliveriesRes, err := wc.wu.GetDeliveries(uint(subscriptionId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, deliveriesRes)
+}
+
+func (wc *webhookController) Redeliver(c echo.Context) error {
+	id := c.Param("deliveryId")
+	deliveryId, _ := strconv.Atoi(id)
+
+	deliveryRes, err := wc.wu.Redeliver(uint(deliveryId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, deliveryRes)
+}
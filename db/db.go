@@ -1,7 +1,9 @@
 package db
 
 import (
+	"errors"
 	"fmt"
+	"go-rest-api/model"
 	"log"
 	"os"
 
@@ -20,10 +22,13 @@ func NewDB() *gorm.DB {
 	url := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", os.Getenv("POSTGRES_USER"),
 		os.Getenv("POSTGRES_PW"), os.Getenv("POSTGRES_HOST"),
 		os.Getenv("POSTGRES_PORT"), os.Getenv("POSTGRES_DB"))
-	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalln(err)
 	}
+	if err := registerErrorTranslation(db); err != nil {
+		log.Fatalln(err)
+	}
 	fmt.Println("Connceted")
 	return db
 }
@@ -34,3 +39,39 @@ func CloseDB(db *gorm.DB) {
 		log.Fatalln(err)
 	}
 }
+
+// registerErrorTranslation は GORM と Postgres のエラーをドメインエラーに置き換える。
+// 元のエラーは保持するため errors.Is(err, gorm.ErrRecordNotFound) での判定も引き続き使える
+func registerErrorTranslation(db *gorm.DB) error {
+	translate := func(tx *gorm.DB) {
+		var domainErr *model.DomainError
+		if tx.Error == nil || errors.As(tx.Error, &domainErr) {
+			return
+		}
+		switch {
+		case errors.Is(tx.Error, gorm.ErrRecordNotFound):
+			tx.Error = model.ErrNotFound.Wrap(tx.Error)
+		case errors.Is(tx.Error, gorm.ErrDuplicatedKey):
+			tx.Error = model.ErrDuplicated.Wrap(tx.Error)
+		case errors.Is(tx.Error, gorm.ErrForeignKeyViolated):
+			tx.Error = model.ErrReferenced.Wrap(tx.Error)
+		}
+	}
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("app:translate_error", translate); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("gorm:query").Register("app:translate_error", translate); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("app:translate_error", translate); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("app:translate_error", translate); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("gorm:row").Register("app:translate_error", translate); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("gorm:raw").Register("app:translate_error", translate)
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+type IMailer interface {
+	Send(to string, subject string, body string) error
+}
+
+type smtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewMailer は SMTP_* の環境変数から送信設定を読み込む。
+// SMTP_HOST が未設定の場合は送信せずログ出力のみ行う（開発環境向け）
+func NewMailer() IMailer {
+	return &smtpMailer{
+		host:     os.Getenv("SMTP_HOST"),
+		port:     os.Getenv("SMTP_PORT"),
+		username: os.Getenv("SMTP_USER"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     os.Getenv("MAIL_FROM"),
+	}
+}
+
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	if m.host == "" {
+		log.Printf("mail to=%s subject=%s\n%s", to, subject, body)
+		return nil
+	}
+	port := m.port
+	if port == "" {
+		port = "587"
+	}
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	msg := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + mime.BEncoding.Encode("UTF-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	if err := smtp.SendMail(m.host+":"+port, auth, m.from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
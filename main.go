@@ -1,36 +1,190 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"go-rest-api/controller"
 	"go-rest-api/db"
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/presence"
 	"go-rest-api/repository"
 	"go-rest-api/router"
 	"go-rest-api/usecase"
 	"go-rest-api/validator"
+	"go-rest-api/webhook"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
 	db := db.NewDB()
+	mailer := mailer.NewMailer()
+	presenceHub := presence.NewHub()
+	webhookClient := webhook.NewWebhookClient()
 	userValidator := validator.NewUserValidator()
 	authUserValidator := validator.NewAuthUserValidator() // AuthUser用のバリデーターを追加
 	taskValidator := validator.NewTaskValidator()
 	attendanceRecordValidator := validator.NewAttendanceRecordValidator()
+	reportingLineValidator := validator.NewReportingLineValidator()
+	employmentTypeValidator := validator.NewEmploymentTypeValidator()
+	invitationValidator := validator.NewInvitationValidator()
+	projectValidator := validator.NewProjectValidator()
+	timeEntryValidator := validator.NewTimeEntryValidator()
+	notificationValidator := validator.NewNotificationValidator()
+	webhookValidator := validator.NewWebhookValidator()
+	leaveValidator := validator.NewLeaveValidator()
+	holidayWorkRequestValidator := validator.NewHolidayWorkRequestValidator()
 
 	userRepository := repository.NewUserRepository(db)
 	authUserRepository := repository.NewAuthUserRepository(db) // AuthUser用のリポジトリを追加
 	taskRepository := repository.NewTaskRepository(db)
+	taskActivityRepository := repository.NewTaskActivityRepository(db)
+	taskRecurrenceRepository := repository.NewTaskRecurrenceRepository(db)
 	attendanceRecordRepository := repository.NewAttendanceRecordRepository(db)
+	reportingLineRepository := repository.NewReportingLineRepository(db)
+	employmentTypeRepository := repository.NewEmploymentTypeRepository(db)
+	invitationRepository := repository.NewInvitationRepository(db)
+	projectRepository := repository.NewProjectRepository(db)
+	timeAllocationRepository := repository.NewTimeAllocationRepository(db)
+	timeEntryRepository := repository.NewTimeEntryRepository(db)
+	timesheetRepository := repository.NewTimesheetRepository(db)
+	attendanceExceptionRepository := repository.NewAttendanceExceptionRepository(db)
+	notificationRepository := repository.NewNotificationRepository(db)
+	reminderRepository := repository.NewReminderRepository(db)
+	webhookRepository := repository.NewWebhookRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	jobRepository := repository.NewJobRepository(db)
+	leaveRepository := repository.NewLeaveRepository(db)
+	holidayWorkRequestRepository := repository.NewHolidayWorkRequestRepository(db)
 
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepository, userRepository, notificationValidator, mailer, webhookClient)
+	reminderUsecase := usecase.NewReminderUsecase(reminderRepository, notificationUsecase)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepository, webhookValidator, webhookClient)
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepository)
+	for _, v := range model.WebhookEvents {
+		outboxUsecase.Subscribe(v, webhookUsecase.HandleEvent)
+	}
 	userUsecase := usecase.NewUserUsecase(userRepository, userValidator)
 	authUserUsecase := usecase.NewAuthUserUsecase(authUserRepository, authUserValidator) // AuthUser用のユースケースを追加
-	taskUsecase := usecase.NewTaskUsecase(taskRepository, taskValidator)
-	attendanceRecordUsecase := usecase.NewAttendanceRecordUsecase(attendanceRecordRepository, attendanceRecordValidator)
+	taskUsecase := usecase.NewTaskUsecase(taskRepository, userRepository, taskActivityRepository, taskRecurrenceRepository, taskValidator)
+	presenceUsecase := usecase.NewPresenceUsecase(attendanceRecordRepository, userRepository, presenceHub)
+	attendanceRecordUsecase := usecase.NewAttendanceRecordUsecase(attendanceRecordRepository, attendanceRecordValidator, presenceUsecase, userRepository)
+	reportingLineUsecase := usecase.NewReportingLineUsecase(reportingLineRepository, attendanceRecordRepository, reportingLineValidator)
+	employmentTypeUsecase := usecase.NewEmploymentTypeUsecase(employmentTypeRepository, employmentTypeValidator)
+	invitationUsecase := usecase.NewInvitationUsecase(invitationRepository, userRepository, employmentTypeRepository, invitationValidator, mailer)
+	scimUsecase := usecase.NewScimUsecase(userRepository, userValidator, invitationUsecase)
+	privacyUsecase := usecase.NewPrivacyUsecase(userRepository, attendanceRecordRepository, taskRepository, reportingLineRepository, invitationRepository, timeEntryRepository, timesheetRepository, taskActivityRepository, notificationRepository, leaveRepository, holidayWorkRequestRepository)
+	projectUsecase := usecase.NewProjectUsecase(projectRepository, projectValidator)
+	timeAllocationUsecase := usecase.NewTimeAllocationUsecase(timeAllocationRepository, attendanceRecordRepository, taskRepository, projectRepository, projectValidator)
+	projectBudgetUsecase := usecase.NewProjectBudgetUsecase(projectRepository, timeEntryRepository, notificationUsecase)
+	timeEntryUsecase := usecase.NewTimeEntryUsecase(timeEntryRepository, taskRepository, attendanceRecordRepository, timeEntryValidator, projectBudgetUsecase, timesheetRepository, projectRepository)
+	taskActivityUsecase := usecase.NewTaskActivityUsecase(taskActivityRepository, taskUsecase, taskValidator)
+	taskRecurrenceUsecase := usecase.NewTaskRecurrenceUsecase(taskRecurrenceRepository, userRepository, taskValidator)
+	timesheetUsecase := usecase.NewTimesheetUsecase(timesheetRepository, timeEntryRepository, userRepository, notificationUsecase)
+	billingUsecase := usecase.NewBillingUsecase(timeEntryRepository, projectRepository)
+	attendanceExceptionUsecase := usecase.NewAttendanceExceptionUsecase(attendanceExceptionRepository, userRepository, notificationUsecase)
+	jobUsecase := usecase.NewJobUsecase(jobRepository)
+	leaveUsecase := usecase.NewLeaveUsecase(leaveRepository, userRepository, employmentTypeRepository, leaveValidator)
+	holidayWorkRequestUsecase := usecase.NewHolidayWorkRequestUsecase(holidayWorkRequestRepository, userRepository, holidayWorkRequestValidator)
 
 	userController := controller.NewUserController(userUsecase)
 	authUserController := controller.NewAuthUserController(authUserUsecase) // AuthUser用のコントローラーを追加
 	taskController := controller.NewTaskController(taskUsecase)
 	attendanceRecordController := controller.NewAttendanceRecordController(attendanceRecordUsecase)
+	reportingLineController := controller.NewReportingLineController(reportingLineUsecase)
+	employmentTypeController := controller.NewEmploymentTypeController(employmentTypeUsecase)
+	invitationController := controller.NewInvitationController(invitationUsecase)
+	scimController := controller.NewScimController(scimUsecase)
+	privacyController := controller.NewPrivacyController(privacyUsecase)
+	projectController := controller.NewProjectController(projectUsecase, projectBudgetUsecase)
+	timeAllocationController := controller.NewTimeAllocationController(timeAllocationUsecase)
+	timeEntryController := controller.NewTimeEntryController(timeEntryUsecase)
+	billingController := controller.NewBillingController(billingUsecase)
+	timesheetController := controller.NewTimesheetController(timesheetUsecase)
+	taskActivityController := controller.NewTaskActivityController(taskActivityUsecase)
+	taskRecurrenceController := controller.NewTaskRecurrenceController(taskRecurrenceUsecase)
+	presenceController := controller.NewPresenceController(presenceUsecase)
+	attendanceExceptionController := controller.NewAttendanceExceptionController(attendanceExceptionUsecase)
+	notificationController := controller.NewNotificationController(notificationUsecase)
+	webhookController := controller.NewWebhookController(webhookUsecase)
+	jobController := controller.NewJobController(jobUsecase)
+	leaveController := controller.NewLeaveController(leaveUsecase)
+	holidayWorkRequestController := controller.NewHolidayWorkRequestController(holidayWorkRequestUsecase)
+	authMiddleware := controller.NewAuthMiddleware(userUsecase)
 
-	e := router.NewRouter(userController, authUserController, taskController, attendanceRecordController) // ルーターにAuthUserコントローラーを追加
-	e.Logger.Fatal(e.Start(":8080"))
+	e := router.NewRouter(userController, authUserController, taskController, attendanceRecordController, reportingLineController, employmentTypeController, invitationController, scimController, privacyController, projectController, timeAllocationController, timeEntryController, billingController, timesheetController, taskActivityController, taskRecurrenceController, presenceController, attendanceExceptionController, notificationController, webhookController, jobController, leaveController, holidayWorkRequestController, authMiddleware) // ルーターにAuthUserコントローラーを追加
+
+	// 定期ジョブ。スケジュールは cron 形式で、実行状態と履歴は DB に残す
+	jobs := []struct {
+		name string
+		spec string
+		fn   usecase.JobFunc
+	}{
+		{"task_recurrences", "0 * * * *", func(ctx context.Context) error {
+			created, err := taskRecurrenceUsecase.GenerateOccurrences(time.Now())
+			if err == nil && created > 0 {
+				log.Printf("generated %d recurring tasks", created)
+			}
+			return err
+		}},
+		{"attendance_anomalies", "15 * * * *", func(ctx context.Context) error {
+			detected, err := attendanceExceptionUsecase.DetectAnomalies(time.Now())
+			if err == nil && detected > 0 {
+				log.Printf("detected %d attendance anomalies", detected)
+			}
+			return err
+		}},
+		{"clock_reminders", "*/5 * * * *", func(ctx context.Context) error {
+			sent, err := reminderUsecase.SendReminders(time.Now())
+			if err == nil && sent > 0 {
+				log.Printf("sent %d reminders", sent)
+			}
+			return err
+		}},
+	}
+	for _, v := range jobs {
+		if err := jobUsecase.Register(v.name, v.spec, v.fn); err != nil {
+			log.Fatal(err)
+		}
+	}
+	jobUsecase.Start()
+	// 配信は数秒おきに回すため cron ではなくワーカーとして動かす
+	jobUsecase.StartWorker("outbox_dispatcher", 2*time.Second, func(ctx context.Context) error {
+		_, err := outboxUsecase.DispatchPending(time.Now())
+		return err
+	})
+	jobUsecase.StartWorker("webhook_dispatcher", 10*time.Second, func(ctx context.Context) error {
+		_, err := webhookUsecase.DispatchPending(time.Now())
+		return err
+	})
+
+	// 在席状況のストリームはクライアントが切断するまで続くため、停止を始めた時点で閉じる
+	e.Server.RegisterOnShutdown(presenceHub.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		if err := e.Start(":8080"); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+	<-ctx.Done()
+
+	// 新しいリクエストとジョブの受け付けを止め、処理中のものが終わるのを待ってから終了する。
+	// リクエストの待機で時間を使い切ってもジョブを待てるよう、期限はそれぞれに設ける
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		e.Logger.Error(err)
+	}
+	jobsCtx, cancelJobs := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelJobs()
+	if err := jobUsecase.Stop(jobsCtx); err != nil {
+		log.Printf("failed to stop jobs: %v", err)
+	}
 }
@@ -10,5 +10,5 @@ func main() {
 	dbConn := db.NewDB()
 	defer fmt.Println("Successfully Migrated")
 	defer db.CloseDB(dbConn)
-	dbConn.AutoMigrate(&model.User{}, &model.Task{}, &model.AttendanceRecord{}, &model.AuthUser{})
+	dbConn.AutoMigrate(&model.Project{}, &model.ProjectRate{}, &model.ProjectMember{}, &model.EmploymentType{}, &model.EmploymentTypeLeaveGrant{}, &model.User{}, &model.Task{}, &model.AttendanceRecord{}, &model.AttendanceBreak{}, &model.AuthUser{}, &model.ReportingLine{}, &model.Invitation{}, &model.TimeAllocation{}, &model.TimeEntry{}, &model.BudgetAlert{}, &model.Timesheet{}, &model.TimesheetApproval{}, &model.TaskComment{}, &model.TaskCommentRevision{}, &model.TaskActivity{}, &model.TaskRecurrence{}, &model.TaskRecurrenceException{}, &model.Holiday{}, &model.AttendanceException{}, &model.Notification{}, &model.NotificationSetting{}, &model.NotificationPreference{}, &model.Reminder{}, &model.WebhookSubscription{}, &model.WebhookDelivery{}, &model.OutboxEvent{}, &model.Job{}, &model.JobRun{}, &model.LeaveRecord{}, &model.HolidayWorkRequest{})
 }
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+const (
+	AnomalyMissingClockOut = "missing_clock_out"
+	AnomalyOverlapping     = "overlapping"
+	AnomalyLongShift       = "long_shift"
+	AnomalyHolidayPunch    = "holiday_punch"
+	AnomalyScheduleGap     = "schedule_gap"
+)
+
+const (
+	ExceptionStatusOpen     = "open"
+	ExceptionStatusResolved = "resolved"
+)
+
+// AttendanceException は異常検知ジョブが見つけた勤怠記録の問題。管理者・マネージャーが確認して解決する
+type AttendanceException struct {
+	ID                 uint             `json:"id" gorm:"primaryKey"`
+	UserID             uint             `json:"user_id" gorm:"not null;index"`
+	AttendanceRecordID uint             `json:"attendance_record_id" gorm:"not null;uniqueIndex:idx_attendance_exceptions_record_type"`
+	Type               string           `json:"type" gorm:"not null;uniqueIndex:idx_attendance_exceptions_record_type"`
+	Date               time.Time        `json:"date" gorm:"type:date;not null;index"`
+	Detail             string           `json:"detail"`
+	Status             string           `json:"status" gorm:"not null;default:open;index"`
+	ResolvedBy         *uint            `json:"resolved_by"`
+	ResolvedAt         *time.Time       `json:"resolved_at"`
+	ResolutionNote     string           `json:"resolution_note"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	User               User             `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+	AttendanceRecord   AttendanceRecord `json:"-" gorm:"foreignKey:AttendanceRecordID; constraint:OnDelete:CASCADE"`
+	Resolver           *User            `json:"-" gorm:"foreignKey:ResolvedBy; constraint:OnDelete:SET NULL"`
+}
+
+type AttendanceExceptionResponse struct {
+	ID                 uint       `json:"id"`
+	UserID             uint       `json:"user_id"`
+	UserName           string     `json:"user_name"`
+	Department         string     `json:"department"`
+	AttendanceRecordID uint       `json:"attendance_record_id"`
+	Type               string     `json:"type"`
+	Date               time.Time  `json:"date"`
+	Detail             string     `json:"detail"`
+	Status             string     `json:"status"`
+	ResolvedBy         *uint      `json:"resolved_by"`
+	ResolvedAt         *time.Time `json:"resolved_at"`
+	ResolutionNote     string     `json:"resolution_note"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// AnomalyRules は異常検知の対象期間としきい値
+type AnomalyRules struct {
+	Since              time.Time
+	Until              time.Time
+	MaxShiftMinutes    int
+	ScheduleGapMinutes int
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// BillingRow はプロジェクト・請求ロール・単価・請求区分ごとに集計した作業時間。
+// HourlyRate が nil の行は単価を記録する前の作業時間
+type BillingRow struct {
+	ProjectID  uint
+	Role       string
+	HourlyRate *float64
+	Billable   bool
+	Minutes    float64
+}
+
+type InvoiceLine struct {
+	ProjectID        uint    `json:"project_id"`
+	ProjectCode      string  `json:"project_code"`
+	ProjectName      string  `json:"project_name"`
+	Role             string  `json:"role"`
+	HourlyRate       float64 `json:"hourly_rate"`
+	BillableHours    float64 `json:"billable_hours"`
+	NonBillableHours float64 `json:"non_billable_hours"`
+	Amount           float64 `json:"amount"`
+}
+
+type ClientInvoice struct {
+	Client           string        `json:"client"`
+	PeriodFrom       time.Time     `json:"period_from"`
+	PeriodTo         time.Time     `json:"period_to"`
+	Lines            []InvoiceLine `json:"lines"`
+	BillableHours    float64       `json:"billable_hours"`
+	NonBillableHours float64       `json:"non_billable_hours"`
+	TotalAmount      float64       `json:"total_amount"`
+}
+
+type BillingReportResponse struct {
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	Invoices    []ClientInvoice `json:"invoices"`
+	TotalAmount float64         `json:"total_amount"`
+}
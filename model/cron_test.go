@@ -0,0 +1,121 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func cronBits(values ...int) uint64 {
+	var bits uint64
+	for _, v := range values {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		min     int
+		max     int
+		want    uint64
+		wantErr bool
+	}{
+		{"star", "*", 1, 12, cronBits(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12), false},
+		{"value", "5", 0, 59, cronBits(5), false},
+		{"list", "1,3,5", 0, 59, cronBits(1, 3, 5), false},
+		{"range", "1-5", 0, 6, cronBits(1, 2, 3, 4, 5), false},
+		{"star step", "*/15", 0, 59, cronBits(0, 15, 30, 45), false},
+		{"value step runs to max", "5/20", 0, 59, cronBits(5, 25, 45), false},
+		{"range step", "1-10/3", 0, 59, cronBits(1, 4, 7, 10), false},
+		{"list of range and value", "0-2,22", 0, 23, cronBits(0, 1, 2, 22), false},
+		{"step larger than range", "10-12/5", 0, 59, cronBits(10), false},
+		{"zero step", "*/0", 0, 59, 0, true},
+		{"negative step", "*/-1", 0, 59, 0, true},
+		{"non numeric step", "*/x", 0, 59, 0, true},
+		{"reversed range", "5-1", 0, 59, 0, true},
+		{"open range", "1-", 0, 59, 0, true},
+		{"non numeric value", "a", 0, 59, 0, true},
+		{"value over max", "60", 0, 59, 0, true},
+		{"value under min", "0", 1, 31, 0, true},
+		{"range over max", "10-13", 1, 12, 0, true},
+		{"empty list item", "1,", 0, 59, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCronField(tt.field, tt.min, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCronField(%q) error = %v, wantErr %v", tt.field, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCronField(%q) = %b, want %b", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"every minute", "* * * * *", false},
+		{"descriptor", "@daily", false},
+		{"surrounding spaces", "  0 9 * * 1-5  ", false},
+		{"sunday as 7", "0 0 * * 7", false},
+		{"too few fields", "* * * *", true},
+		{"too many fields", "0 * * * * *", true},
+		{"unknown descriptor", "@yearly", true},
+		{"hour out of range", "0 24 * * *", true},
+		{"day of month zero", "0 0 0 * *", true},
+		{"month out of range", "0 0 1 13 *", true},
+		{"day of week out of range", "0 0 * * 8", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCron(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCron(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	at := func(year int, month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"next minute", "* * * * *", at(2024, 1, 1, 10, 15).Add(30 * time.Second), at(2024, 1, 1, 10, 16)},
+		{"step", "*/15 * * * *", at(2024, 1, 1, 10, 15), at(2024, 1, 1, 10, 30)},
+		{"step wraps hour", "*/15 * * * *", at(2024, 1, 1, 10, 50), at(2024, 1, 1, 11, 0)},
+		{"hourly", "@hourly", at(2024, 1, 1, 10, 15), at(2024, 1, 1, 11, 0)},
+		{"weekdays skip weekend", "30 9 * * 1-5", at(2024, 1, 6, 10, 0), at(2024, 1, 8, 9, 30)},
+		{"sunday as 7", "0 0 * * 7", at(2024, 1, 1, 0, 0), at(2024, 1, 7, 0, 0)},
+		{"31st skips short months", "0 0 31 * *", at(2024, 2, 1, 0, 0), at(2024, 3, 31, 0, 0)},
+		{"month range", "0 0 1 6-8 *", at(2024, 8, 2, 0, 0), at(2025, 6, 1, 0, 0)},
+		{"day of month or day of week", "0 0 15 * 1", at(2024, 1, 9, 0, 0), at(2024, 1, 15, 0, 0)},
+		{"day of week before day of month", "0 0 20 * 1", at(2024, 1, 9, 0, 0), at(2024, 1, 15, 0, 0)},
+		{"day of month and star", "0 0 15 * *", at(2024, 1, 9, 0, 0), at(2024, 1, 15, 0, 0)},
+		{"leap day", "0 0 29 2 *", at(2024, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"never", "0 0 30 2 *", at(2024, 1, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.spec, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// EmployeeAttendanceStats は期間内の従業員ごとの勤怠集計（SQL で集計した結果）
+type EmployeeAttendanceStats struct {
+	UserID             uint
+	Name               string
+	EmploymentType     string
+	DaysWorked         int
+	WorkedMinutes      int
+	OvertimeMinutes    int
+	LateCount          int
+	AbsenceCount       int
+	LeaveUsedDays      float64
+	AvgClockInSeconds  *float64
+	AvgClockOutSeconds *float64
+}
+
+// DepartmentAttendanceStats は部署全体の勤怠集計（SQL で集計した結果）
+type DepartmentAttendanceStats struct {
+	Headcount          int
+	DaysWorked         int
+	WorkedMinutes      int
+	OvertimeMinutes    int
+	LateCount          int
+	AbsenceCount       int
+	LeaveUsedDays      float64
+	AvgWorkedMinutes   *float64
+	AvgClockInSeconds  *float64
+	AvgClockOutSeconds *float64
+}
+
+type EmployeeDashboardResponse struct {
+	UserID          uint    `json:"user_id"`
+	Name            string  `json:"name"`
+	EmploymentType  string  `json:"employment_type,omitempty"`
+	DaysWorked      int     `json:"days_worked"`
+	WorkedMinutes   int     `json:"worked_minutes"`
+	OvertimeMinutes int     `json:"overtime_minutes"`
+	LateCount       int     `json:"late_count"`
+	AbsenceCount    int     `json:"absence_count"`
+	LeaveUsedDays   float64 `json:"leave_used_days"`
+	AvgClockIn      string  `json:"avg_clock_in,omitempty"`
+	AvgClockOut     string  `json:"avg_clock_out,omitempty"`
+}
+
+type DepartmentSummaryResponse struct {
+	Headcount           int     `json:"headcount"`
+	DaysWorked          int     `json:"days_worked"`
+	WorkedMinutes       int     `json:"worked_minutes"`
+	OvertimeMinutes     int     `json:"overtime_minutes"`
+	LateCount           int     `json:"late_count"`
+	AbsenceCount        int     `json:"absence_count"`
+	LeaveUsedDays       float64 `json:"leave_used_days"`
+	AvgWorkedMinutesDay int     `json:"avg_worked_minutes_per_day"`
+	AvgClockIn          string  `json:"avg_clock_in,omitempty"`
+	AvgClockOut         string  `json:"avg_clock_out,omitempty"`
+}
+
+type DepartmentDashboardResponse struct {
+	Department string                      `json:"department"`
+	From       time.Time                   `json:"from"`
+	To         time.Time                   `json:"to"`
+	Summary    DepartmentSummaryResponse   `json:"summary"`
+	Employees  []EmployeeDashboardResponse `json:"employees"`
+}
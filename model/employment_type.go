@@ -0,0 +1,90 @@
+package model
+
+import "time"
+
+const (
+	EmploymentTypeFullTime   = "full_time"
+	EmploymentTypePartTime   = "part_time"
+	EmploymentTypeContract   = "contract"
+	EmploymentTypeDispatched = "dispatched"
+)
+
+// 雇用区分が未設定のユーザーには法定労働時間（1日8時間）を適用する
+const DefaultOvertimeThresholdMinutes = 8 * 60
+
+// 始業時刻が未設定の場合の既定値（遅刻の判定に使う）
+const DefaultScheduledStartTime = "09:00"
+
+// 雇用区分が未設定のユーザーには法定労働時間（週40時間）を適用する
+const DefaultWeeklyOvertimeThresholdMinutes = 40 * 60
+
+// 所定労働時間が未設定の場合の既定値（終業時刻の算出に使う）
+const DefaultScheduledMinutesPerDay = 8 * 60
+
+type EmploymentType struct {
+	ID                       uint                       `json:"id" gorm:"primaryKey"`
+	Code                     string                     `json:"code" gorm:"not null;uniqueIndex"`
+	Name                     string                     `json:"name" gorm:"not null"`
+	ScheduledMinutesPerDay   int                        `json:"scheduled_minutes_per_day"`
+	ScheduledStartTime       string                     `json:"scheduled_start_time"`
+	OvertimeThresholdMinutes int                        `json:"overtime_threshold_minutes"`
+	WeeklyOvertimeThreshold  int                        `json:"weekly_overtime_threshold_minutes"`
+	LeaveGrants              []EmploymentTypeLeaveGrant `json:"leave_grants" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt                time.Time                  `json:"created_at"`
+	UpdatedAt                time.Time                  `json:"updated_at"`
+}
+
+// 勤続月数ごとの年次有給休暇付与日数
+type EmploymentTypeLeaveGrant struct {
+	ID               uint    `json:"id" gorm:"primaryKey"`
+	EmploymentTypeID uint    `json:"employment_type_id" gorm:"not null;index"`
+	ServiceMonths    int     `json:"service_months"`
+	Days             float64 `json:"days"`
+}
+
+type EmploymentTypeResponse struct {
+	ID                       uint                       `json:"id"`
+	Code                     string                     `json:"code"`
+	Name                     string                     `json:"name"`
+	ScheduledMinutesPerDay   int                        `json:"scheduled_minutes_per_day"`
+	ScheduledStartTime       string                     `json:"scheduled_start_time"`
+	OvertimeThresholdMinutes int                        `json:"overtime_threshold_minutes"`
+	WeeklyOvertimeThreshold  int                        `json:"weekly_overtime_threshold_minutes"`
+	LeaveGrants              []EmploymentTypeLeaveGrant `json:"leave_grants"`
+}
+
+// OvertimeMinutes は1日の実働分数のうち時間外となる分数を返す
+func (et *EmploymentType) OvertimeMinutes(workedMinutes int) int {
+	threshold := DefaultOvertimeThresholdMinutes
+	if et != nil && et.OvertimeThresholdMinutes > 0 {
+		threshold = et.OvertimeThresholdMinutes
+	}
+	if workedMinutes <= threshold {
+		return 0
+	}
+	return workedMinutes - threshold
+}
+
+// LeaveGrantPeriod は date の時点で有効な付与日数と、その付与期間（付与日から1年間）を返す。
+// 付与テーブルの最後の行を過ぎた後は、同じ日数を1年ごとに付与する。まだ付与されていない場合は ok が false になる
+func (et *EmploymentType) LeaveGrantPeriod(hireDate time.Time, date time.Time) (days float64, grantedAt time.Time, ok bool) {
+	if et == nil {
+		return 0, time.Time{}, false
+	}
+	months := (date.Year()-hireDate.Year())*12 + int(date.Month()) - int(hireDate.Month())
+	if date.Day() < hireDate.Day() {
+		months--
+	}
+	best := -1
+	for _, g := range et.LeaveGrants {
+		if g.ServiceMonths <= months && g.ServiceMonths > best {
+			best = g.ServiceMonths
+			days = g.Days
+		}
+	}
+	if best < 0 {
+		return 0, time.Time{}, false
+	}
+	grantMonths := best + (months-best)/12*12
+	return days, hireDate.AddDate(0, grantMonths, 0), true
+}
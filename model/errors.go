@@ -0,0 +1,98 @@
+package model
+
+import "fmt"
+
+// エラーの種類。HTTP ステータスへの対応はコントローラーで行う
+const (
+	ErrorKindBadRequest   = "bad_request"
+	ErrorKindUnauthorized = "unauthorized"
+	ErrorKindForbidden    = "forbidden"
+	ErrorKindNotFound     = "not_found"
+	ErrorKindConflict     = "conflict"
+	ErrorKindInvalid      = "invalid"
+)
+
+// DomainError は利用者に返してよいエラー。Code は変わらない識別子で、クライアントはメッセージではなくこれで判別する
+type DomainError struct {
+	Kind    string
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+// Is は Code が同じなら同じエラーとして扱う。メッセージに値を埋め込んだエラーも errors.Is で判別できる
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap は原因となったエラーを保持したコピーを返す
+func (e *DomainError) Wrap(err error) *DomainError {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+func newDomainError(kind string, code string, format string, args ...interface{}) *DomainError {
+	return &DomainError{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewBadRequestError(code string, format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrorKindBadRequest, code, format, args...)
+}
+
+func NewUnauthorizedError(code string, format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrorKindUnauthorized, code, format, args...)
+}
+
+func NewForbiddenError(code string, format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrorKindForbidden, code, format, args...)
+}
+
+func NewNotFoundError(code string, format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrorKindNotFound, code, format, args...)
+}
+
+func NewConflictError(code string, format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrorKindConflict, code, format, args...)
+}
+
+// NewInvalidError は入力の形式は正しいが業務上のルールに反する場合のエラー
+func NewInvalidError(code string, format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrorKindInvalid, code, format, args...)
+}
+
+var (
+	ErrNotFound           = NewNotFoundError("not_found", "resource not found")
+	ErrDuplicated         = NewConflictError("duplicated", "resource already exists")
+	ErrReferenced         = NewConflictError("reference_violation", "resource is referenced by or refers to a missing resource")
+	ErrForbidden          = NewForbiddenError("forbidden", "you do not have permission for this resource")
+	ErrInvalidCredentials = NewUnauthorizedError("invalid_credentials", "invalid email or password")
+	ErrEmailTaken         = NewConflictError("email_taken", "email is already in use")
+)
+
+// ProblemDetails は RFC 7807 の problem+json。Code と Errors は拡張メンバー
+type ProblemDetails struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`
+	Errors   []ProblemFieldError `json:"errors,omitempty"`
+}
+
+// ProblemFieldError は入力項目ごとの検証エラー。Code は ozzo-validation のエラーコード
+type ProblemFieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
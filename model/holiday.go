@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Holiday は会社の休日。休日出勤の承認がない打刻は異常として検知する
+type Holiday struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;uniqueIndex"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
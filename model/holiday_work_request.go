@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+const (
+	HolidayWorkPending  = "pending"
+	HolidayWorkApproved = "approved"
+	HolidayWorkRejected = "rejected"
+)
+
+// HolidayWorkRequest は会社の休日に出勤するための申請。承認された日の打刻は異常として検知しない
+type HolidayWorkRequest struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_holiday_work_requests_user_date"`
+	Date       time.Time  `json:"date" gorm:"type:date;not null;uniqueIndex:idx_holiday_work_requests_user_date"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status" gorm:"not null;default:pending;index"`
+	ReviewedBy *uint      `json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	User       User       `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+	Reviewer   *User      `json:"-" gorm:"foreignKey:ReviewedBy; constraint:OnDelete:SET NULL"`
+}
+
+type HolidayWorkRequestResponse struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	UserName   string     `json:"user_name"`
+	Department string     `json:"department"`
+	Date       time.Time  `json:"date"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	ReviewedBy *uint      `json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
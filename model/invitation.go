@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+type Invitation struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	Email            string     `json:"email" gorm:"not null;index"`
+	Name             string     `json:"name"`
+	Department       string     `json:"department"`
+	Role             string     `json:"role" gorm:"not null;default:member"`
+	HireDate         *time.Time `json:"hire_date"`
+	EmploymentTypeID *uint      `json:"employment_type_id"`
+	UserID           *uint      `json:"user_id"`
+	InvitedBy        *uint      `json:"invited_by"`
+	TokenHash        string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	AcceptedAt       *time.Time `json:"accepted_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusExpired  = "expired"
+	InvitationStatusAccepted = "accepted"
+)
+
+type InvitationResponse struct {
+	ID         uint       `json:"id"`
+	Email      string     `json:"email"`
+	Name       string     `json:"name"`
+	Department string     `json:"department"`
+	Role       string     `json:"role"`
+	HireDate   *time.Time `json:"hire_date,omitempty"`
+	Status     string     `json:"status"`
+	InvitedBy  *uint      `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Status は招待の現在の状態を返す
+func (i Invitation) Status(now time.Time) string {
+	if i.AcceptedAt != nil {
+		return InvitationStatusAccepted
+	}
+	if i.ExpiresAt.Before(now) {
+		return InvitationStatusExpired
+	}
+	return InvitationStatusPending
+}
+
+const (
+	ImportStatusValid   = "valid"
+	ImportStatusInvited = "invited"
+	ImportStatusError   = "error"
+)
+
+type UserImportRowResult struct {
+	Row    int    `json:"row"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type UserImportResponse struct {
+	DryRun  bool                  `json:"dry_run"`
+	Total   int                   `json:"total"`
+	Invited int                   `json:"invited"`
+	Failed  int                   `json:"failed"`
+	Rows    []UserImportRowResult `json:"rows"`
+}
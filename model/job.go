@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// Job はスケジューラーに登録された定期ジョブの状態。複数のレプリカで共有するため DB に保存する
+type Job struct {
+	Name       string     `json:"name" gorm:"primaryKey"`
+	Schedule   string     `json:"schedule" gorm:"not null"`
+	Enabled    bool       `json:"enabled" gorm:"not null;default:true"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastStatus string     `json:"last_status"`
+	LastError  string     `json:"last_error"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// JobRun はジョブの実行履歴
+type JobRun struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	JobName    string     `json:"job_name" gorm:"not null;index"`
+	Instance   string     `json:"instance"`
+	Status     string     `json:"status" gorm:"not null"`
+	Error      string     `json:"error"`
+	StartedAt  time.Time  `json:"started_at" gorm:"not null"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Job        Job        `json:"-" gorm:"foreignKey:JobName; constraint:OnDelete:CASCADE"`
+}
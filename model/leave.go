@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// LeaveRecord は年次有給休暇の取得日。半日休暇は Days を 0.5 にする
+type LeaveRecord struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_leave_records_user_date"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_leave_records_user_date;index"`
+	Days      float64   `json:"days" gorm:"not null"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+}
+
+// LeaveBalanceResponse は現在の付与期間の付与日数・取得日数・残日数。前期間からの繰越は含めない
+type LeaveBalanceResponse struct {
+	GrantedAt     *time.Time `json:"granted_at"`
+	NextGrantAt   *time.Time `json:"next_grant_at"`
+	GrantedDays   float64    `json:"granted_days"`
+	UsedDays      float64    `json:"used_days"`
+	RemainingDays float64    `json:"remaining_days"`
+}
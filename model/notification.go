@@ -0,0 +1,108 @@
+package model
+
+import "time"
+
+const (
+	NotificationForgottenClockOut = "forgotten_clock_out"
+	NotificationApprovalRequested = "approval_requested"
+	NotificationTimesheetReviewed = "timesheet_reviewed"
+	NotificationBudgetWarning     = "budget_warning"
+	NotificationClockInReminder   = "clock_in_reminder"
+	NotificationClockOutReminder  = "clock_out_reminder"
+)
+
+// NotificationEvents は通知の設定画面に並べるイベントの一覧
+var NotificationEvents = []string{
+	NotificationForgottenClockOut,
+	NotificationApprovalRequested,
+	NotificationTimesheetReviewed,
+	NotificationBudgetWarning,
+	NotificationClockInReminder,
+	NotificationClockOutReminder,
+}
+
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelInApp   = "in_app"
+)
+
+var NotificationChannels = []string{
+	NotificationChannelEmail,
+	NotificationChannelWebhook,
+	NotificationChannelInApp,
+}
+
+const (
+	LocaleJa = "ja"
+	LocaleEn = "en"
+)
+
+// Notification はアプリ内の受信箱に届いた通知
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Event     string     `json:"event" gorm:"not null"`
+	Title     string     `json:"title" gorm:"not null"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+}
+
+// 打刻リマインダーの既定のタイミング（始業から・終業からの分数）
+const (
+	DefaultClockInReminderMinutes  = 15
+	DefaultClockOutReminderMinutes = 30
+)
+
+// NotificationSetting はユーザーごとの通知の言語、Webhook の送信先、打刻リマインダーのタイミング。
+// 未登録のユーザーは日本語で Webhook なし、リマインダーは既定のタイミング
+type NotificationSetting struct {
+	UserID                  uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Locale                  string    `json:"locale" gorm:"not null;default:ja"`
+	WebhookURL              string    `json:"webhook_url"`
+	ClockInReminderMinutes  *int      `json:"clock_in_reminder_minutes"`
+	ClockOutReminderMinutes *int      `json:"clock_out_reminder_minutes"`
+	UpdatedAt               time.Time `json:"updated_at"`
+	User                    User      `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+}
+
+// NotificationPreference はイベントとチャネルの組み合わせごとの受信可否。未登録の組み合わせは受信する
+type NotificationPreference struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	UserID  uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_notification_preferences_event_channel"`
+	Event   string `json:"event" gorm:"not null;uniqueIndex:idx_notification_preferences_event_channel"`
+	Channel string `json:"channel" gorm:"not null;uniqueIndex:idx_notification_preferences_event_channel"`
+	Enabled bool   `json:"enabled"`
+	User    User   `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+}
+
+type NotificationPreferenceResponse struct {
+	Event   string `json:"event"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+type NotificationSettingsResponse struct {
+	Locale                  string                           `json:"locale"`
+	WebhookURL              string                           `json:"webhook_url"`
+	ClockInReminderMinutes  int                              `json:"clock_in_reminder_minutes"`
+	ClockOutReminderMinutes int                              `json:"clock_out_reminder_minutes"`
+	Preferences             []NotificationPreferenceResponse `json:"preferences"`
+}
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Event     string     `json:"event"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// WebhookMessage は Slack の Incoming Webhook 互換の送信内容
+type WebhookMessage struct {
+	Text  string `json:"text"`
+	Event string `json:"event"`
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ドメインイベントの種別。Webhook の購読でもこの名前を使う
+const (
+	EventAttendanceClockIn         = "attendance.clock_in"
+	EventAttendanceClockOut        = "attendance.clock_out"
+	EventAttendanceBreakStart      = "attendance.break_start"
+	EventAttendanceBreakEnd        = "attendance.break_end"
+	EventAttendanceRecordCorrected = "attendance.record_corrected"
+	EventPeriodLocked              = "period.locked"
+)
+
+// OutboxEvent は状態の変更と同じトランザクションで書き込むドメインイベント。
+// ディスパッチャーが購読者へ届け終えるまで ProcessedAt は NULL のまま残る
+type OutboxEvent struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Type          string     `json:"type" gorm:"not null"`
+	AggregateType string     `json:"aggregate_type" gorm:"not null"`
+	AggregateID   uint       `json:"aggregate_id"`
+	Payload       string     `json:"payload" gorm:"type:text;not null"`
+	OccurredAt    time.Time  `json:"occurred_at" gorm:"not null"`
+	ProcessedAt   *time.Time `json:"processed_at" gorm:"index"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+}
+
+// NewOutboxEvent は data を JSON にしてイベントを作る
+func NewOutboxEvent(eventType string, aggregateType string, aggregateId uint, data interface{}) (OutboxEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	now := time.Now()
+	return OutboxEvent{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateId,
+		Payload:       string(payload),
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+type AttendanceEventData struct {
+	RecordID     uint       `json:"record_id"`
+	UserID       uint       `json:"user_id"`
+	ClockInTime  time.Time  `json:"clock_in_time"`
+	ClockOutTime *time.Time `json:"clock_out_time"`
+	Deleted      bool       `json:"deleted,omitempty"`
+}
+
+func NewAttendanceEventData(record AttendanceRecord) AttendanceEventData {
+	data := AttendanceEventData{
+		RecordID:    record.ID,
+		UserID:      record.UserID,
+		ClockInTime: record.ClockInTime,
+	}
+	if record.ClockOutTime.After(record.ClockInTime) {
+		data.ClockOutTime = &record.ClockOutTime
+	}
+	return data
+}
+
+// AttendanceCreateEvent は退勤時刻まで入力された記録を打刻ではなく後からの登録（修正）として扱う
+func AttendanceCreateEvent(record AttendanceRecord) string {
+	if record.ClockOutTime.IsZero() {
+		return EventAttendanceClockIn
+	}
+	return EventAttendanceRecordCorrected
+}
+
+// AttendanceUpdateEvent は出勤中の記録に退勤時刻だけを入れる更新を退勤の打刻とし、それ以外は修正とする
+func AttendanceUpdateEvent(current AttendanceRecord, updated AttendanceRecord) string {
+	if !current.ClockOutTime.After(current.ClockInTime) && updated.ClockInTime.Equal(current.ClockInTime) {
+		return EventAttendanceClockOut
+	}
+	return EventAttendanceRecordCorrected
+}
+
+type AttendanceBreakEventData struct {
+	RecordID  uint       `json:"record_id"`
+	UserID    uint       `json:"user_id"`
+	BreakID   uint       `json:"break_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+}
+
+type PeriodEventData struct {
+	UserID      uint      `json:"user_id"`
+	TimesheetID uint      `json:"timesheet_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// PageQuery は一覧取得のページング条件。Cursor は前のページの next_cursor で、Limit が 0 なら全件を返す
+type PageQuery struct {
+	Cursor string
+	Limit  int
+}
+
+type PageInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
+}
+
+// PageResponse はページングした一覧のレスポンス
+type PageResponse struct {
+	Data interface{} `json:"data"`
+	Page PageInfo    `json:"page"`
+}
+
+// AttendanceRecordFilter は勤怠記録一覧の絞り込み条件。From, To は出勤時刻の範囲（To を含まない）
+type AttendanceRecordFilter struct {
+	Department     string
+	EmploymentType string
+	From           *time.Time
+	To             *time.Time
+	Sort           string
+}
+
+// UserFilter はユーザー一覧の絞り込み条件。From, To は登録日時の範囲（To を含まない）
+type UserFilter struct {
+	EmploymentType string
+	From           *time.Time
+	To             *time.Time
+	Sort           string
+}
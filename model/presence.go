@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// PresenceEvent は在席状況の変化。Status は AttendanceStatus* のいずれか
+type PresenceEvent struct {
+	UserID     uint      `json:"user_id"`
+	Name       string    `json:"name"`
+	Department string    `json:"department"`
+	Status     string    `json:"status"`
+	At         time.Time `json:"at"`
+}
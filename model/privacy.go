@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// 本人からの開示請求に応じて出力する個人データ一式
+type PersonalDataProfile struct {
+	ID              uint       `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Department      string     `json:"department"`
+	Role            string     `json:"role"`
+	ExternalID      string     `json:"external_id,omitempty"`
+	HireDate        *time.Time `json:"hire_date,omitempty"`
+	TerminationDate *time.Time `json:"termination_date,omitempty"`
+	DeactivatedAt   *time.Time `json:"deactivated_at,omitempty"`
+	EmploymentType  string     `json:"employment_type,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type PersonalDataExport struct {
+	ExportedAt          time.Time                    `json:"exported_at"`
+	Profile             PersonalDataProfile          `json:"profile"`
+	AttendanceRecords   []AttendanceRecordResponse   `json:"attendance_records"`
+	Tasks               []TaskResponse               `json:"tasks"`
+	TimeEntries         []TimeEntryResponse          `json:"time_entries"`
+	ReportingLines      []ReportingLineResponse      `json:"reporting_lines"`
+	Invitations         []InvitationResponse         `json:"invitations"`
+	Timesheets          []TimesheetResponse          `json:"timesheets"`
+	TaskComments        []TaskCommentResponse        `json:"task_comments"`
+	TaskActivities      []TaskActivityResponse       `json:"task_activities"`
+	Notifications       []NotificationResponse       `json:"notifications"`
+	LeaveRecords        []LeaveRecord                `json:"leave_records"`
+	HolidayWorkRequests []HolidayWorkRequestResponse `json:"holiday_work_requests"`
+}
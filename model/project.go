@@ -0,0 +1,168 @@
+package model
+
+import "time"
+
+type Project struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	Code        string          `json:"code" gorm:"not null;uniqueIndex"`
+	Name        string          `json:"name" gorm:"not null"`
+	Client      string          `json:"client"`
+	BudgetHours float64         `json:"budget_hours"`
+	HourlyRate  float64         `json:"hourly_rate"`
+	LeadID      *uint           `json:"lead_id"`
+	StartDate   *time.Time      `json:"start_date"`
+	EndDate     *time.Time      `json:"end_date"`
+	Lead        *User           `json:"-" gorm:"foreignKey:LeadID; constraint:OnDelete:SET NULL"`
+	Rates       []ProjectRate   `json:"rates,omitempty" gorm:"foreignKey:ProjectID; constraint:OnDelete:CASCADE"`
+	Members     []ProjectMember `json:"members,omitempty" gorm:"foreignKey:ProjectID; constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ProjectRate はプロジェクトごとの請求ロール別時間単価。未設定のロールはプロジェクトの HourlyRate を使う
+type ProjectRate struct {
+	ID         uint    `json:"id" gorm:"primaryKey"`
+	ProjectID  uint    `json:"project_id" gorm:"not null;uniqueIndex:idx_project_rates_role"`
+	Role       string  `json:"role" gorm:"not null;uniqueIndex:idx_project_rates_role"`
+	HourlyRate float64 `json:"hourly_rate" gorm:"not null"`
+}
+
+// ProjectMember はプロジェクトでの請求ロール。権限のロール（admin など）とは別に、単価の区分として使う
+type ProjectMember struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ProjectID   uint      `json:"project_id" gorm:"not null;uniqueIndex:idx_project_members_user"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_project_members_user"`
+	BillingRole string    `json:"billing_role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	User        User      `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+}
+
+type ProjectMemberResponse struct {
+	UserID      uint   `json:"user_id"`
+	BillingRole string `json:"billing_role"`
+}
+
+type ProjectRateResponse struct {
+	Role       string  `json:"role"`
+	HourlyRate float64 `json:"hourly_rate"`
+}
+
+type ProjectResponse struct {
+	ID          uint                    `json:"id"`
+	Code        string                  `json:"code"`
+	Name        string                  `json:"name"`
+	Client      string                  `json:"client"`
+	BudgetHours float64                 `json:"budget_hours"`
+	HourlyRate  float64                 `json:"hourly_rate"`
+	LeadID      *uint                   `json:"lead_id"`
+	StartDate   *time.Time              `json:"start_date"`
+	EndDate     *time.Time              `json:"end_date"`
+	Rates       []ProjectRateResponse   `json:"rates"`
+	Members     []ProjectMemberResponse `json:"members"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// RateFor は請求ロールに適用する時間単価を返す
+func (p Project) RateFor(role string) float64 {
+	for _, r := range p.Rates {
+		if r.Role == role {
+			return r.HourlyRate
+		}
+	}
+	return p.HourlyRate
+}
+
+// BillingRoleOf はメンバーの請求ロールを返す。メンバーでない場合は空（プロジェクトの既定単価）になる
+func (p Project) BillingRoleOf(userId uint) string {
+	for _, m := range p.Members {
+		if m.UserID == userId {
+			return m.BillingRole
+		}
+	}
+	return ""
+}
+
+// IsActiveOn は指定日がプロジェクトの有効期間内かどうかを返す
+func (p Project) IsActiveOn(date time.Time) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	if p.StartDate != nil && day.Before(time.Date(p.StartDate.Year(), p.StartDate.Month(), p.StartDate.Day(), 0, 0, 0, 0, date.Location())) {
+		return false
+	}
+	if p.EndDate != nil && day.After(time.Date(p.EndDate.Year(), p.EndDate.Month(), p.EndDate.Day(), 0, 0, 0, 0, date.Location())) {
+		return false
+	}
+	return true
+}
+
+// 勤怠1日分の実働時間をタスク・プロジェクトに按分したもの
+type TimeAllocation struct {
+	ID                 uint             `json:"id" gorm:"primaryKey"`
+	UserID             uint             `json:"user_id" gorm:"not null;index"`
+	AttendanceRecordID uint             `json:"attendance_record_id" gorm:"not null;index"`
+	TaskID             *uint            `json:"task_id"`
+	ProjectID          *uint            `json:"project_id" gorm:"index"`
+	Minutes            int              `json:"minutes" gorm:"not null"`
+	Note               string           `json:"note"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	AttendanceRecord   AttendanceRecord `json:"-" gorm:"foreignKey:AttendanceRecordID; constraint:OnDelete:CASCADE"`
+	Task               *Task            `json:"-" gorm:"foreignKey:TaskID; constraint:OnDelete:SET NULL"`
+	Project            *Project         `json:"-" gorm:"foreignKey:ProjectID; constraint:OnDelete:RESTRICT"`
+}
+
+type TimeAllocationResponse struct {
+	ID                 uint   `json:"id"`
+	AttendanceRecordID uint   `json:"attendance_record_id"`
+	TaskID             *uint  `json:"task_id"`
+	ProjectID          *uint  `json:"project_id"`
+	Minutes            int    `json:"minutes"`
+	Note               string `json:"note"`
+}
+
+type DayAllocationResponse struct {
+	AttendanceRecordID uint                     `json:"attendance_record_id"`
+	WorkedMinutes      int                      `json:"worked_minutes"`
+	AllocatedMinutes   int                      `json:"allocated_minutes"`
+	UnallocatedMinutes int                      `json:"unallocated_minutes"`
+	Allocations        []TimeAllocationResponse `json:"allocations"`
+}
+
+// BudgetAlert は予算時間の消化率がしきい値を超えたときに一度だけ記録される警告イベント
+type BudgetAlert struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	ProjectID        uint      `json:"project_id" gorm:"not null;uniqueIndex:idx_budget_alerts_threshold"`
+	ThresholdPercent int       `json:"threshold_percent" gorm:"not null;uniqueIndex:idx_budget_alerts_threshold"`
+	UsedHours        float64   `json:"used_hours"`
+	BudgetHours      float64   `json:"budget_hours"`
+	CreatedAt        time.Time `json:"created_at"`
+	Project          Project   `json:"-" gorm:"foreignKey:ProjectID; constraint:OnDelete:CASCADE"`
+}
+
+// WeeklyMinutesRow は週（月曜始まり）ごとに集計した作業時間
+type WeeklyMinutesRow struct {
+	WeekStart time.Time
+	Minutes   float64
+}
+
+type BurndownWeek struct {
+	WeekStart       time.Time `json:"week_start"`
+	Hours           float64   `json:"hours"`
+	CumulativeHours float64   `json:"cumulative_hours"`
+	RemainingHours  float64   `json:"remaining_hours"`
+}
+
+type ProjectBurndownResponse struct {
+	ProjectID             uint           `json:"project_id"`
+	Code                  string         `json:"code"`
+	Name                  string         `json:"name"`
+	BudgetHours           float64        `json:"budget_hours"`
+	UsedHours             float64        `json:"used_hours"`
+	RemainingHours        float64        `json:"remaining_hours"`
+	UsedPercent           float64        `json:"used_percent"`
+	RunRateHoursPerWeek   float64        `json:"run_rate_hours_per_week"`
+	ProjectedExhaustionAt *time.Time     `json:"projected_exhaustion_at"`
+	Weeks                 []BurndownWeek `json:"weeks"`
+	Alerts                []BudgetAlert  `json:"alerts"`
+}
@@ -0,0 +1,213 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RecurrenceDaily   = "DAILY"
+	RecurrenceWeekly  = "WEEKLY"
+	RecurrenceMonthly = "MONTHLY"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// RecurrenceRule は RRULE の一部（FREQ, INTERVAL, BYDAY, BYMONTHDAY）に対応した繰り返し規則
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+}
+
+// ParseRecurrenceRule は "FREQ=WEEKLY;BYDAY=MO,TH" のような文字列を解析する
+func ParseRecurrenceRule(s string) (RecurrenceRule, error) {
+	rule := RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return rule, fmt.Errorf("invalid rrule part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch key {
+		case "FREQ":
+			if value != RecurrenceDaily && value != RecurrenceWeekly && value != RecurrenceMonthly {
+				return rule, fmt.Errorf("unsupported FREQ %s", value)
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid INTERVAL %s", value)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[d]
+				if !ok {
+					return rule, fmt.Errorf("invalid BYDAY %s", d)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n == 0 || n < -31 || n > 31 {
+				return rule, fmt.Errorf("invalid BYMONTHDAY %s", value)
+			}
+			rule.ByMonthDay = n
+		default:
+			return rule, fmt.Errorf("unsupported rrule part %s", key)
+		}
+	}
+	if rule.Freq == "" {
+		return rule, fmt.Errorf("FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != RecurrenceWeekly {
+		return rule, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if rule.ByMonthDay != 0 && rule.Freq != RecurrenceMonthly {
+		return rule, fmt.Errorf("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	return rule, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// matches は start を起点とした規則に date が該当するかを返す
+func (r RecurrenceRule) matches(start time.Time, date time.Time) bool {
+	switch r.Freq {
+	case RecurrenceDaily:
+		days := int(date.Sub(start).Hours()/24 + 0.5)
+		return days%r.Interval == 0
+	case RecurrenceWeekly:
+		weekOf := func(t time.Time) time.Time { return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7)) }
+		weeks := int(weekOf(date).Sub(weekOf(start)).Hours()/(24*7) + 0.5)
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return date.Weekday() == start.Weekday()
+		}
+		for _, d := range r.ByDay {
+			if date.Weekday() == d {
+				return true
+			}
+		}
+		return false
+	case RecurrenceMonthly:
+		months := (date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		// 月末より大きい日付はその月の末日、負の値は月末から数えた日とする
+		lastDay := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+		day := r.ByMonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		if day < 0 {
+			day = lastDay + day + 1
+		}
+		if day > lastDay {
+			day = lastDay
+		}
+		return date.Day() == day
+	}
+	return false
+}
+
+// Occurrences は start 以降で from〜to（両端を含む）の間に該当する日付を返す
+func (r RecurrenceRule) Occurrences(start time.Time, from time.Time, to time.Time) []time.Time {
+	start = truncateDay(start)
+	d := truncateDay(from)
+	if d.Before(start) {
+		d = start
+	}
+	dates := []time.Time{}
+	for ; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if r.matches(start, d) {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+// TaskRecurrence は繰り返しタスクのシリーズ。各回のタスクはこの内容を元に生成される
+type TaskRecurrence struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	Title          string     `json:"title" gorm:"not null"`
+	Description    string     `json:"description"`
+	Priority       string     `json:"priority" gorm:"not null;default:medium"`
+	EstimatedHours float64    `json:"estimated_hours"`
+	Visibility     string     `json:"visibility" gorm:"not null;default:private"`
+	Department     string     `json:"department"`
+	AssigneeID     *uint      `json:"assignee_id"`
+	ProjectID      *uint      `json:"project_id"`
+	RRule          string     `json:"rrule" gorm:"not null"`
+	StartDate      time.Time  `json:"start_date" gorm:"type:date;not null"`
+	Until          *time.Time `json:"until" gorm:"type:date"`
+	GeneratedUntil *time.Time `json:"generated_until" gorm:"type:date"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	User           User       `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+	Assignee       *User      `json:"-" gorm:"foreignKey:AssigneeID; constraint:OnDelete:SET NULL"`
+	Project        *Project   `json:"-" gorm:"foreignKey:ProjectID; constraint:OnDelete:SET NULL"`
+}
+
+// TaskRecurrenceException は個別に削除され、再生成しない回の日付
+type TaskRecurrenceException struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	RecurrenceID   uint           `json:"recurrence_id" gorm:"not null;uniqueIndex:idx_recurrence_exceptions_date"`
+	OccurrenceDate time.Time      `json:"occurrence_date" gorm:"type:date;not null;uniqueIndex:idx_recurrence_exceptions_date"`
+	Recurrence     TaskRecurrence `json:"-" gorm:"foreignKey:RecurrenceID; constraint:OnDelete:CASCADE"`
+}
+
+type TaskRecurrenceResponse struct {
+	ID             uint       `json:"id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Priority       string     `json:"priority"`
+	EstimatedHours float64    `json:"estimated_hours"`
+	Visibility     string     `json:"visibility"`
+	Department     string     `json:"department,omitempty"`
+	AssigneeID     *uint      `json:"assignee_id"`
+	ProjectID      *uint      `json:"project_id"`
+	RRule          string     `json:"rrule"`
+	StartDate      time.Time  `json:"start_date"`
+	Until          *time.Time `json:"until"`
+	GeneratedUntil *time.Time `json:"generated_until"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewOccurrence は指定日の回のタスクを組み立てる。期日はその日になる
+func (tr TaskRecurrence) NewOccurrence(date time.Time) Task {
+	id := tr.ID
+	return Task{
+		Title:          tr.Title,
+		Description:    tr.Description,
+		Status:         TaskStatusTodo,
+		Priority:       tr.Priority,
+		DueDate:        &date,
+		EstimatedHours: tr.EstimatedHours,
+		Visibility:     tr.Visibility,
+		Department:     tr.Department,
+		UserId:         tr.UserID,
+		AssigneeID:     tr.AssigneeID,
+		ProjectID:      tr.ProjectID,
+		RecurrenceID:   &id,
+		OccurrenceDate: &date,
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rrule   string
+		want    RecurrenceRule
+		wantErr bool
+	}{
+		{"daily", "FREQ=DAILY", RecurrenceRule{Freq: RecurrenceDaily, Interval: 1}, false},
+		{"prefix and lower case", "RRULE:freq=weekly;interval=2;byday=mo,th", RecurrenceRule{Freq: RecurrenceWeekly, Interval: 2, ByDay: []time.Weekday{time.Monday, time.Thursday}}, false},
+		{"trailing separator", "FREQ=MONTHLY;BYMONTHDAY=31;", RecurrenceRule{Freq: RecurrenceMonthly, Interval: 1, ByMonthDay: 31}, false},
+		{"negative month day", "FREQ=MONTHLY;BYMONTHDAY=-1", RecurrenceRule{Freq: RecurrenceMonthly, Interval: 1, ByMonthDay: -1}, false},
+		{"missing FREQ", "INTERVAL=2", RecurrenceRule{}, true},
+		{"unsupported FREQ", "FREQ=YEARLY", RecurrenceRule{}, true},
+		{"part without value", "FREQ", RecurrenceRule{}, true},
+		{"unsupported part", "FREQ=DAILY;COUNT=3", RecurrenceRule{}, true},
+		{"zero INTERVAL", "FREQ=DAILY;INTERVAL=0", RecurrenceRule{}, true},
+		{"non numeric INTERVAL", "FREQ=DAILY;INTERVAL=x", RecurrenceRule{}, true},
+		{"invalid BYDAY", "FREQ=WEEKLY;BYDAY=MO,XX", RecurrenceRule{}, true},
+		{"BYDAY with DAILY", "FREQ=DAILY;BYDAY=MO", RecurrenceRule{}, true},
+		{"zero BYMONTHDAY", "FREQ=MONTHLY;BYMONTHDAY=0", RecurrenceRule{}, true},
+		{"BYMONTHDAY over 31", "FREQ=MONTHLY;BYMONTHDAY=32", RecurrenceRule{}, true},
+		{"BYMONTHDAY under -31", "FREQ=MONTHLY;BYMONTHDAY=-32", RecurrenceRule{}, true},
+		{"BYMONTHDAY with WEEKLY", "FREQ=WEEKLY;BYMONTHDAY=1", RecurrenceRule{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecurrenceRule(tt.rrule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRecurrenceRule(%q) error = %v, wantErr %v", tt.rrule, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRecurrenceRule(%q) = %+v, want %+v", tt.rrule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		rrule string
+		start time.Time
+		date  time.Time
+		want  bool
+	}{
+		{"daily on start", "FREQ=DAILY", date(2024, 1, 3), date(2024, 1, 3), true},
+		{"daily interval hit", "FREQ=DAILY;INTERVAL=3", date(2024, 1, 30), date(2024, 2, 2), true},
+		{"daily interval miss", "FREQ=DAILY;INTERVAL=3", date(2024, 1, 30), date(2024, 2, 1), false},
+		{"weekly same weekday", "FREQ=WEEKLY", date(2024, 1, 3), date(2024, 1, 10), true},
+		{"weekly other weekday", "FREQ=WEEKLY", date(2024, 1, 3), date(2024, 1, 11), false},
+		// 週は月曜始まりで数えるため、水曜に始めても同じ週の月曜は対象の週になる
+		{"interval week of start", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", date(2024, 1, 3), date(2024, 1, 1), true},
+		{"interval skipped week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", date(2024, 1, 3), date(2024, 1, 8), false},
+		{"interval skipped week later day", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", date(2024, 1, 3), date(2024, 1, 10), false},
+		{"interval next monday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", date(2024, 1, 3), date(2024, 1, 15), true},
+		{"interval next wednesday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", date(2024, 1, 3), date(2024, 1, 17), true},
+		{"interval weekday not listed", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", date(2024, 1, 3), date(2024, 1, 16), false},
+		{"interval across year", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", date(2024, 12, 25), date(2025, 1, 12), true},
+		{"monthly start day", "FREQ=MONTHLY", date(2024, 1, 15), date(2024, 2, 15), true},
+		{"monthly interval miss", "FREQ=MONTHLY;INTERVAL=2", date(2024, 1, 15), date(2024, 2, 15), false},
+		{"monthly interval hit", "FREQ=MONTHLY;INTERVAL=2", date(2024, 1, 15), date(2024, 3, 15), true},
+		{"31st in long month", "FREQ=MONTHLY;BYMONTHDAY=31", date(2024, 1, 31), date(2024, 3, 31), true},
+		{"31st clamps to 30th", "FREQ=MONTHLY;BYMONTHDAY=31", date(2024, 1, 31), date(2024, 4, 30), true},
+		{"31st clamps to leap day", "FREQ=MONTHLY;BYMONTHDAY=31", date(2024, 1, 31), date(2024, 2, 29), true},
+		{"31st not before leap day", "FREQ=MONTHLY;BYMONTHDAY=31", date(2024, 1, 31), date(2024, 2, 28), false},
+		{"31st clamps to 28th", "FREQ=MONTHLY;BYMONTHDAY=31", date(2023, 1, 31), date(2023, 2, 28), true},
+		{"start day 31 clamps", "FREQ=MONTHLY", date(2024, 1, 31), date(2024, 6, 30), true},
+		{"last day", "FREQ=MONTHLY;BYMONTHDAY=-1", date(2024, 1, 1), date(2024, 2, 29), true},
+		{"last day not 28th in leap year", "FREQ=MONTHLY;BYMONTHDAY=-1", date(2024, 1, 1), date(2024, 2, 28), false},
+		{"second to last day", "FREQ=MONTHLY;BYMONTHDAY=-2", date(2023, 1, 1), date(2023, 2, 27), true},
+		{"-31 skipped in short month", "FREQ=MONTHLY;BYMONTHDAY=-31", date(2024, 1, 1), date(2024, 4, 1), false},
+		{"-31 in long month", "FREQ=MONTHLY;BYMONTHDAY=-31", date(2024, 1, 1), date(2024, 3, 1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rrule)
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) error = %v", tt.rrule, err)
+			}
+			if got := rule.matches(tt.start, tt.date); got != tt.want {
+				t.Errorf("matches(%s, %s) = %v, want %v", tt.start.Format("2006-01-02"), tt.date.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleOccurrences(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=MONTHLY;BYMONTHDAY=31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rule.Occurrences(date(2024, 1, 31), date(2024, 1, 1), date(2024, 5, 31))
+	want := []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31), date(2024, 4, 30), date(2024, 5, 31)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Occurrences() = %v, want %v", got, want)
+	}
+}
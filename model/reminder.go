@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+const (
+	ReminderClockIn  = "clock_in"
+	ReminderClockOut = "clock_out"
+)
+
+// Reminder は送信済みの打刻リマインダー。同じ日に同じ種類のリマインダーを二度送らないために記録する
+type Reminder struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_reminders_user_date_type"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_reminders_user_date_type"`
+	Type      string    `json:"type" gorm:"not null;uniqueIndex:idx_reminders_user_date_type"`
+	CreatedAt time.Time `json:"created_at"`
+	User      User      `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+}
+
+// ReminderTarget はリマインダーの判定に使う、ユーザーの所定の勤務時間と当日の打刻状況
+type ReminderTarget struct {
+	UserID                  uint
+	ScheduledStartTime      string
+	ScheduledMinutes        int
+	ClockInReminderMinutes  int
+	ClockOutReminderMinutes int
+	ClockedIn               bool
+	OpenRecord              bool
+	// 半休の日は出勤が遅れる場合があるため出勤のリマインダーを送らない
+	HalfDayLeave bool
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	ReportingLineTypePrimary  = "primary"
+	ReportingLineTypeActing   = "acting"
+	ReportingLineTypeDelegate = "delegate"
+)
+
+type ReportingLine struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	ManagerID     uint       `json:"manager_id" gorm:"not null;index"`
+	Type          string     `json:"type" gorm:"not null;default:primary"`
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"not null"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	User          User       `json:"user" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+	Manager       User       `json:"manager" gorm:"foreignKey:ManagerID; constraint:OnDelete:CASCADE"`
+}
+
+type ReportingLineResponse struct {
+	ID            uint       `json:"id"`
+	UserID        uint       `json:"user_id"`
+	ManagerID     uint       `json:"manager_id"`
+	Type          string     `json:"type"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+type TeamMemberResponse struct {
+	User         UserResponse `json:"user"`
+	ManagerID    uint         `json:"manager_id"`
+	LineType     string       `json:"line_type"`
+	Depth        int          `json:"depth"`
+	Status       string       `json:"status"`
+	ClockInTime  *time.Time   `json:"clock_in_time"`
+	ClockOutTime *time.Time   `json:"clock_out_time"`
+}
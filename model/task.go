@@ -2,18 +2,107 @@ package model
 
 import "time"
 
+const (
+	TaskStatusTodo       = "todo"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+	TaskStatusBlocked    = "blocked"
+)
+
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityMedium = "medium"
+	TaskPriorityHigh   = "high"
+	TaskPriorityUrgent = "urgent"
+)
+
+// private は作成者と担当者のみ、department は同じ部署のメンバー全員に公開される
+const (
+	TaskVisibilityPrivate    = "private"
+	TaskVisibilityDepartment = "department"
+)
+
+// 現在のステータスから遷移できるステータス
+var TaskStatusTransitions = map[string][]string{
+	TaskStatusTodo:       {TaskStatusInProgress, TaskStatusBlocked, TaskStatusDone},
+	TaskStatusInProgress: {TaskStatusTodo, TaskStatusBlocked, TaskStatusDone},
+	TaskStatusBlocked:    {TaskStatusTodo, TaskStatusInProgress},
+	TaskStatusDone:       {TaskStatusInProgress},
+}
+
 type Task struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	User      User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
-	UserId    uint      `json:"user_id" gorm:"not null"`
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	Title          string          `json:"title" gorm:"not null"`
+	Description    string          `json:"description"`
+	Status         string          `json:"status" gorm:"not null;default:todo;index"`
+	Priority       string          `json:"priority" gorm:"not null;default:medium"`
+	DueDate        *time.Time      `json:"due_date" gorm:"index"`
+	EstimatedHours float64         `json:"estimated_hours"`
+	Visibility     string          `json:"visibility" gorm:"not null;default:private"`
+	Department     string          `json:"department" gorm:"index"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"index:idx_tasks_user_created,priority:2"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	User           User            `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId         uint            `json:"user_id" gorm:"not null;index:idx_tasks_user_created,priority:1"`
+	Assignee       *User           `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID; constraint:OnDelete:SET NULL"`
+	AssigneeID     *uint           `json:"assignee_id" gorm:"index"`
+	Project        *Project        `json:"project,omitempty" gorm:"foreignKey:ProjectID; constraint:OnDelete:SET NULL"`
+	ProjectID      *uint           `json:"project_id" gorm:"index"`
+	Recurrence     *TaskRecurrence `json:"-" gorm:"foreignKey:RecurrenceID; constraint:OnDelete:SET NULL"`
+	RecurrenceID   *uint           `json:"recurrence_id" gorm:"uniqueIndex:idx_tasks_occurrence"`
+	OccurrenceDate *time.Time      `json:"occurrence_date" gorm:"type:date;uniqueIndex:idx_tasks_occurrence"`
+	// 繰り返しの1回分だけを個別に編集した場合は true になり、シリーズの変更の対象外になる
+	Detached bool `json:"detached" gorm:"not null;default:false"`
 }
 
 type TaskResponse struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Title          string     `json:"title" gorm:"not null"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	Priority       string     `json:"priority"`
+	DueDate        *time.Time `json:"due_date"`
+	EstimatedHours float64    `json:"estimated_hours"`
+	Visibility     string     `json:"visibility"`
+	Department     string     `json:"department,omitempty"`
+	UserId         uint       `json:"user_id"`
+	AssigneeID     *uint      `json:"assignee_id"`
+	ProjectID      *uint      `json:"project_id"`
+	RecurrenceID   *uint      `json:"recurrence_id,omitempty"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	Detached       bool       `json:"detached,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// GET /tasks のクエリパラメータ
+type TaskFilter struct {
+	Status     string
+	Priority   string
+	AssigneeID *uint
+	ProjectID  *uint
+	DueFrom    *time.Time
+	DueTo      *time.Time
+	// From, To は作成日時の範囲（To を含まない）
+	From *time.Time
+	To   *time.Time
+	Sort string
+}
+
+// CanTransitionTo は現在のステータスから next へ遷移できるかを返す
+func (t Task) CanTransitionTo(next string) bool {
+	if t.Status == next {
+		return true
+	}
+	for _, s := range TaskStatusTransitions[t.Status] {
+		if s == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsShared は部署ボードに公開されたタスクかどうかを返す
+func (t Task) IsShared() bool {
+	return t.Visibility == TaskVisibilityDepartment
 }
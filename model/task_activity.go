@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+const (
+	TaskActivityCreated         = "created"
+	TaskActivityStatusChanged   = "status_changed"
+	TaskActivityAssigneeChanged = "assignee_changed"
+	TaskActivityDueDateChanged  = "due_date_changed"
+	TaskActivityCommentAdded    = "comment_added"
+	TaskActivityCommentEdited   = "comment_edited"
+	TaskActivityCommentDeleted  = "comment_deleted"
+)
+
+type TaskComment struct {
+	ID        uint                  `json:"id" gorm:"primaryKey"`
+	TaskID    uint                  `json:"task_id" gorm:"not null;index"`
+	AuthorID  uint                  `json:"author_id" gorm:"not null;index"`
+	Body      string                `json:"body" gorm:"not null"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Task      Task                  `json:"-" gorm:"foreignKey:TaskID; constraint:OnDelete:CASCADE"`
+	Author    User                  `json:"-" gorm:"foreignKey:AuthorID; constraint:OnDelete:CASCADE"`
+	Revisions []TaskCommentRevision `json:"-" gorm:"foreignKey:CommentID; constraint:OnDelete:CASCADE"`
+}
+
+// TaskCommentRevision はコメント編集前の本文
+type TaskCommentRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CommentID uint      `json:"comment_id" gorm:"not null;index"`
+	Body      string    `json:"body" gorm:"not null"`
+	EditedAt  time.Time `json:"edited_at" gorm:"not null"`
+}
+
+// TaskActivity はタスクの変更履歴。状態・担当者・期日の変更とコメント操作を自動で記録する
+type TaskActivity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"task_id" gorm:"not null;index"`
+	ActorID   uint      `json:"actor_id" gorm:"not null"`
+	Type      string    `json:"type" gorm:"not null"`
+	FromValue string    `json:"from_value"`
+	ToValue   string    `json:"to_value"`
+	CommentID *uint     `json:"comment_id"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	Task      Task      `json:"-" gorm:"foreignKey:TaskID; constraint:OnDelete:CASCADE"`
+	Actor     User      `json:"-" gorm:"foreignKey:ActorID; constraint:OnDelete:CASCADE"`
+}
+
+type TaskCommentRevisionResponse struct {
+	Body     string    `json:"body"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+type TaskCommentResponse struct {
+	ID         uint                          `json:"id"`
+	TaskID     uint                          `json:"task_id"`
+	AuthorID   uint                          `json:"author_id"`
+	AuthorName string                        `json:"author_name"`
+	Body       string                        `json:"body"`
+	Edited     bool                          `json:"edited"`
+	Revisions  []TaskCommentRevisionResponse `json:"revisions"`
+	CreatedAt  time.Time                     `json:"created_at"`
+	UpdatedAt  time.Time                     `json:"updated_at"`
+}
+
+type TaskActivityResponse struct {
+	ID        uint                 `json:"id"`
+	TaskID    uint                 `json:"task_id"`
+	Type      string               `json:"type"`
+	ActorID   uint                 `json:"actor_id"`
+	ActorName string               `json:"actor_name"`
+	From      string               `json:"from,omitempty"`
+	To        string               `json:"to,omitempty"`
+	Comment   *TaskCommentResponse `json:"comment,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+}
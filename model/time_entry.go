@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+const (
+	TimeEntrySourceTimer  = "timer"
+	TimeEntrySourceManual = "manual"
+)
+
+type TimeEntry struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index;uniqueIndex:idx_time_entries_running,where:ended_at IS NULL"`
+	TaskID    uint       `json:"task_id" gorm:"not null;index"`
+	StartedAt time.Time  `json:"started_at" gorm:"not null;index"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Source    string     `json:"source" gorm:"not null;default:timer"`
+	Billable  bool       `json:"billable" gorm:"not null;default:false"`
+	Note      string     `json:"note"`
+	// 記録した時点の請求ロールと単価。後から単価やロールが変わっても過去の作業の請求額は変わらない
+	BillingRole string    `json:"billing_role"`
+	HourlyRate  *float64  `json:"hourly_rate"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	User        User      `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+	Task        Task      `json:"-" gorm:"foreignKey:TaskID; constraint:OnDelete:CASCADE"`
+}
+
+type TimeEntryResponse struct {
+	ID              uint       `json:"id"`
+	TaskID          uint       `json:"task_id"`
+	TaskTitle       string     `json:"task_title"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Source          string     `json:"source"`
+	Billable        bool       `json:"billable"`
+	Note            string     `json:"note"`
+}
+
+type TimeEntryListResponse struct {
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	TotalMinutes int                 `json:"total_minutes"`
+	Entries      []TimeEntryResponse `json:"entries"`
+}
+
+// DurationMinutes は計測中の場合 now までの経過分数を返す
+func (te TimeEntry) DurationMinutes(now time.Time) int {
+	end := now
+	if te.EndedAt != nil {
+		end = *te.EndedAt
+	}
+	if end.Before(te.StartedAt) {
+		return 0
+	}
+	return int(end.Sub(te.StartedAt).Minutes())
+}
@@ -1,25 +1,97 @@
 package model
 
 import (
+	"sort"
 	"time"
 )
 
 type AttendanceRecord struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	UserID       uint      `json:"user_id" gorm:"not null"`
-	ClockInTime  time.Time `json:"clock_in_time"`
-	ClockOutTime time.Time `json:"clock_out_time"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	User         User      `json:"user" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+	ID           uint              `json:"id" gorm:"primaryKey"`
+	UserID       uint              `json:"user_id" gorm:"not null;index:idx_attendance_records_user_clock_in,priority:1"`
+	ClockInTime  time.Time         `json:"clock_in_time" gorm:"index:idx_attendance_records_user_clock_in,priority:2;index"`
+	ClockOutTime time.Time         `json:"clock_out_time"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	User         User              `json:"user" gorm:"foreignKey:UserID; constraint:OnDelete:RESTRICT"`
+	Breaks       []AttendanceBreak `json:"breaks,omitempty" gorm:"foreignKey:AttendanceRecordID; constraint:OnDelete:CASCADE"`
+}
+
+// AttendanceBreak は勤怠1日分の中の休憩。休憩中は EndedAt が nil
+type AttendanceBreak struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	AttendanceRecordID uint       `json:"attendance_record_id" gorm:"not null;index"`
+	StartedAt          time.Time  `json:"started_at" gorm:"not null"`
+	EndedAt            *time.Time `json:"ended_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type AttendanceBreakResponse struct {
+	ID        uint       `json:"id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
 }
 
 type AttendanceRecordResponse struct {
-	ID           uint         `json:"id"`
-	UserID       uint         `json:"user_id"`
-	ClockInTime  time.Time    `json:"clock_in_time"`
-	ClockOutTime time.Time    `json:"clock_out_time"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
-	User         UserResponse `json:"user"`
+	ID              uint                      `json:"id"`
+	UserID          uint                      `json:"user_id"`
+	ClockInTime     time.Time                 `json:"clock_in_time"`
+	ClockOutTime    time.Time                 `json:"clock_out_time"`
+	WorkedMinutes   int                       `json:"worked_minutes"`
+	OvertimeMinutes int                       `json:"overtime_minutes"`
+	CreatedAt       time.Time                 `json:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at"`
+	User            UserResponse              `json:"user"`
+	Breaks          []AttendanceBreakResponse `json:"breaks"`
+}
+
+// WorkedMinutes は退勤済みの場合に出勤から退勤までの時間から休憩を除いた実働の分数を返す。
+// 休憩は Breaks を読み込んだ記録でのみ差し引かれる
+func (r AttendanceRecord) WorkedMinutes() int {
+	if r.ClockOutTime.IsZero() || r.ClockOutTime.Before(r.ClockInTime) {
+		return 0
+	}
+	worked := r.ClockOutTime.Sub(r.ClockInTime) - r.breakDuration()
+	if worked < 0 {
+		return 0
+	}
+	return int(worked.Minutes())
+}
+
+// breakDuration は終了した休憩の合計時間
+func (r AttendanceRecord) breakDuration() time.Duration {
+	var total time.Duration
+	for _, b := range r.Breaks {
+		if b.EndedAt != nil && b.EndedAt.After(b.StartedAt) {
+			total += b.EndedAt.Sub(b.StartedAt)
+		}
+	}
+	return total
+}
+
+// OpenBreak は終了していない休憩を返す。休憩中でなければ nil
+func (r AttendanceRecord) OpenBreak() *AttendanceBreak {
+	for i := range r.Breaks {
+		if r.Breaks[i].EndedAt == nil {
+			return &r.Breaks[i]
+		}
+	}
+	return nil
 }
+
+// BreakResponses は休憩を開始時刻の順にレスポンスへ変換する
+func (r AttendanceRecord) BreakResponses() []AttendanceBreakResponse {
+	res := make([]AttendanceBreakResponse, len(r.Breaks))
+	for i, b := range r.Breaks {
+		res[i] = AttendanceBreakResponse{ID: b.ID, StartedAt: b.StartedAt, EndedAt: b.EndedAt}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].StartedAt.Before(res[j].StartedAt) })
+	return res
+}
+
+const (
+	AttendanceStatusNotClockedIn = "not_clocked_in"
+	AttendanceStatusWorking      = "working"
+	AttendanceStatusOnBreak      = "on_break"
+	AttendanceStatusClockedOut   = "clocked_out"
+)
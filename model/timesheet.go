@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+const (
+	TimesheetStatusSubmitted = "submitted"
+	TimesheetStatusApproved  = "approved"
+	TimesheetStatusRejected  = "rejected"
+)
+
+// Timesheet は1週間（月曜始まり）分のプロジェクト作業時間の提出・承認状況。勤怠の締めとは別に扱う
+type Timesheet struct {
+	ID           uint                `json:"id" gorm:"primaryKey"`
+	UserID       uint                `json:"user_id" gorm:"not null;uniqueIndex:idx_timesheets_user_week"`
+	WeekStart    time.Time           `json:"week_start" gorm:"type:date;not null;uniqueIndex:idx_timesheets_user_week"`
+	Status       string              `json:"status" gorm:"not null"`
+	TotalMinutes int                 `json:"total_minutes"`
+	SubmittedAt  time.Time           `json:"submitted_at"`
+	ReviewedBy   *uint               `json:"reviewed_by"`
+	ReviewedAt   *time.Time          `json:"reviewed_at"`
+	Comment      string              `json:"comment"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	User         User                `json:"-" gorm:"foreignKey:UserID; constraint:OnDelete:CASCADE"`
+	Reviewer     *User               `json:"-" gorm:"foreignKey:ReviewedBy; constraint:OnDelete:SET NULL"`
+	Approvals    []TimesheetApproval `json:"-" gorm:"foreignKey:TimesheetID"`
+}
+
+// TimesheetApproval はプロジェクトリーダーごとの承認。週に作業時間のある全プロジェクトのリーダーが
+// 承認したときにタイムシートが承認済みになる。差し戻し・再提出で消える
+type TimesheetApproval struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TimesheetID uint      `json:"timesheet_id" gorm:"not null;uniqueIndex:idx_timesheet_approvals_lead"`
+	LeadID      uint      `json:"lead_id" gorm:"not null;uniqueIndex:idx_timesheet_approvals_lead"`
+	Comment     string    `json:"comment"`
+	CreatedAt   time.Time `json:"created_at"`
+	Timesheet   Timesheet `json:"-" gorm:"foreignKey:TimesheetID; constraint:OnDelete:CASCADE"`
+	Lead        User      `json:"-" gorm:"foreignKey:LeadID; constraint:OnDelete:CASCADE"`
+}
+
+type TimesheetResponse struct {
+	ID           uint                `json:"id"`
+	UserID       uint                `json:"user_id"`
+	UserName     string              `json:"user_name,omitempty"`
+	WeekStart    time.Time           `json:"week_start"`
+	Status       string              `json:"status"`
+	TotalMinutes int                 `json:"total_minutes"`
+	SubmittedAt  time.Time           `json:"submitted_at"`
+	ReviewedBy   *uint               `json:"reviewed_by"`
+	ReviewedAt   *time.Time          `json:"reviewed_at"`
+	Comment      string              `json:"comment"`
+	ApprovedBy   []uint              `json:"approved_by,omitempty"`
+	Entries      []TimeEntryResponse `json:"entries,omitempty"`
+}
+
+// IsLocked は提出中・承認済みで作業時間を変更できない状態かどうかを返す
+func (ts Timesheet) IsLocked() bool {
+	return ts.Status == TimesheetStatusSubmitted || ts.Status == TimesheetStatusApproved
+}
@@ -1,22 +1,79 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleMember  = "member"
+)
 
 type User struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	Email      string    `json:"email" `
-	Password   string    `json:"password"`
-	Department string    `json:"department"`
-	Name       string    `json:"name"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID               uint            `json:"id" gorm:"primaryKey"`
+	Email            string          `json:"email" `
+	Password         string          `json:"password"`
+	Department       string          `json:"department" gorm:"index"`
+	Name             string          `json:"name"`
+	Role             string          `json:"role" gorm:"not null;default:member"`
+	ExternalID       string          `json:"external_id" gorm:"index"`
+	HireDate         *time.Time      `json:"hire_date"`
+	TerminationDate  *time.Time      `json:"termination_date"`
+	DeactivatedAt    *time.Time      `json:"deactivated_at"`
+	ErasedAt         *time.Time      `json:"erased_at"`
+	EmploymentTypeID *uint           `json:"employment_type_id"`
+	EmploymentType   *EmploymentType `json:"employment_type,omitempty" gorm:"foreignKey:EmploymentTypeID; constraint:OnDelete:SET NULL"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"index"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt  `json:"-" gorm:"index"`
 }
 
 type UserResponse struct {
-	ID         uint   `json:"id" gorm:"primaryKey"`
-	Email      string `json:"email" `
-	Department string `json:"department"`
-	Name       string `json:"name"`
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Email           string     `json:"email" `
+	Department      string     `json:"department"`
+	Name            string     `json:"name"`
+	Role            string     `json:"role,omitempty"`
+	HireDate        *time.Time `json:"hire_date,omitempty"`
+	TerminationDate *time.Time `json:"termination_date,omitempty"`
+	EmploymentType  string     `json:"employment_type,omitempty"`
+	IsActive        bool       `json:"is_active"`
+}
+
+// EmploymentTypeCode は雇用区分が読み込まれていればそのコードを返す
+func (u User) EmploymentTypeCode() string {
+	if u.EmploymentType == nil {
+		return ""
+	}
+	return u.EmploymentType.Code
+}
+
+// CanViewDepartment は部署ボードを閲覧・更新できるかを返す。管理者は全部署を扱える
+func (u User) CanViewDepartment(department string) bool {
+	return u.Role == RoleAdmin || (department != "" && u.Department == department)
+}
+
+// CanManageDepartment は部署ボードのタスクを削除するなどの管理操作ができるかを返す
+func (u User) CanManageDepartment(department string) bool {
+	return u.Role == RoleAdmin || (u.Role == RoleManager && department != "" && u.Department == department)
+}
+
+// IsActive は無効化されておらず、退職日を過ぎていない場合に true を返す。退職日は当日の終わりまで在籍とする
+func (u User) IsActive(now time.Time) bool {
+	if u.DeactivatedAt != nil {
+		return false
+	}
+	if u.TerminationDate != nil {
+		t := u.TerminationDate
+		lastDayEnd := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+		if !now.Before(lastDayEnd) {
+			return false
+		}
+	}
+	return true
 }
 
 // type User struct {
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// WebhookEvents は外部に配信できるドメインイベントの種別
+var WebhookEvents = []string{
+	EventAttendanceClockIn,
+	EventAttendanceClockOut,
+	EventAttendanceBreakStart,
+	EventAttendanceBreakEnd,
+	EventAttendanceRecordCorrected,
+	EventPeriodLocked,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription は管理者が登録した外部システムの送信先。Events はカンマ区切りのイベント種別
+type WebhookSubscription struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	URL         string    `json:"url" gorm:"not null"`
+	Description string    `json:"description"`
+	Events      string    `json:"events" gorm:"not null"`
+	Secret      string    `json:"-" gorm:"not null"`
+	Active      bool      `json:"active" gorm:"not null;default:true"`
+	CreatedBy   *uint     `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Creator     *User     `json:"-" gorm:"foreignKey:CreatedBy; constraint:OnDelete:SET NULL"`
+}
+
+// EventList は購読しているイベント種別を返す
+func (s WebhookSubscription) EventList() []string {
+	events := []string{}
+	for _, v := range strings.Split(s.Events, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			events = append(events, v)
+		}
+	}
+	return events
+}
+
+// Subscribes は有効な購読で、指定のイベントを購読していれば true を返す
+func (s WebhookSubscription) Subscribes(event string) bool {
+	if !s.Active {
+		return false
+	}
+	for _, v := range s.EventList() {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery は1件の送信と、その再試行の状況を記録する配信ログ。
+// 同じイベントが再処理されても送信先ごとに1件しか作らない（手動の再送は OutboxEventID を持たない）
+type WebhookDelivery struct {
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint                `json:"subscription_id" gorm:"not null;index;uniqueIndex:idx_webhook_deliveries_event"`
+	OutboxEventID  *uint               `json:"outbox_event_id" gorm:"uniqueIndex:idx_webhook_deliveries_event"`
+	Event          string              `json:"event" gorm:"not null"`
+	Payload        string              `json:"payload" gorm:"type:text;not null"`
+	Status         string              `json:"status" gorm:"not null;default:pending;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int                 `json:"attempts"`
+	NextAttemptAt  time.Time           `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	LastStatusCode int                 `json:"last_status_code"`
+	LastError      string              `json:"last_error"`
+	DeliveredAt    *time.Time          `json:"delivered_at"`
+	RedeliveryOf   *uint               `json:"redelivery_of"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+	Subscription   WebhookSubscription `json:"-" gorm:"foreignKey:SubscriptionID; constraint:OnDelete:CASCADE"`
+}
+
+type WebhookSubscriptionResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedBy   *uint     `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookPayload は送信する本文の共通形式。Data はドメインイベントの内容をそのまま入れる
+type WebhookPayload struct {
+	EventID    uint            `json:"event_id"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
@@ -0,0 +1,76 @@
+package presence
+
+import (
+	"go-rest-api/model"
+	"sync"
+)
+
+type IHub interface {
+	Publish(event model.PresenceEvent)
+	Subscribe(department string) (<-chan model.PresenceEvent, func())
+	Close()
+}
+
+type subscriber struct {
+	department string
+	ch         chan model.PresenceEvent
+}
+
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+// NewHub は在席状況の変化を購読者に配信するハブを作る。プロセス内でのみ共有される
+func NewHub() IHub {
+	return &hub{subscribers: map[*subscriber]struct{}{}}
+}
+
+// Publish は部署が一致する（または部署を指定していない）購読者に配信する。
+// 受信が追いつかない購読者への配信は読み飛ばし、出勤・退勤の処理を待たせない
+func (h *hub) Publish(event model.PresenceEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subscribers {
+		if s.department != "" && s.department != event.Department {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+		}
+	}
+}
+
+// Close は全ての購読者のチャネルを閉じ、以降の購読もすぐに閉じる。
+// サーバーの停止時に接続を維持したままのストリームを終わらせるために使う
+func (h *hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subscribers {
+		close(s.ch)
+		delete(h.subscribers, s)
+	}
+}
+
+// Subscribe は購読を開始し、受信チャネルと購読解除の関数を返す。ハブが閉じられるとチャネルも閉じられる
+func (h *hub) Subscribe(department string) (<-chan model.PresenceEvent, func()) {
+	s := &subscriber{department: department, ch: make(chan model.PresenceEvent, 32)}
+	h.mu.Lock()
+	if h.closed {
+		close(s.ch)
+	} else {
+		h.subscribers[s] = struct{}{}
+	}
+	h.mu.Unlock()
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, s)
+			h.mu.Unlock()
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type IAttendanceExceptionRepository interface {
+	GetExceptions(exceptions *[]model.AttendanceException, status string, department string) error
+	GetExceptionById(exception *model.AttendanceException, exceptionId uint) error
+	ResolveException(exception *model.AttendanceException, exceptionId uint) error
+	DetectAnomalies(exceptions *[]model.AttendanceException, rules model.AnomalyRules) error
+	GetHolidays(holidays *[]model.Holiday) error
+	CreateHoliday(holiday *model.Holiday) error
+	DeleteHoliday(holidayId uint) error
+}
+
+type attendanceExceptionRepository struct {
+	db *gorm.DB
+}
+
+func NewAttendanceExceptionRepository(db *gorm.DB) IAttendanceExceptionRepository {
+	return &attendanceExceptionRepository{db}
+}
+
+// detectAnomaliesQuery は期間内（since 以上 until 未満）の出勤記録を検査し、未登録の異常を例外キューへ追加する。
+// 退勤の打刻がない記録はゼロ値（clock_in_time より前）で保存されている。休日の打刻は休日出勤の申請が承認された日を除く
+const detectAnomaliesQuery = `
+INSERT INTO attendance_exceptions (user_id, attendance_record_id, type, date, detail, status, created_at, updated_at)
+SELECT user_id, id, type, day, detail, 'open', NOW(), NOW() FROM (
+	SELECT r.user_id, r.id, 'missing_clock_out' AS type, r.clock_in_time::date AS day,
+		'no clock-out after clock-in at ' || TO_CHAR(r.clock_in_time, 'HH24:MI') AS detail
+	FROM records r
+	WHERE r.clock_out_time < r.clock_in_time
+	UNION ALL
+	SELECT r.user_id, r.id, 'overlapping', r.clock_in_time::date,
+		'overlaps record #' || MIN(o.id)
+	FROM records r
+	JOIN attendance_records o ON o.user_id = r.user_id AND o.id <> r.id
+		AND (o.clock_in_time, o.id) < (r.clock_in_time, r.id)
+		AND o.clock_out_time > r.clock_in_time
+	GROUP BY r.user_id, r.id, r.clock_in_time
+	UNION ALL
+	SELECT r.user_id, r.id, 'long_shift', r.clock_in_time::date,
+		'shift of ' || r.worked || ' minutes'
+	FROM records r
+	WHERE r.worked > @max_shift
+	UNION ALL
+	SELECT r.user_id, r.id, 'holiday_punch', r.clock_in_time::date,
+		'punched on holiday: ' || h.name
+	FROM records r
+	JOIN holidays h ON h.date = r.clock_in_time::date
+	WHERE NOT EXISTS (SELECT 1 FROM holiday_work_requests w
+		WHERE w.user_id = r.user_id AND w.date = r.clock_in_time::date AND w.status = 'approved')
+	UNION ALL
+	SELECT r.user_id, r.id, 'schedule_gap', r.clock_in_time::date,
+		CONCAT_WS('; ',
+			CASE WHEN ABS(r.start_gap) > @gap THEN 'clock-in differs from scheduled start by ' || ROUND(r.start_gap) || ' minutes' END,
+			CASE WHEN r.scheduled > 0 AND r.worked > 0 AND ABS(r.worked - r.scheduled) > @gap
+				THEN 'worked ' || r.worked || ' minutes against ' || r.scheduled || ' scheduled' END)
+	FROM records r
+	WHERE NOT EXISTS (SELECT 1 FROM holidays h WHERE h.date = r.clock_in_time::date)
+	AND (ABS(r.start_gap) > @gap OR (r.scheduled > 0 AND r.worked > 0 AND ABS(r.worked - r.scheduled) > @gap))
+) AS anomalies
+ON CONFLICT (attendance_record_id, type) DO NOTHING
+RETURNING *`
+
+const anomalyRecordsQuery = `
+WITH records AS (
+	SELECT r.id, r.user_id, r.clock_in_time, r.clock_out_time,
+		` + workedMinutesSQL + ` AS worked,
+		COALESCE(et.scheduled_minutes_per_day, 0) AS scheduled,
+		EXTRACT(EPOCH FROM r.clock_in_time::time - COALESCE(NULLIF(et.scheduled_start_time, ''), @start)::time) / 60 AS start_gap
+	FROM attendance_records r
+	JOIN users u ON u.id = r.user_id
+	LEFT JOIN employment_types et ON et.id = u.employment_type_id
+	WHERE r.clock_in_time >= @since AND r.clock_in_time < @until AND u.deleted_at IS NULL
+)`
+
+func (aer *attendanceExceptionRepository) GetExceptions(exceptions *[]model.AttendanceException, status string, department string) error {
+	query := aer.db.Preload("User").Joins("JOIN users ON users.id = attendance_exceptions.user_id")
+	if status != "" {
+		query = query.Where("attendance_exceptions.status = ?", status)
+	}
+	if department != "" {
+		query = query.Where("users.department = ?", department)
+	}
+	if err := query.Order("attendance_exceptions.date DESC, attendance_exceptions.id").Find(exceptions).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (aer *attendanceExceptionRepository) GetExceptionById(exception *model.AttendanceException, exceptionId uint) error {
+	if err := aer.db.Preload("User").First(exception, exceptionId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ResolveException は未解決の例外のみ解決済みにする
+func (aer *attendanceExceptionRepository) ResolveException(exception *model.AttendanceException, exceptionId uint) error {
+	result := aer.db.Model(&model.AttendanceException{}).
+		Where("id = ? AND status = ?", exceptionId, model.ExceptionStatusOpen).
+		Updates(map[string]interface{}{
+			"status":          model.ExceptionStatusResolved,
+			"resolved_by":     exception.ResolvedBy,
+			"resolved_at":     exception.ResolvedAt,
+			"resolution_note": exception.ResolutionNote,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.NewConflictError("exception_resolved", "exception is already resolved")
+	}
+	return aer.GetExceptionById(exception, exceptionId)
+}
+
+// DetectAnomalies は新たに検知した例外を返す。検知済みの記録と種類の組み合わせは再登録しない
+func (aer *attendanceExceptionRepository) DetectAnomalies(exceptions *[]model.AttendanceException, rules model.AnomalyRules) error {
+	err := aer.db.Raw(anomalyRecordsQuery+detectAnomaliesQuery, map[string]interface{}{
+		"since":     rules.Since,
+		"until":     rules.Until,
+		"max_shift": rules.MaxShiftMinutes,
+		"gap":       rules.ScheduleGapMinutes,
+		"start":     model.DefaultScheduledStartTime,
+	}).Scan(exceptions).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (aer *attendanceExceptionRepository) GetHolidays(holidays *[]model.Holiday) error {
+	if err := aer.db.Order("date").Find(holidays).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (aer *attendanceExceptionRepository) CreateHoliday(holiday *model.Holiday) error {
+	if err := aer.db.Create(holiday).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (aer *attendanceExceptionRepository) DeleteHoliday(holidayId uint) error {
+	result := aer.db.Delete(&model.Holiday{}, holidayId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package repository
 
 import (
-	"errors"
 	"fmt"
 	"go-rest-api/model"
 
@@ -35,7 +34,7 @@ func (aur *authUserRepository) CreateAuthUser(authUser *model.AuthUser) error {
 	aur.db.Model(&model.AuthUser{}).Where("email = ?", authUser.Email).Count(&count)
 
 	if count > 0 {
-		return errors.New("このメールアドレスは既に使用されています")
+		return model.ErrEmailTaken
 	}
 
 	if err := aur.db.Create(authUser).Error; err != nil {
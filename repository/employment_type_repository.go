@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type IEmploymentTypeRepository interface {
+	GetAllEmploymentTypes(types *[]model.EmploymentType) error
+	GetEmploymentTypeById(employmentType *model.EmploymentType, typeId uint) error
+	GetEmploymentTypeByCode(employmentType *model.EmploymentType, code string) error
+	CreateEmploymentType(employmentType *model.EmploymentType) error
+	UpdateEmploymentType(employmentType *model.EmploymentType, typeId uint) error
+	DeleteEmploymentType(typeId uint) error
+}
+
+type employmentTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmploymentTypeRepository(db *gorm.DB) IEmploymentTypeRepository {
+	return &employmentTypeRepository{db}
+}
+
+func (etr *employmentTypeRepository) GetAllEmploymentTypes(types *[]model.EmploymentType) error {
+	if err := etr.db.Preload("LeaveGrants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("service_months")
+	}).Order("id").Find(types).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (etr *employmentTypeRepository) GetEmploymentTypeById(employmentType *model.EmploymentType, typeId uint) error {
+	if err := etr.db.Preload("LeaveGrants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("service_months")
+	}).First(employmentType, typeId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (etr *employmentTypeRepository) GetEmploymentTypeByCode(employmentType *model.EmploymentType, code string) error {
+	if err := etr.db.Where("code = ?", code).First(employmentType).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (etr *employmentTypeRepository) CreateEmploymentType(employmentType *model.EmploymentType) error {
+	if err := etr.db.Create(employmentType).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (etr *employmentTypeRepository) UpdateEmploymentType(employmentType *model.EmploymentType, typeId uint) error {
+	// 付与テーブルは差分ではなく丸ごと置き換える
+	return etr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.EmploymentType{}).Where("id = ?", typeId).
+			Select("code", "name", "scheduled_minutes_per_day", "scheduled_start_time", "overtime_threshold_minutes", "weekly_overtime_threshold").
+			Updates(employmentType)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return model.ErrNotFound
+		}
+		if err := tx.Where("employment_type_id = ?", typeId).Delete(&model.EmploymentTypeLeaveGrant{}).Error; err != nil {
+			return err
+		}
+		for i := range employmentType.LeaveGrants {
+			employmentType.LeaveGrants[i].ID = 0
+			employmentType.LeaveGrants[i].EmploymentTypeID = typeId
+		}
+		if len(employmentType.LeaveGrants) > 0 {
+			if err := tx.Create(&employmentType.LeaveGrants).Error; err != nil {
+				return err
+			}
+		}
+		employmentType.ID = typeId
+		return nil
+	})
+}
+
+func (etr *employmentTypeRepository) DeleteEmploymentType(typeId uint) error {
+	result := etr.db.Where("id = ?", typeId).Delete(&model.EmploymentType{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IHolidayWorkRequestRepository interface {
+	GetRequestsByUser(requests *[]model.HolidayWorkRequest, userId uint) error
+	GetRequests(requests *[]model.HolidayWorkRequest, status string, department string) error
+	GetRequestById(request *model.HolidayWorkRequest, requestId uint) error
+	IsHoliday(date time.Time) (bool, error)
+	CreateRequest(request *model.HolidayWorkRequest) error
+	ReviewRequest(request *model.HolidayWorkRequest, requestId uint) error
+	DeletePendingRequest(userId uint, requestId uint) error
+}
+
+type holidayWorkRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewHolidayWorkRequestRepository(db *gorm.DB) IHolidayWorkRequestRepository {
+	return &holidayWorkRequestRepository{db}
+}
+
+func (hr *holidayWorkRequestRepository) GetRequestsByUser(requests *[]model.HolidayWorkRequest, userId uint) error {
+	if err := hr.db.Preload("User").Where("user_id = ?", userId).Order("date DESC").Find(requests).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (hr *holidayWorkRequestRepository) GetRequests(requests *[]model.HolidayWorkRequest, status string, department string) error {
+	query := hr.db.Preload("User").Joins("JOIN users ON users.id = holiday_work_requests.user_id")
+	if status != "" {
+		query = query.Where("holiday_work_requests.status = ?", status)
+	}
+	if department != "" {
+		query = query.Where("users.department = ?", department)
+	}
+	if err := query.Order("holiday_work_requests.date DESC, holiday_work_requests.id").Find(requests).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (hr *holidayWorkRequestRepository) GetRequestById(request *model.HolidayWorkRequest, requestId uint) error {
+	if err := hr.db.Preload("User").First(request, requestId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (hr *holidayWorkRequestRepository) IsHoliday(date time.Time) (bool, error) {
+	var count int64
+	if err := hr.db.Model(&model.Holiday{}).Where("date = ?", date.Format("2006-01-02")).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (hr *holidayWorkRequestRepository) CreateRequest(request *model.HolidayWorkRequest) error {
+	if err := hr.db.Create(request).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReviewRequest は未審査の申請のみ承認・却下する。request には申請者と日付を読み込んでおく。承認した場合は、その日の休日出勤として
+// すでに検知されている未解決の例外も同じトランザクションで解決済みにする
+func (hr *holidayWorkRequestRepository) ReviewRequest(request *model.HolidayWorkRequest, requestId uint) error {
+	return hr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.HolidayWorkRequest{}).
+			Where("id = ? AND status = ?", requestId, model.HolidayWorkPending).
+			Updates(map[string]interface{}{
+				"status":      request.Status,
+				"reviewed_by": request.ReviewedBy,
+				"reviewed_at": request.ReviewedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return model.NewConflictError("request_reviewed", "holiday work request is already reviewed")
+		}
+		if request.Status != model.HolidayWorkApproved {
+			return nil
+		}
+		return tx.Model(&model.AttendanceException{}).
+			Where("user_id = ? AND date = ? AND type = ? AND status = ?",
+				request.UserID, request.Date.Format("2006-01-02"), model.AnomalyHolidayPunch, model.ExceptionStatusOpen).
+			Updates(map[string]interface{}{
+				"status":          model.ExceptionStatusResolved,
+				"resolved_by":     request.ReviewedBy,
+				"resolved_at":     request.ReviewedAt,
+				"resolution_note": "holiday work approved",
+			}).Error
+	})
+}
+
+// DeletePendingRequest は本人の未審査の申請だけを取り下げる
+func (hr *holidayWorkRequestRepository) DeletePendingRequest(userId uint, requestId uint) error {
+	result := hr.db.Where("id = ? AND user_id = ? AND status = ?", requestId, userId, model.HolidayWorkPending).
+		Delete(&model.HolidayWorkRequest{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IInvitationRepository interface {
+	GetUnacceptedInvitations(invitations *[]model.Invitation) error
+	GetInvitationById(invitation *model.Invitation, invitationId uint) error
+	GetInvitationsByUser(invitations *[]model.Invitation, userId uint, email string) error
+	GetInvitationByTokenHash(invitation *model.Invitation, tokenHash string) error
+	CountPendingByEmail(email string, now time.Time) (int64, error)
+	CreateInvitation(invitation *model.Invitation) error
+	UpdateToken(invitationId uint, tokenHash string, expiresAt time.Time) error
+	DeleteInvitation(invitationId uint) error
+	AcceptInvitation(invitation *model.Invitation, user *model.User, acceptedAt time.Time) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) IInvitationRepository {
+	return &invitationRepository{db}
+}
+
+func (ir *invitationRepository) GetUnacceptedInvitations(invitations *[]model.Invitation) error {
+	if err := ir.db.Where("accepted_at IS NULL").Order("created_at").Find(invitations).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *invitationRepository) GetInvitationById(invitation *model.Invitation, invitationId uint) error {
+	if err := ir.db.First(invitation, invitationId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *invitationRepository) GetInvitationsByUser(invitations *[]model.Invitation, userId uint, email string) error {
+	if err := ir.db.Where("user_id = ? OR email = ?", userId, email).Order("created_at").Find(invitations).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *invitationRepository) GetInvitationByTokenHash(invitation *model.Invitation, tokenHash string) error {
+	if err := ir.db.Where("token_hash = ?", tokenHash).First(invitation).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *invitationRepository) CountPendingByEmail(email string, now time.Time) (int64, error) {
+	var count int64
+	err := ir.db.Model(&model.Invitation{}).
+		Where("email = ? AND accepted_at IS NULL AND expires_at > ?", email, now).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (ir *invitationRepository) CreateInvitation(invitation *model.Invitation) error {
+	if err := ir.db.Create(invitation).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *invitationRepository) UpdateToken(invitationId uint, tokenHash string, expiresAt time.Time) error {
+	result := ir.db.Model(&model.Invitation{}).Where("id = ? AND accepted_at IS NULL", invitationId).
+		Updates(map[string]interface{}{"token_hash": tokenHash, "expires_at": expiresAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ir *invitationRepository) DeleteInvitation(invitationId uint) error {
+	// 受諾済みの招待は履歴として残す
+	result := ir.db.Where("id = ? AND accepted_at IS NULL", invitationId).Delete(&model.Invitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ir *invitationRepository) AcceptInvitation(invitation *model.Invitation, user *model.User, acceptedAt time.Time) error {
+	return ir.db.Transaction(func(tx *gorm.DB) error {
+		// accepted_at IS NULL を条件にすることで同じリンクの二重使用を防ぐ
+		result := tx.Model(&model.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", acceptedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return model.NewConflictError("invitation_used", "invitation has already been used")
+		}
+		// SCIM などで作成済みのユーザーにはパスワードのみ設定する
+		if invitation.UserID != nil {
+			result := tx.Model(&model.User{}).Where("id = ?", *invitation.UserID).Update("password", user.Password)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected < 1 {
+				return model.ErrNotFound
+			}
+			user.ID = *invitation.UserID
+			return nil
+		}
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Invitation{}).Where("id = ?", invitation.ID).Update("user_id", user.ID).Error
+	})
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"go-rest-api/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IJobRepository interface {
+	GetJobs(jobs *[]model.Job) error
+	GetJobByName(job *model.Job, name string) error
+	RegisterJob(job *model.Job) error
+	UpdateJobState(job *model.Job) error
+	SetEnabled(name string, enabled bool) error
+	SetNextRun(name string, nextRunAt time.Time) error
+	GetRuns(runs *[]model.JobRun, name string, limit int) error
+	StartRun(run *model.JobRun) error
+	FinishRun(run *model.JobRun) error
+	TryLock(name string) (func(), bool, error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) IJobRepository {
+	return &jobRepository{db}
+}
+
+func (jr *jobRepository) GetJobs(jobs *[]model.Job) error {
+	if err := jr.db.Order("name").Find(jobs).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (jr *jobRepository) GetJobByName(job *model.Job, name string) error {
+	if err := jr.db.Where("name = ?", name).First(job).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RegisterJob は未登録なら作成する。スケジュールが変わった場合のみ次回の実行時刻を計算し直し、
+// それ以外は保存済みの状態（次回の実行時刻や有効・無効）を引き継ぐ
+func (jr *jobRepository) RegisterJob(job *model.Job) error {
+	err := jr.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"schedule":    job.Schedule,
+			"next_run_at": job.NextRunAt,
+			"updated_at":  time.Now(),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Neq{Column: clause.Column{Table: "jobs", Name: "schedule"}, Value: job.Schedule},
+		}},
+	}).Create(job).Error
+	if err != nil {
+		return err
+	}
+	return jr.GetJobByName(job, job.Name)
+}
+
+func (jr *jobRepository) UpdateJobState(job *model.Job) error {
+	err := jr.db.Model(job).
+		Select("next_run_at", "last_run_at", "last_status", "last_error").
+		Updates(job).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (jr *jobRepository) SetEnabled(name string, enabled bool) error {
+	result := jr.db.Model(&model.Job{}).Where("name = ?", name).Update("enabled", enabled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (jr *jobRepository) SetNextRun(name string, nextRunAt time.Time) error {
+	result := jr.db.Model(&model.Job{}).Where("name = ?", name).Update("next_run_at", nextRunAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (jr *jobRepository) GetRuns(runs *[]model.JobRun, name string, limit int) error {
+	if err := jr.db.Where("job_name = ?", name).Order("id DESC").Limit(limit).Find(runs).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// StartRun は実行履歴を作成する。ロックを取得してから呼ぶため、実行中のまま残っている履歴は
+// 停止したプロセスのものとして中断扱いにする
+func (jr *jobRepository) StartRun(run *model.JobRun) error {
+	return jr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.JobRun{}).
+			Where("job_name = ? AND status = ?", run.JobName, model.JobRunRunning).
+			Updates(map[string]interface{}{"status": model.JobRunFailed, "error": "interrupted", "finished_at": run.StartedAt}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(run).Error
+	})
+}
+
+func (jr *jobRepository) FinishRun(run *model.JobRun) error {
+	if err := jr.db.Model(run).Select("status", "error", "finished_at").Updates(run).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// TryLock は Postgres のアドバイザリロックでジョブの実行権を取得する。ロックはセッション単位のため
+// 専用の接続を確保し、返した解放関数で解放して接続を戻す。他のレプリカが保持中なら false を返す
+func (jr *jobRepository) TryLock(name string) (func(), bool, error) {
+	sqlDB, err := jr.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	key := "job:" + name
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+	unlock := func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", key); err != nil {
+			log.Printf("failed to release lock of job %s: %v", name, err)
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
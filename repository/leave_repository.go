@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ILeaveRepository interface {
+	GetLeaves(leaves *[]model.LeaveRecord, userId uint, from *time.Time, to *time.Time) error
+	SumLeaveDays(userId uint, from time.Time, to time.Time) (float64, error)
+	CreateLeave(leave *model.LeaveRecord) error
+	DeleteLeave(userId uint, leaveId uint) error
+}
+
+type leaveRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaveRepository(db *gorm.DB) ILeaveRepository {
+	return &leaveRepository{db}
+}
+
+func (lr *leaveRepository) GetLeaves(leaves *[]model.LeaveRecord, userId uint, from *time.Time, to *time.Time) error {
+	query := lr.db.Where("user_id = ?", userId)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	if err := query.Order("date").Find(leaves).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// SumLeaveDays は from 以上 to 未満の日付に取得した日数の合計を返す
+func (lr *leaveRepository) SumLeaveDays(userId uint, from time.Time, to time.Time) (float64, error) {
+	var days float64
+	err := lr.db.Model(&model.LeaveRecord{}).Select("COALESCE(SUM(days), 0)").
+		Where("user_id = ? AND date >= ? AND date < ?", userId, from, to).Scan(&days).Error
+	if err != nil {
+		return 0, err
+	}
+	return days, nil
+}
+
+func (lr *leaveRepository) CreateLeave(leave *model.LeaveRecord) error {
+	if err := lr.db.Create(leave).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (lr *leaveRepository) DeleteLeave(userId uint, leaveId uint) error {
+	result := lr.db.Where("id = ? AND user_id = ?", leaveId, userId).Delete(&model.LeaveRecord{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
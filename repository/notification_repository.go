@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type INotificationRepository interface {
+	GetNotifications(notifications *[]model.Notification, userId uint, unreadOnly bool) error
+	CountUnread(userId uint) (int64, error)
+	CreateNotification(notification *model.Notification) error
+	MarkRead(userId uint, notificationId uint, readAt time.Time) error
+	MarkAllRead(userId uint, readAt time.Time) error
+	GetSetting(setting *model.NotificationSetting, userId uint) error
+	GetPreferences(preferences *[]model.NotificationPreference, userId uint) error
+	SaveSettings(setting *model.NotificationSetting, preferences []model.NotificationPreference) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) INotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (nr *notificationRepository) GetNotifications(notifications *[]model.Notification, userId uint, unreadOnly bool) error {
+	query := nr.db.Where("user_id = ?", userId)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Order("created_at DESC, id DESC").Find(notifications).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) CountUnread(userId uint) (int64, error) {
+	var count int64
+	if err := nr.db.Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (nr *notificationRepository) CreateNotification(notification *model.Notification) error {
+	if err := nr.db.Create(notification).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) MarkRead(userId uint, notificationId uint, readAt time.Time) error {
+	result := nr.db.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", notificationId, userId).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", readAt))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (nr *notificationRepository) MarkAllRead(userId uint, readAt time.Time) error {
+	err := nr.db.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userId).Update("read_at", readAt).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) GetSetting(setting *model.NotificationSetting, userId uint) error {
+	if err := nr.db.Where("user_id = ?", userId).First(setting).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) GetPreferences(preferences *[]model.NotificationPreference, userId uint) error {
+	if err := nr.db.Where("user_id = ?", userId).Order("event, channel").Find(preferences).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveSettings は言語・Webhook の送信先・リマインダーのタイミングと、指定されたイベント・チャネルの受信可否をまとめて保存する
+func (nr *notificationRepository) SaveSettings(setting *model.NotificationSetting, preferences []model.NotificationPreference) error {
+	return nr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"locale", "webhook_url", "clock_in_reminder_minutes", "clock_out_reminder_minutes", "updated_at"}),
+		}).Create(setting).Error
+		if err != nil {
+			return err
+		}
+		if len(preferences) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+		}).Create(&preferences).Error
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IOutboxRepository interface {
+	GetPendingEvents(events *[]model.OutboxEvent, now time.Time, limit int) error
+	MarkProcessed(eventId uint, processedAt time.Time) error
+	MarkFailed(event *model.OutboxEvent) error
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) IOutboxRepository {
+	return &outboxRepository{db}
+}
+
+// createOutboxEvent は呼び出し元のトランザクション tx の中でドメインイベントを書き込む
+func createOutboxEvent(tx *gorm.DB, eventType string, aggregateType string, aggregateId uint, data interface{}) error {
+	event, err := model.NewOutboxEvent(eventType, aggregateType, aggregateId, data)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&event).Error
+}
+
+// GetPendingEvents は未処理で再試行の時刻を迎えたイベントを発生順に返す
+func (or *outboxRepository) GetPendingEvents(events *[]model.OutboxEvent, now time.Time, limit int) error {
+	err := or.db.Where("processed_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id").Limit(limit).Find(events).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (or *outboxRepository) MarkProcessed(eventId uint, processedAt time.Time) error {
+	err := or.db.Model(&model.OutboxEvent{}).Where("id = ?", eventId).
+		Update("processed_at", processedAt).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (or *outboxRepository) MarkFailed(event *model.OutboxEvent) error {
+	err := or.db.Model(event).
+		Select("attempts", "next_attempt_at", "last_error").
+		Updates(event).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"go-rest-api/model"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sortColumn は sort パラメータで指定できる項目。value は行からカーソルに保存する値を取り出す
+type sortColumn struct {
+	expr     string
+	nullable bool
+	isTime   bool
+	value    func(row interface{}) interface{}
+}
+
+type sortKey struct {
+	column sortColumn
+	desc   bool
+}
+
+// pageCursor は最後に返した行の並び替えキーの値。別の並び順で使われないよう sort も保存する
+type pageCursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+var errInvalidCursor = model.NewBadRequestError("invalid_cursor", "invalid cursor")
+
+// parseSort は "-due_date,priority" のような sort パラメータを並び替えキーに変換する。
+// 最後に idColumn を加え、同じ値の行も常に同じ順に並ぶようにする
+func parseSort(sort string, columns map[string]sortColumn, idColumn sortColumn) ([]sortKey, error) {
+	keys := []sortKey{}
+	if sort != "" {
+		for _, key := range strings.Split(sort, ",") {
+			key = strings.TrimSpace(key)
+			desc := strings.HasPrefix(key, "-")
+			column, ok := columns[strings.TrimPrefix(key, "-")]
+			if !ok {
+				return nil, model.NewBadRequestError("invalid_sort", "invalid sort key: %s", key)
+			}
+			keys = append(keys, sortKey{column, desc})
+		}
+	}
+	return append(keys, sortKey{column: idColumn}), nil
+}
+
+// sortTerm は ORDER BY の1項目。nullable な項目は NULL を最後に並べるため「NULL か」と値の2項目に分ける
+type sortTerm struct {
+	expr  string
+	desc  bool
+	value interface{}
+}
+
+func sortTerms(keys []sortKey, values []interface{}) []sortTerm {
+	terms := []sortTerm{}
+	for i, k := range keys {
+		var value interface{}
+		if values != nil {
+			value = values[i]
+		}
+		if k.column.nullable {
+			terms = append(terms, sortTerm{expr: "(" + k.column.expr + " IS NULL)", value: value == nil})
+		}
+		terms = append(terms, sortTerm{expr: k.column.expr, desc: k.desc, value: value})
+	}
+	return terms
+}
+
+func orderClause(keys []sortKey) string {
+	orders := []string{}
+	for _, t := range sortTerms(keys, nil) {
+		direction := "ASC"
+		if t.desc {
+			direction = "DESC"
+		}
+		orders = append(orders, t.expr+" "+direction)
+	}
+	return strings.Join(orders, ", ")
+}
+
+// afterCursor はカーソルの行より後に並ぶ行だけを返す条件を追加する。
+// (a, b) の順なら a > x OR (a = x AND b > y) のように、前の項目が等しい場合に次の項目で比べる
+func afterCursor(query *gorm.DB, keys []sortKey, values []interface{}) *gorm.DB {
+	terms := sortTerms(keys, values)
+	conditions := []string{}
+	args := []interface{}{}
+	for i, t := range terms {
+		// 値が NULL の項目は「NULL か」の項目で比べ終えているため、それより後ろの行は次の項目で決まる
+		if t.value == nil {
+			continue
+		}
+		parts := []string{}
+		for _, prev := range terms[:i] {
+			parts = append(parts, prev.expr+" IS NOT DISTINCT FROM ?")
+			args = append(args, prev.value)
+		}
+		op := ">"
+		if t.desc {
+			op = "<"
+		}
+		parts = append(parts, t.expr+" "+op+" ?")
+		args = append(args, t.value)
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	if len(conditions) == 0 {
+		return query.Where("FALSE")
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+func encodeCursor(sort string, keys []sortKey, row interface{}) (string, error) {
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = k.column.value(row)
+	}
+	b, err := json.Marshal(pageCursor{Sort: sort, Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string, sort string, keys []sortKey) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	c := pageCursor{}
+	if err := decoder.Decode(&c); err != nil || c.Sort != sort || len(c.Values) != len(keys) {
+		return nil, errInvalidCursor
+	}
+	for i, v := range c.Values {
+		switch value := v.(type) {
+		case json.Number:
+			if n, err := value.Int64(); err == nil {
+				c.Values[i] = n
+			} else if f, err := value.Float64(); err == nil {
+				c.Values[i] = f
+			}
+		case string:
+			if keys[i].column.isTime {
+				t, err := time.Parse(time.RFC3339Nano, value)
+				if err != nil {
+					return nil, errInvalidCursor
+				}
+				c.Values[i] = t
+			}
+		}
+	}
+	return c.Values, nil
+}
+
+// paginate は並び順とカーソルを適用して limit+1 件を rows に読み込み、続きがあれば次のカーソルを返す。
+// limit が 0 の場合は全件を返す
+func paginate(query *gorm.DB, rows interface{}, sort string, keys []sortKey, page model.PageQuery) (model.PageInfo, error) {
+	query = query.Order(orderClause(keys))
+	if page.Limit <= 0 {
+		return model.PageInfo{}, query.Find(rows).Error
+	}
+	if page.Cursor != "" {
+		values, err := decodeCursor(page.Cursor, sort, keys)
+		if err != nil {
+			return model.PageInfo{}, err
+		}
+		query = afterCursor(query, keys, values)
+	}
+	if err := query.Limit(page.Limit + 1).Find(rows).Error; err != nil {
+		return model.PageInfo{}, err
+	}
+	info := model.PageInfo{Limit: page.Limit}
+	slice := reflect.ValueOf(rows).Elem()
+	if slice.Len() <= page.Limit {
+		return info, nil
+	}
+	slice.SetLen(page.Limit)
+	cursor, err := encodeCursor(sort, keys, slice.Index(page.Limit-1).Interface())
+	if err != nil {
+		return model.PageInfo{}, err
+	}
+	info.NextCursor = cursor
+	info.HasMore = true
+	return info, nil
+}
+
+// timeValue は *time.Time をカーソルに保存できる値にする（nil は NULL）
+func timeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
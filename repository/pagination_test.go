@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"encoding/base64"
+	"go-rest-api/model"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	created := time.Date(2024, 3, 1, 9, 30, 15, 123456789, jst)
+	due := time.Date(2024, 3, 31, 0, 0, 0, 0, jst)
+	tests := []struct {
+		name string
+		sort string
+		task model.Task
+		want []interface{}
+	}{
+		{"id only", "", model.Task{ID: 42}, []interface{}{int64(42)}},
+		{"time with nanoseconds", "-created_at", model.Task{ID: 7, CreatedAt: created}, []interface{}{created, int64(7)}},
+		{"nullable time set", "due_date", model.Task{ID: 3, DueDate: &due}, []interface{}{due, int64(3)}},
+		{"nullable time null", "due_date", model.Task{ID: 3}, []interface{}{nil, int64(3)}},
+		{"rank and string", "-priority,title", model.Task{ID: 9, Priority: model.TaskPriorityHigh, Title: "週報 \"draft\""}, []interface{}{int64(3), "週報 \"draft\"", int64(9)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseSort(tt.sort, taskSortColumns, taskIdColumn)
+			if err != nil {
+				t.Fatalf("parseSort(%q) error = %v", tt.sort, err)
+			}
+			cursor, err := encodeCursor(tt.sort, keys, tt.task)
+			if err != nil {
+				t.Fatalf("encodeCursor() error = %v", err)
+			}
+			got, err := decodeCursor(cursor, tt.sort, keys)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q) error = %v", cursor, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("decodeCursor() = %v, want %v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if wantTime, ok := want.(time.Time); ok {
+					if gotTime, ok := got[i].(time.Time); !ok || !gotTime.Equal(wantTime) {
+						t.Errorf("value %d = %#v, want %v", i, got[i], wantTime)
+					}
+					continue
+				}
+				if got[i] != want {
+					t.Errorf("value %d = %#v, want %#v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	keys, err := parseSort("-created_at", taskSortColumns, taskIdColumn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := encodeCursor("-created_at", keys, model.Task{ID: 1, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{"not base64", "!!!", "-created_at"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"-created_at","v":["2024-01-01T00:00:00Z",1]}`)), "-created_at"},
+		{"not json", encode("cursor"), "-created_at"},
+		{"other sort", valid, "created_at"},
+		{"too few values", encode(`{"s":"-created_at","v":[1]}`), "-created_at"},
+		{"too many values", encode(`{"s":"-created_at","v":["2024-01-01T00:00:00Z",1,2]}`), "-created_at"},
+		{"invalid time", encode(`{"s":"-created_at","v":["yesterday",1]}`), "-created_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, tt.sort, keys); err != errInvalidCursor {
+				t.Errorf("decodeCursor(%q) error = %v, want %v", tt.cursor, err, errInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		wantExpr []string
+		wantDesc []bool
+		wantErr  bool
+	}{
+		{"default", "", []string{"tasks.id"}, []bool{false}, false},
+		{"multiple keys", "-due_date, title", []string{"tasks.due_date", "tasks.title", "tasks.id"}, []bool{true, false, false}, false},
+		{"unknown key", "owner", nil, nil, true},
+		{"empty key", "title,", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseSort(tt.sort, taskSortColumns, taskIdColumn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSort(%q) error = %v, wantErr %v", tt.sort, err, tt.wantErr)
+			}
+			if len(keys) != len(tt.wantExpr) {
+				t.Fatalf("parseSort(%q) returned %d keys, want %d", tt.sort, len(keys), len(tt.wantExpr))
+			}
+			for i, k := range keys {
+				if k.column.expr != tt.wantExpr[i] || k.desc != tt.wantDesc[i] {
+					t.Errorf("key %d = (%s, %v), want (%s, %v)", i, k.column.expr, k.desc, tt.wantExpr[i], tt.wantDesc[i])
+				}
+			}
+		})
+	}
+}
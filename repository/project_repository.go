@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IProjectRepository interface {
+	GetAllProjects(projects *[]model.Project) error
+	GetProjectById(project *model.Project, projectId uint) error
+	CreateProject(project *model.Project) error
+	UpdateProject(project *model.Project, projectId uint) error
+	DeleteProject(projectId uint) error
+	GetProjectsByIds(projects *[]model.Project, projectIds []uint) error
+	ReplaceRates(projectId uint, rates []model.ProjectRate) error
+	ReplaceMembers(projectId uint, members []model.ProjectMember) error
+	GetBudgetAlerts(alerts *[]model.BudgetAlert, projectId uint) error
+	CreateBudgetAlert(alert *model.BudgetAlert) (bool, error)
+}
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+func NewProjectRepository(db *gorm.DB) IProjectRepository {
+	return &projectRepository{db}
+}
+
+func (pr *projectRepository) GetAllProjects(projects *[]model.Project) error {
+	if err := pr.db.Preload("Rates").Preload("Members").Order("code").Find(projects).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *projectRepository) GetProjectById(project *model.Project, projectId uint) error {
+	if err := pr.db.Preload("Rates").Preload("Members").First(project, projectId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *projectRepository) CreateProject(project *model.Project) error {
+	if err := pr.db.Create(project).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *projectRepository) UpdateProject(project *model.Project, projectId uint) error {
+	result := pr.db.Model(&model.Project{}).Where("id = ?", projectId).
+		Select("code", "name", "client", "budget_hours", "hourly_rate", "lead_id", "start_date", "end_date").Updates(project)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return pr.db.Preload("Rates").Preload("Members").First(project, projectId).Error
+}
+
+func (pr *projectRepository) DeleteProject(projectId uint) error {
+	result := pr.db.Where("id = ?", projectId).Delete(&model.Project{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (pr *projectRepository) GetProjectsByIds(projects *[]model.Project, projectIds []uint) error {
+	if err := pr.db.Preload("Rates").Where("id IN ?", projectIds).Order("client, code").Find(projects).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *projectRepository) ReplaceRates(projectId uint, rates []model.ProjectRate) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ?", projectId).Delete(&model.ProjectRate{}).Error; err != nil {
+			return err
+		}
+		if len(rates) == 0 {
+			return nil
+		}
+		for i := range rates {
+			rates[i].ID = 0
+			rates[i].ProjectID = projectId
+		}
+		return tx.Create(&rates).Error
+	})
+}
+
+func (pr *projectRepository) ReplaceMembers(projectId uint, members []model.ProjectMember) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ?", projectId).Delete(&model.ProjectMember{}).Error; err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		for i := range members {
+			members[i].ID = 0
+			members[i].ProjectID = projectId
+		}
+		return tx.Create(&members).Error
+	})
+}
+
+func (pr *projectRepository) GetBudgetAlerts(alerts *[]model.BudgetAlert, projectId uint) error {
+	if err := pr.db.Where("project_id = ?", projectId).Order("threshold_percent").Find(alerts).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateBudgetAlert は同じしきい値の警告がまだなければ記録し、新たに記録したかどうかを返す
+func (pr *projectRepository) CreateBudgetAlert(alert *model.BudgetAlert) (bool, error) {
+	result := pr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IReminderRepository interface {
+	GetReminderTargets(targets *[]model.ReminderTarget, day time.Time) error
+	CreateReminder(reminder *model.Reminder) (bool, error)
+	DeleteReminder(reminderId uint) error
+}
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) IReminderRepository {
+	return &reminderRepository{db}
+}
+
+// GetReminderTargets は在籍中のユーザーについて所定の勤務時間、リマインダーのタイミング、当日の打刻状況を返す。
+// 会社の休日は誰も対象にせず、1日の休暇を取った人も対象にしない
+func (rr *reminderRepository) GetReminderTargets(targets *[]model.ReminderTarget, day time.Time) error {
+	query := `
+SELECT u.id AS user_id,
+	COALESCE(NULLIF(et.scheduled_start_time, ''), @start) AS scheduled_start_time,
+	COALESCE(NULLIF(et.scheduled_minutes_per_day, 0), @minutes) AS scheduled_minutes,
+	COALESCE(ns.clock_in_reminder_minutes, @clock_in) AS clock_in_reminder_minutes,
+	COALESCE(ns.clock_out_reminder_minutes, @clock_out) AS clock_out_reminder_minutes,
+	EXISTS (SELECT 1 FROM attendance_records r
+		WHERE r.user_id = u.id AND r.clock_in_time >= @day_start AND r.clock_in_time < @day_end) AS clocked_in,
+	EXISTS (SELECT 1 FROM attendance_records r
+		WHERE r.user_id = u.id AND r.clock_in_time >= @day_start AND r.clock_in_time < @day_end
+		AND r.clock_out_time < r.clock_in_time) AS open_record,
+	EXISTS (SELECT 1 FROM leave_records l
+		WHERE l.user_id = u.id AND l.date = CAST(@day AS date) AND l.days < 1) AS half_day_leave
+FROM users u
+LEFT JOIN employment_types et ON et.id = u.employment_type_id
+LEFT JOIN notification_settings ns ON ns.user_id = u.id
+WHERE u.deleted_at IS NULL AND u.deactivated_at IS NULL AND u.erased_at IS NULL
+AND (u.hire_date IS NULL OR u.hire_date < @day_end)
+AND (u.termination_date IS NULL OR u.termination_date >= @day_start)
+AND NOT EXISTS (SELECT 1 FROM holidays h WHERE h.date = CAST(@day AS date))
+AND NOT EXISTS (SELECT 1 FROM leave_records l WHERE l.user_id = u.id AND l.date = CAST(@day AS date) AND l.days >= 1)
+ORDER BY u.id`
+	err := rr.db.Raw(query, map[string]interface{}{
+		"day":       day.Format("2006-01-02"),
+		"day_start": day,
+		"day_end":   day.AddDate(0, 0, 1),
+		"start":     model.DefaultScheduledStartTime,
+		"minutes":   model.DefaultScheduledMinutesPerDay,
+		"clock_in":  model.DefaultClockInReminderMinutes,
+		"clock_out": model.DefaultClockOutReminderMinutes,
+	}).Scan(targets).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateReminder は送信済みとして記録する。同じ日・種類のリマインダーが記録済みなら false を返す
+func (rr *reminderRepository) CreateReminder(reminder *model.Reminder) (bool, error) {
+	result := rr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteReminder は送信に失敗したリマインダーの記録を取り消し、次の実行で再送できるようにする
+func (rr *reminderRepository) DeleteReminder(reminderId uint) error {
+	if err := rr.db.Delete(&model.Reminder{}, reminderId).Error; err != nil {
+		return err
+	}
+	return nil
+}
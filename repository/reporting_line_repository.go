@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IReportingLineRepository interface {
+	GetActiveLinesByManagers(lines *[]model.ReportingLine, managerIds []uint, date time.Time) error
+	GetLinesByUser(lines *[]model.ReportingLine, userId uint) error
+	CreateReportingLine(line *model.ReportingLine) error
+	EndReportingLine(lineId uint, effectiveTo time.Time) error
+	DeleteReportingLine(lineId uint) error
+}
+
+type reportingLineRepository struct {
+	db *gorm.DB
+}
+
+func NewReportingLineRepository(db *gorm.DB) IReportingLineRepository {
+	return &reportingLineRepository{db}
+}
+
+func (rlr *reportingLineRepository) GetActiveLinesByManagers(lines *[]model.ReportingLine, managerIds []uint, date time.Time) error {
+	// 指定日時点で有効な（開始済みかつ未終了の）レポートラインのみ取得
+	err := rlr.db.Preload("User").
+		Where("manager_id IN ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", managerIds, date, date).
+		Order("user_id").Find(lines).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rlr *reportingLineRepository) GetLinesByUser(lines *[]model.ReportingLine, userId uint) error {
+	if err := rlr.db.Where("user_id = ?", userId).Order("effective_from").Find(lines).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rlr *reportingLineRepository) CreateReportingLine(line *model.ReportingLine) error {
+	if err := rlr.db.Create(line).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rlr *reportingLineRepository) EndReportingLine(lineId uint, effectiveTo time.Time) error {
+	result := rlr.db.Model(&model.ReportingLine{}).Where("id = ?", lineId).Update("effective_to", effectiveTo)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (rlr *reportingLineRepository) DeleteReportingLine(lineId uint) error {
+	result := rlr.db.Where("id = ?", lineId).Delete(&model.ReportingLine{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
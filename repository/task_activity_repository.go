@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ITaskActivityRepository interface {
+	GetActivities(activities *[]model.TaskActivity, taskId uint) error
+	GetActivitiesByActor(activities *[]model.TaskActivity, actorId uint) error
+	CreateActivities(activities []model.TaskActivity) error
+	GetComments(comments *[]model.TaskComment, taskId uint) error
+	GetCommentsByAuthor(comments *[]model.TaskComment, authorId uint) error
+	GetCommentById(comment *model.TaskComment, taskId uint, commentId uint) error
+	CreateComment(comment *model.TaskComment) error
+	UpdateComment(comment *model.TaskComment, body string) error
+	DeleteComment(comment model.TaskComment, actorId uint) error
+}
+
+type taskActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskActivityRepository(db *gorm.DB) ITaskActivityRepository {
+	return &taskActivityRepository{db}
+}
+
+func (tar *taskActivityRepository) GetActivities(activities *[]model.TaskActivity, taskId uint) error {
+	if err := tar.db.Preload("Actor").Where("task_id = ?", taskId).Order("created_at, id").Find(activities).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tar *taskActivityRepository) GetActivitiesByActor(activities *[]model.TaskActivity, actorId uint) error {
+	if err := tar.db.Preload("Actor").Where("actor_id = ?", actorId).Order("created_at, id").Find(activities).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tar *taskActivityRepository) CreateActivities(activities []model.TaskActivity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	if err := tar.db.Create(&activities).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tar *taskActivityRepository) GetComments(comments *[]model.TaskComment, taskId uint) error {
+	err := tar.db.Preload("Author").
+		Preload("Revisions", func(db *gorm.DB) *gorm.DB { return db.Order("edited_at") }).
+		Where("task_id = ?", taskId).Order("created_at, id").Find(comments).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tar *taskActivityRepository) GetCommentsByAuthor(comments *[]model.TaskComment, authorId uint) error {
+	err := tar.db.Preload("Author").
+		Preload("Revisions", func(db *gorm.DB) *gorm.DB { return db.Order("edited_at") }).
+		Where("author_id = ?", authorId).Order("created_at, id").Find(comments).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tar *taskActivityRepository) GetCommentById(comment *model.TaskComment, taskId uint, commentId uint) error {
+	err := tar.db.Preload("Author").
+		Preload("Revisions", func(db *gorm.DB) *gorm.DB { return db.Order("edited_at") }).
+		Where("task_id = ?", taskId).First(comment, commentId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateComment はコメントと、その追加を示すアクティビティを同時に記録する
+func (tar *taskActivityRepository) CreateComment(comment *model.TaskComment) error {
+	return tar.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Author", "Task").Create(comment).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.TaskActivity{
+			TaskID:    comment.TaskID,
+			ActorID:   comment.AuthorID,
+			Type:      model.TaskActivityCommentAdded,
+			CommentID: &comment.ID,
+		}).Error
+	})
+}
+
+// UpdateComment は編集前の本文を履歴に残してから本文を書き換える
+func (tar *taskActivityRepository) UpdateComment(comment *model.TaskComment, body string) error {
+	now := time.Now()
+	return tar.db.Transaction(func(tx *gorm.DB) error {
+		revision := model.TaskCommentRevision{CommentID: comment.ID, Body: comment.Body, EditedAt: now}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&model.TaskComment{}).Where("id = ? AND author_id = ?", comment.ID, comment.AuthorID).
+			Updates(map[string]interface{}{"body": body, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return model.ErrNotFound
+		}
+		if err := tx.Create(&model.TaskActivity{
+			TaskID:    comment.TaskID,
+			ActorID:   comment.AuthorID,
+			Type:      model.TaskActivityCommentEdited,
+			CommentID: &comment.ID,
+		}).Error; err != nil {
+			return err
+		}
+		comment.Revisions = append(comment.Revisions, revision)
+		comment.Body = body
+		comment.UpdatedAt = now
+		return nil
+	})
+}
+
+// DeleteComment はコメントを削除し、履歴には削除されたことだけを残す
+func (tar *taskActivityRepository) DeleteComment(comment model.TaskComment, actorId uint) error {
+	return tar.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.TaskActivity{}).Where("comment_id = ?", comment.ID).Update("comment_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.TaskComment{}, comment.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return model.ErrNotFound
+		}
+		return tx.Create(&model.TaskActivity{
+			TaskID:  comment.TaskID,
+			ActorID: actorId,
+			Type:    model.TaskActivityCommentDeleted,
+		}).Error
+	})
+}
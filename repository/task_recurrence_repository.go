@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITaskRecurrenceRepository interface {
+	GetRecurrencesByUser(recurrences *[]model.TaskRecurrence, userId uint) error
+	GetRecurrenceById(recurrence *model.TaskRecurrence, userId uint, recurrenceId uint) error
+	GetActiveRecurrences(recurrences *[]model.TaskRecurrence, date time.Time) error
+	CreateRecurrence(recurrence *model.TaskRecurrence) error
+	UpdateRecurrence(recurrence *model.TaskRecurrence, userId uint, recurrenceId uint) error
+	DeleteRecurrence(userId uint, recurrenceId uint) error
+	GetExceptionDates(dates *[]time.Time, recurrenceId uint) error
+	CreateException(recurrenceId uint, date time.Time) error
+	CreateOccurrences(recurrenceId uint, tasks []model.Task, generatedUntil time.Time) (int64, error)
+	DeletePendingOccurrences(recurrenceId uint, from time.Time) error
+}
+
+type taskRecurrenceRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskRecurrenceRepository(db *gorm.DB) ITaskRecurrenceRepository {
+	return &taskRecurrenceRepository{db}
+}
+
+func (rr *taskRecurrenceRepository) GetRecurrencesByUser(recurrences *[]model.TaskRecurrence, userId uint) error {
+	if err := rr.db.Where("user_id = ?", userId).Order("id").Find(recurrences).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *taskRecurrenceRepository) GetRecurrenceById(recurrence *model.TaskRecurrence, userId uint, recurrenceId uint) error {
+	if err := rr.db.Where("user_id = ?", userId).First(recurrence, recurrenceId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *taskRecurrenceRepository) GetActiveRecurrences(recurrences *[]model.TaskRecurrence, date time.Time) error {
+	if err := rr.db.Where("until IS NULL OR until >= ?", date).Order("id").Find(recurrences).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *taskRecurrenceRepository) CreateRecurrence(recurrence *model.TaskRecurrence) error {
+	if err := rr.db.Create(recurrence).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *taskRecurrenceRepository) UpdateRecurrence(recurrence *model.TaskRecurrence, userId uint, recurrenceId uint) error {
+	result := rr.db.Model(recurrence).Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ?", recurrenceId, userId).
+		Select("title", "description", "priority", "estimated_hours", "visibility", "department",
+			"assignee_id", "project_id", "rrule", "start_date", "until", "generated_until").
+		Updates(recurrence)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (rr *taskRecurrenceRepository) DeleteRecurrence(userId uint, recurrenceId uint) error {
+	result := rr.db.Where("id = ? AND user_id = ?", recurrenceId, userId).Delete(&model.TaskRecurrence{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (rr *taskRecurrenceRepository) GetExceptionDates(dates *[]time.Time, recurrenceId uint) error {
+	err := rr.db.Model(&model.TaskRecurrenceException{}).
+		Where("recurrence_id = ?", recurrenceId).Pluck("occurrence_date", dates).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *taskRecurrenceRepository) CreateException(recurrenceId uint, date time.Time) error {
+	exception := model.TaskRecurrenceException{RecurrenceID: recurrenceId, OccurrenceDate: date}
+	if err := rr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&exception).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateOccurrences は生成済みの回を飛ばして新しい回を作成し、生成済みの日付を進める
+func (rr *taskRecurrenceRepository) CreateOccurrences(recurrenceId uint, tasks []model.Task, generatedUntil time.Time) (int64, error) {
+	var created int64
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		if len(tasks) > 0 {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tasks)
+			if result.Error != nil {
+				return result.Error
+			}
+			created = result.RowsAffected
+		}
+		return tx.Model(&model.TaskRecurrence{}).Where("id = ?", recurrenceId).
+			Update("generated_until", generatedUntil).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// DeletePendingOccurrences は from 以降の未着手で個別編集されておらず、作業時間の記録もない回を削除する
+func (rr *taskRecurrenceRepository) DeletePendingOccurrences(recurrenceId uint, from time.Time) error {
+	err := rr.db.
+		Where("recurrence_id = ? AND occurrence_date >= ? AND status = ? AND detached = ?", recurrenceId, from, model.TaskStatusTodo, false).
+		Where("NOT EXISTS (SELECT 1 FROM time_entries WHERE time_entries.task_id = tasks.id)").
+		Delete(&model.Task{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
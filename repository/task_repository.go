@@ -1,19 +1,24 @@
 package repository
 
 import (
-	"fmt"
 	"go-rest-api/model"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type ITaskRepository interface {
-	GetAllTasks(tasks *[]model.Task, userId uint) error
+	GetAllTasks(tasks *[]model.Task, userId uint, filter model.TaskFilter, page model.PageQuery) (model.PageInfo, error)
 	GetTaskById(task *model.Task, userId uint, taskId uint) error
 	CreateTask(task *model.Task) error
 	UpdateTask(task *model.Task, userId uint, taskId uint) error
 	DeleteTask(userId uint, taskId uint) error
+	GetBoardTasks(tasks *[]model.Task, department string, filter model.TaskFilter) error
+	GetSharedTaskById(task *model.Task, department string, taskId uint) error
+	UpdateSharedTask(task *model.Task, department string, taskId uint) error
+	ClaimTask(task *model.Task, department string, taskId uint, userId uint) error
+	DeleteSharedTask(department string, taskId uint) error
 }
 
 type taskRepository struct {
@@ -24,15 +29,83 @@ func NewTaskRepository(db *gorm.DB) ITaskRepository {
 	return &taskRepository{db}
 }
 
-func (tr *taskRepository) GetAllTasks(tasks *[]model.Task, userId uint) error {
-	if err := tr.db.Joins("User").Where("user_id=?", userId).Order("created_at").Find(tasks).Error; err != nil {
-		return err
+// ソートに使えるカラム。priority は文字列順ではなく重要度順に並べる
+var taskSortColumns = map[string]sortColumn{
+	"created_at": {expr: "tasks.created_at", isTime: true, value: func(row interface{}) interface{} { return row.(model.Task).CreatedAt }},
+	"updated_at": {expr: "tasks.updated_at", isTime: true, value: func(row interface{}) interface{} { return row.(model.Task).UpdatedAt }},
+	"due_date":   {expr: "tasks.due_date", nullable: true, isTime: true, value: func(row interface{}) interface{} { return timeValue(row.(model.Task).DueDate) }},
+	"status":     {expr: "tasks.status", value: func(row interface{}) interface{} { return row.(model.Task).Status }},
+	"title":      {expr: "tasks.title", value: func(row interface{}) interface{} { return row.(model.Task).Title }},
+	"priority": {
+		expr:  "CASE tasks.priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END",
+		value: func(row interface{}) interface{} { return taskPriorityRank(row.(model.Task).Priority) },
+	},
+}
+
+var taskIdColumn = sortColumn{expr: "tasks.id", value: func(row interface{}) interface{} { return row.(model.Task).ID }}
+
+// taskPriorityRank は ORDER BY の CASE 式と同じ重要度の順位を返す
+func taskPriorityRank(priority string) int {
+	switch priority {
+	case model.TaskPriorityUrgent:
+		return 4
+	case model.TaskPriorityHigh:
+		return 3
+	case model.TaskPriorityMedium:
+		return 2
 	}
-	return nil
+	return 1
+}
+
+// taskSort は sort パラメータの既定値（作成日時順）を補う
+func taskSort(filter model.TaskFilter) string {
+	if filter.Sort == "" {
+		return "created_at"
+	}
+	return filter.Sort
+}
+
+// applyTaskFilter は一覧取得の絞り込み条件をクエリに追加する
+func applyTaskFilter(query *gorm.DB, filter model.TaskFilter) *gorm.DB {
+	if filter.Status != "" {
+		query = query.Where("tasks.status IN ?", strings.Split(filter.Status, ","))
+	}
+	if filter.Priority != "" {
+		query = query.Where("tasks.priority IN ?", strings.Split(filter.Priority, ","))
+	}
+	if filter.AssigneeID != nil {
+		query = query.Where("tasks.assignee_id = ?", *filter.AssigneeID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("tasks.project_id = ?", *filter.ProjectID)
+	}
+	if filter.DueFrom != nil {
+		query = query.Where("tasks.due_date >= ?", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		query = query.Where("tasks.due_date < ?", *filter.DueTo)
+	}
+	if filter.From != nil {
+		query = query.Where("tasks.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("tasks.created_at < ?", *filter.To)
+	}
+	return query
+}
+
+func (tr *taskRepository) GetAllTasks(tasks *[]model.Task, userId uint, filter model.TaskFilter, page model.PageQuery) (model.PageInfo, error) {
+	sort := taskSort(filter)
+	keys, err := parseSort(sort, taskSortColumns, taskIdColumn)
+	if err != nil {
+		return model.PageInfo{}, err
+	}
+	query := applyTaskFilter(tr.db.Joins("User").Where("(tasks.user_id = ? OR tasks.assignee_id = ?)", userId, userId), filter)
+	return paginate(query, tasks, sort, keys, page)
 }
 
 func (tr *taskRepository) GetTaskById(task *model.Task, userId uint, taskId uint) error {
-	if err := tr.db.Joins("User").Where("user_id=?", userId).First(task, taskId).Error; err != nil {
+	if err := tr.db.Joins("User").Where("tasks.user_id = ? OR tasks.assignee_id = ?", userId, userId).First(task, taskId).Error; err != nil {
 		return err
 	}
 	return nil
@@ -46,23 +119,101 @@ func (tr *taskRepository) CreateTask(task *model.Task) error {
 }
 
 func (tr *taskRepository) UpdateTask(task *model.Task, userId uint, taskId uint) error {
-	result := tr.db.Model(task).Clauses(clause.Returning{}).Where("id=? AND user_id=?", taskId, userId).Update("title", task.Title)
+	// 作成者と担当者のどちらも更新できる
+	result := tr.db.Model(task).Clauses(clause.Returning{}).
+		Where("id=? AND (user_id=? OR assignee_id=?)", taskId, userId, userId).
+		Select("title", "description", "status", "priority", "due_date", "estimated_hours", "assignee_id", "project_id", "detached").
+		Updates(task)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected < 1 {
-		return fmt.Errorf("object does not exist")
+		return model.ErrNotFound
 	}
 	return nil
 }
 
 func (tr *taskRepository) DeleteTask(userId uint, taskId uint) error {
-	result := tr.db.Where("id=? AND user_id=?", taskId, userId).Delete(&model.Task{})
+	return deleteUnlockedTask(tr.db, taskId, "id=? AND user_id=?", taskId, userId)
+}
+
+func (tr *taskRepository) GetBoardTasks(tasks *[]model.Task, department string, filter model.TaskFilter) error {
+	sort := taskSort(filter)
+	keys, err := parseSort(sort, taskSortColumns, taskIdColumn)
+	if err != nil {
+		return err
+	}
+	query := applyTaskFilter(tr.db.Joins("User").Where("tasks.visibility = ? AND tasks.department = ?", model.TaskVisibilityDepartment, department), filter)
+	_, err = paginate(query, tasks, sort, keys, model.PageQuery{})
+	return err
+}
+
+func (tr *taskRepository) GetSharedTaskById(task *model.Task, department string, taskId uint) error {
+	// department が空の場合（管理者）は部署を問わない
+	query := tr.db.Joins("User").Where("tasks.visibility = ?", model.TaskVisibilityDepartment)
+	if department != "" {
+		query = query.Where("tasks.department = ?", department)
+	}
+	if err := query.First(task, taskId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tr *taskRepository) UpdateSharedTask(task *model.Task, department string, taskId uint) error {
+	result := tr.db.Model(task).Clauses(clause.Returning{}).
+		Where("id = ? AND visibility = ? AND department = ?", taskId, model.TaskVisibilityDepartment, department).
+		Select("title", "description", "status", "priority", "due_date", "estimated_hours", "assignee_id", "project_id", "detached").
+		Updates(task)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (tr *taskRepository) ClaimTask(task *model.Task, department string, taskId uint, userId uint) error {
+	// 担当者が未設定の共有タスクのみ引き受けられる
+	result := tr.db.Model(task).Clauses(clause.Returning{}).
+		Where("id = ? AND visibility = ? AND department = ? AND assignee_id IS NULL", taskId, model.TaskVisibilityDepartment, department).
+		Update("assignee_id", userId)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected < 1 {
-		return fmt.Errorf("object does not exist")
+		return model.NewConflictError("task_not_claimable", "task is not available to claim")
 	}
 	return nil
 }
+
+func (tr *taskRepository) DeleteSharedTask(department string, taskId uint) error {
+	return deleteUnlockedTask(tr.db, taskId, "id = ? AND visibility = ? AND department = ?", taskId, model.TaskVisibilityDepartment, department)
+}
+
+// deleteUnlockedTask は条件に合うタスクを削除する。作業時間はタスクと一緒に削除されるため、
+// 提出中・承認済みのタイムシートの週に作業時間があるタスクは削除しない
+func deleteUnlockedTask(db *gorm.DB, taskId uint, query string, args ...interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var locked int64
+		err := tx.Model(&model.TimeEntry{}).
+			Joins("JOIN timesheets ON timesheets.user_id = time_entries.user_id AND time_entries.started_at >= timesheets.week_start AND time_entries.started_at < timesheets.week_start + 7").
+			Where("time_entries.task_id = ? AND timesheets.status IN ?", taskId, []string{model.TimesheetStatusSubmitted, model.TimesheetStatusApproved}).
+			Count(&locked).Error
+		if err != nil {
+			return err
+		}
+		if locked > 0 {
+			return model.NewConflictError("task_has_locked_entries", "task has time entries in a submitted or approved timesheet")
+		}
+		result := tx.Where(query, args...).Delete(&model.Task{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return model.ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type ITimeAllocationRepository interface {
+	GetAllocationsByRecord(allocations *[]model.TimeAllocation, userId uint, recordId uint) error
+	ReplaceAllocations(userId uint, recordId uint, allocations *[]model.TimeAllocation) error
+}
+
+type timeAllocationRepository struct {
+	db *gorm.DB
+}
+
+func NewTimeAllocationRepository(db *gorm.DB) ITimeAllocationRepository {
+	return &timeAllocationRepository{db}
+}
+
+func (tar *timeAllocationRepository) GetAllocationsByRecord(allocations *[]model.TimeAllocation, userId uint, recordId uint) error {
+	if err := tar.db.Where("user_id = ? AND attendance_record_id = ?", userId, recordId).Order("id").Find(allocations).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tar *timeAllocationRepository) ReplaceAllocations(userId uint, recordId uint, allocations *[]model.TimeAllocation) error {
+	// 1日分の按分はまとめて置き換え、合計時間の検証結果と不整合が出ないようにする
+	return tar.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND attendance_record_id = ?", userId, recordId).Delete(&model.TimeAllocation{}).Error; err != nil {
+			return err
+		}
+		if len(*allocations) == 0 {
+			return nil
+		}
+		return tx.Create(allocations).Error
+	})
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ITimeEntryRepository interface {
+	GetRunningEntry(entry *model.TimeEntry, userId uint) error
+	GetEntryById(entry *model.TimeEntry, userId uint, entryId uint) error
+	GetEntriesByUser(entries *[]model.TimeEntry, userId uint) error
+	GetEntriesByRange(entries *[]model.TimeEntry, userId uint, from time.Time, to time.Time) error
+	CountOverlapping(userId uint, start time.Time, end time.Time) (int64, error)
+	CreateEntry(entry *model.TimeEntry) error
+	StopEntry(entry *model.TimeEntry, endedAt time.Time) error
+	DeleteEntry(userId uint, entryId uint) error
+	SetBillable(entry *model.TimeEntry, userId uint, entryId uint, billable bool) error
+	GetBillingRows(rows *[]model.BillingRow, from time.Time, to time.Time, client string) error
+	GetProjectWeeklyMinutes(rows *[]model.WeeklyMinutesRow, projectId uint) error
+}
+
+type timeEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewTimeEntryRepository(db *gorm.DB) ITimeEntryRepository {
+	return &timeEntryRepository{db}
+}
+
+func (ter *timeEntryRepository) GetRunningEntry(entry *model.TimeEntry, userId uint) error {
+	if err := ter.db.Preload("Task").Where("user_id = ? AND ended_at IS NULL", userId).First(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ter *timeEntryRepository) GetEntryById(entry *model.TimeEntry, userId uint, entryId uint) error {
+	if err := ter.db.Preload("Task").Where("user_id = ?", userId).First(entry, entryId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ter *timeEntryRepository) GetEntriesByUser(entries *[]model.TimeEntry, userId uint) error {
+	if err := ter.db.Preload("Task").Where("user_id = ?", userId).Order("started_at").Find(entries).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ter *timeEntryRepository) GetEntriesByRange(entries *[]model.TimeEntry, userId uint, from time.Time, to time.Time) error {
+	err := ter.db.Preload("Task").
+		Where("user_id = ? AND started_at >= ? AND started_at < ?", userId, from, to).
+		Order("started_at").Find(entries).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ter *timeEntryRepository) CountOverlapping(userId uint, start time.Time, end time.Time) (int64, error) {
+	// 計測中のエントリは現在も継続しているものとして扱う
+	var count int64
+	err := ter.db.Model(&model.TimeEntry{}).
+		Where("user_id = ? AND started_at < ? AND COALESCE(ended_at, NOW()) > ?", userId, end, start).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (ter *timeEntryRepository) CreateEntry(entry *model.TimeEntry) error {
+	if err := ter.db.Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ter *timeEntryRepository) StopEntry(entry *model.TimeEntry, endedAt time.Time) error {
+	result := ter.db.Model(entry).Where("ended_at IS NULL").Update("ended_at", endedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.NewConflictError("timer_not_running", "timer is not running")
+	}
+	return nil
+}
+
+func (ter *timeEntryRepository) DeleteEntry(userId uint, entryId uint) error {
+	result := ter.db.Where("id = ? AND user_id = ?", entryId, userId).Delete(&model.TimeEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ter *timeEntryRepository) SetBillable(entry *model.TimeEntry, userId uint, entryId uint, billable bool) error {
+	result := ter.db.Model(&model.TimeEntry{}).
+		Where("id = ? AND user_id = ?", entryId, userId).Update("billable", billable)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return ter.db.Preload("Task").First(entry, entryId).Error
+}
+
+// GetBillingRows は期間内に終了した作業時間を、タスクのプロジェクトと記録時の請求ロール・単価ごとに集計する。
+// client が空でなければそのクライアントのプロジェクトだけを対象にする
+func (ter *timeEntryRepository) GetBillingRows(rows *[]model.BillingRow, from time.Time, to time.Time, client string) error {
+	query := ter.db.Table("time_entries").
+		Select("tasks.project_id AS project_id, time_entries.billing_role AS role, time_entries.hourly_rate AS hourly_rate, time_entries.billable AS billable, "+
+			"SUM(EXTRACT(EPOCH FROM time_entries.ended_at - time_entries.started_at)) / 60 AS minutes").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id").
+		Joins("JOIN projects ON projects.id = tasks.project_id").
+		Where("time_entries.ended_at IS NOT NULL AND time_entries.started_at >= ? AND time_entries.started_at < ?", from, to)
+	if client != "" {
+		query = query.Where("projects.client = ?", client)
+	}
+	err := query.Group("tasks.project_id, time_entries.billing_role, time_entries.hourly_rate, time_entries.billable").Scan(rows).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetProjectWeeklyMinutes はプロジェクトのタスクに記録された作業時間を週ごとに集計する（計測中は含めない）
+func (ter *timeEntryRepository) GetProjectWeeklyMinutes(rows *[]model.WeeklyMinutesRow, projectId uint) error {
+	err := ter.db.Table("time_entries").
+		Select("DATE_TRUNC('week', time_entries.started_at) AS week_start, "+
+			"SUM(EXTRACT(EPOCH FROM time_entries.ended_at - time_entries.started_at)) / 60 AS minutes").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id").
+		Where("tasks.project_id = ? AND time_entries.ended_at IS NOT NULL", projectId).
+		Group("week_start").Order("week_start").Scan(rows).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,25 +1,32 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"go-rest-api/model"
 	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IAttendanceRecordRepository interface {
 	GetRecordByDate(record *model.AttendanceRecord, userId uint, date time.Time) error
-	GetAllRecords(records *[]model.AttendanceRecord, userId uint) error
+	GetAllRecords(records *[]model.AttendanceRecord, userId uint, filter model.AttendanceRecordFilter, page model.PageQuery) (model.PageInfo, error)
 	GetRecordById(record *model.AttendanceRecord, userId uint, recordId uint) error
-	GetRecordsByDate(records *[]model.AttendanceRecord, date time.Time) error
-	GetRecordsByDepartment(records *[]model.AttendanceRecord, department string) error
-	GetRecordsByDateDepartment(records *[]model.AttendanceRecord, date time.Time, department string) error
-	GetAllUsers(records *[]model.User) error
+	GetRecordsByDate(records *[]model.AttendanceRecord, date time.Time, employmentType string) error
+	GetRecordsByDepartment(records *[]model.AttendanceRecord, filter model.AttendanceRecordFilter, page model.PageQuery) (model.PageInfo, error)
+	GetRecordsByDateDepartment(records *[]model.AttendanceRecord, date time.Time, department string, employmentType string) error
+	GetRecordsByUsersDate(records *[]model.AttendanceRecord, userIds []uint, date time.Time) error
+	GetAllUsers(records *[]model.User, filter model.UserFilter, page model.PageQuery) (model.PageInfo, error)
+	GetEmployeeStats(stats *[]model.EmployeeAttendanceStats, department string, from time.Time, to time.Time) error
+	GetDepartmentStats(stats *model.DepartmentAttendanceStats, department string, from time.Time, to time.Time) error
 	CreateRecord(record *model.AttendanceRecord) error
 	UpdateRecord(record *model.AttendanceRecord, userId uint, recordId uint) error
 	DeleteRecord(userId uint, recordId uint) error
+	StartBreak(brk *model.AttendanceBreak, userId uint) error
+	EndBreak(brk *model.AttendanceBreak, userId uint, recordId uint, endedAt time.Time) error
 }
 
 type attendanceRecordRepository struct {
@@ -30,6 +37,56 @@ func NewAttendanceRecordRepository(db *gorm.DB) IAttendanceRecordRepository {
 	return &attendanceRecordRepository{db}
 }
 
+// byEmploymentType は雇用区分コードが指定された場合のみユーザーを絞り込む
+func byEmploymentType(column string, employmentType string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if employmentType == "" {
+			return db
+		}
+		return db.Where(column+" IN (SELECT users.id FROM users JOIN employment_types ON employment_types.id = users.employment_type_id WHERE employment_types.code = ?)", employmentType)
+	}
+}
+
+var recordSortColumns = map[string]sortColumn{
+	"clock_in_time":  {expr: "attendance_records.clock_in_time", isTime: true, value: func(row interface{}) interface{} { return row.(model.AttendanceRecord).ClockInTime }},
+	"clock_out_time": {expr: "attendance_records.clock_out_time", isTime: true, value: func(row interface{}) interface{} { return row.(model.AttendanceRecord).ClockOutTime }},
+	"created_at":     {expr: "attendance_records.created_at", isTime: true, value: func(row interface{}) interface{} { return row.(model.AttendanceRecord).CreatedAt }},
+	"user_id":        {expr: "attendance_records.user_id", value: func(row interface{}) interface{} { return row.(model.AttendanceRecord).UserID }},
+}
+
+var recordIdColumn = sortColumn{expr: "attendance_records.id", value: func(row interface{}) interface{} { return row.(model.AttendanceRecord).ID }}
+
+var userSortColumns = map[string]sortColumn{
+	"name":       {expr: "users.name", value: func(row interface{}) interface{} { return row.(model.User).Name }},
+	"email":      {expr: "users.email", value: func(row interface{}) interface{} { return row.(model.User).Email }},
+	"department": {expr: "users.department", value: func(row interface{}) interface{} { return row.(model.User).Department }},
+	"created_at": {expr: "users.created_at", isTime: true, value: func(row interface{}) interface{} { return row.(model.User).CreatedAt }},
+	"hire_date":  {expr: "users.hire_date", nullable: true, isTime: true, value: func(row interface{}) interface{} { return timeValue(row.(model.User).HireDate) }},
+}
+
+var userIdColumn = sortColumn{expr: "users.id", value: func(row interface{}) interface{} { return row.(model.User).ID }}
+
+// recordSort は sort パラメータが空の場合に既定の並び順を補う
+func recordSort(filter model.AttendanceRecordFilter, def string) string {
+	if filter.Sort == "" {
+		return def
+	}
+	return filter.Sort
+}
+
+// byClockInRange は出勤時刻が From 以上 To 未満の記録に絞り込む
+func byClockInRange(filter model.AttendanceRecordFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.From != nil {
+			db = db.Where("attendance_records.clock_in_time >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("attendance_records.clock_in_time < ?", *filter.To)
+		}
+		return db
+	}
+}
+
 func (ar *attendanceRecordRepository) GetRecordByDate(record *model.AttendanceRecord, userId uint, date time.Time) error {
 	// 日付のみを抽出（時間部分は無視）
 
@@ -37,42 +94,46 @@ func (ar *attendanceRecordRepository) GetRecordByDate(record *model.AttendanceRe
 	dayEnd := dayStart.Add(24 * time.Hour)
 
 	// 指定された日付に一致するレコードを検索
-	err := ar.db.Where("user_id = ? AND clock_in_time >= ? AND clock_in_time < ?", userId, dayStart, dayEnd).First(record).Error
+	err := ar.db.Preload("Breaks").Where("user_id = ? AND clock_in_time >= ? AND clock_in_time < ?", userId, dayStart, dayEnd).First(record).Error
 
 	if err != nil {
 		return err
 	}
 	return nil
 }
-func (ar *attendanceRecordRepository) GetRecordsByDate(records *[]model.AttendanceRecord, date time.Time) error {
+func (ar *attendanceRecordRepository) GetRecordsByDate(records *[]model.AttendanceRecord, date time.Time, employmentType string) error {
 	// 日付の開始時刻と終了時刻を計算
 	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	dayEnd := dayStart.Add(24 * time.Hour)
 	fmt.Println(dayStart)
 	// 指定された日付に一致するレコードを検索
-	err := ar.db.Where("clock_in_time >= ? AND clock_in_time < ?", dayStart, dayEnd).Find(records).Error
+	err := ar.db.Preload("User.EmploymentType").Preload("Breaks").Scopes(byEmploymentType("attendance_records.user_id", employmentType)).
+		Where("clock_in_time >= ? AND clock_in_time < ?", dayStart, dayEnd).Find(records).Error
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
-func (ar *attendanceRecordRepository) GetRecordsByDepartment(records *[]model.AttendanceRecord, department string) error {
-
-	err := ar.db.Joins("join users on users.id = attendance_records.user_id").Where("users.department = ?", department).Find(records).Error
+func (ar *attendanceRecordRepository) GetRecordsByDepartment(records *[]model.AttendanceRecord, filter model.AttendanceRecordFilter, page model.PageQuery) (model.PageInfo, error) {
+	sort := recordSort(filter, "clock_in_time")
+	keys, err := parseSort(sort, recordSortColumns, recordIdColumn)
 	if err != nil {
-		return err
+		return model.PageInfo{}, err
 	}
-
-	return nil
+	query := ar.db.Preload("User.EmploymentType").Preload("Breaks").Joins("join users on users.id = attendance_records.user_id").
+		Scopes(byEmploymentType("attendance_records.user_id", filter.EmploymentType), byClockInRange(filter)).
+		Where("users.department = ?", filter.Department)
+	return paginate(query, records, sort, keys, page)
 }
 
-func (ar *attendanceRecordRepository) GetRecordsByDateDepartment(records *[]model.AttendanceRecord, date time.Time, department string) error {
+func (ar *attendanceRecordRepository) GetRecordsByDateDepartment(records *[]model.AttendanceRecord, date time.Time, department string, employmentType string) error {
 	// 日付の開始時刻と終了時刻を計算
 	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	dayEnd := dayStart.Add(24 * time.Hour)
 
-	err := ar.db.Preload("User").Joins("join users on users.id = attendance_records.user_id").
+	err := ar.db.Preload("User.EmploymentType").Preload("Breaks").Joins("join users on users.id = attendance_records.user_id").
+		Scopes(byEmploymentType("attendance_records.user_id", employmentType)).
 		Where("attendance_records.clock_in_time >= ? AND attendance_records.clock_in_time < ? AND users.department = ?", dayStart, dayEnd, department).
 		Find(records).Error
 
@@ -83,52 +144,282 @@ func (ar *attendanceRecordRepository) GetRecordsByDateDepartment(records *[]mode
 	return nil
 }
 
-func (ar *attendanceRecordRepository) GetAllUsers(records *[]model.User) error {
-	if err := ar.db.Order("id asc").Find(records).Error; err != nil {
+func (ar *attendanceRecordRepository) GetRecordsByUsersDate(records *[]model.AttendanceRecord, userIds []uint, date time.Time) error {
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	dayEnd := dayStart.Add(24 * time.Hour)
+
+	err := ar.db.Where("user_id IN ? AND clock_in_time >= ? AND clock_in_time < ?", userIds, dayStart, dayEnd).
+		Order("clock_in_time").Find(records).Error
+	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (ar *attendanceRecordRepository) GetAllRecords(records *[]model.AttendanceRecord, userId uint) error {
-	if err := ar.db.Joins("User").Where("user_id = ?", userId).Order("created_at").Find(records).Error; err != nil {
-		return err
+func (ar *attendanceRecordRepository) GetAllUsers(records *[]model.User, filter model.UserFilter, page model.PageQuery) (model.PageInfo, error) {
+	keys, err := parseSort(filter.Sort, userSortColumns, userIdColumn)
+	if err != nil {
+		return model.PageInfo{}, err
 	}
-	return nil
+	query := ar.db.Preload("EmploymentType").Scopes(byEmploymentType("users.id", filter.EmploymentType))
+	if filter.From != nil {
+		query = query.Where("users.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("users.created_at < ?", *filter.To)
+	}
+	return paginate(query, records, filter.Sort, keys, page)
+}
+
+func (ar *attendanceRecordRepository) GetAllRecords(records *[]model.AttendanceRecord, userId uint, filter model.AttendanceRecordFilter, page model.PageQuery) (model.PageInfo, error) {
+	sort := recordSort(filter, "created_at")
+	keys, err := parseSort(sort, recordSortColumns, recordIdColumn)
+	if err != nil {
+		return model.PageInfo{}, err
+	}
+	query := ar.db.Joins("User").Preload("User.EmploymentType").Preload("Breaks").
+		Scopes(byClockInRange(filter)).Where("attendance_records.user_id = ?", userId)
+	return paginate(query, records, sort, keys, page)
 }
 
 func (ar *attendanceRecordRepository) GetRecordById(record *model.AttendanceRecord, userId uint, recordId uint) error {
-	if err := ar.db.Joins("User").Where("attendance_records.user_id = ? AND attendance_records.id = ?", userId, recordId).First(record).Error; err != nil {
+	if err := ar.db.Joins("User").Preload("Breaks").Where("attendance_records.user_id = ? AND attendance_records.id = ?", userId, recordId).First(record).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// CreateRecord は記録と打刻のドメインイベントを同じトランザクションで書き込む
 func (ar *attendanceRecordRepository) CreateRecord(record *model.AttendanceRecord) error {
-	if err := ar.db.Create(record).Error; err != nil {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Breaks").Create(record).Error; err != nil {
+			return err
+		}
+		return createOutboxEvent(tx, model.AttendanceCreateEvent(*record), "attendance_record", record.ID, model.NewAttendanceEventData(*record))
+	})
+}
+
+// UpdateRecord は更新前後の記録から退勤の打刻か修正かを判定し、ドメインイベントを同じトランザクションで書き込む
+func (ar *attendanceRecordRepository) UpdateRecord(record *model.AttendanceRecord, userId uint, recordId uint) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		current := model.AttendanceRecord{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", recordId, userId).First(&current).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return model.ErrNotFound
+			}
+			return err
+		}
+		if err := tx.Model(record).Omit("Breaks").Where("id = ? AND user_id = ?", recordId, userId).Updates(record).Error; err != nil {
+			return err
+		}
+		updated := model.AttendanceRecord{}
+		if err := tx.First(&updated, recordId).Error; err != nil {
+			return err
+		}
+		// 休憩中に退勤した場合は退勤時刻で休憩を終える
+		if updated.ClockOutTime.After(updated.ClockInTime) {
+			err := tx.Model(&model.AttendanceBreak{}).
+				Where("attendance_record_id = ? AND ended_at IS NULL", recordId).
+				Update("ended_at", updated.ClockOutTime).Error
+			if err != nil {
+				return err
+			}
+		}
+		return createOutboxEvent(tx, model.AttendanceUpdateEvent(current, updated), "attendance_record", recordId, model.NewAttendanceEventData(updated))
+	})
+}
+
+// DeleteRecord は削除を記録の修正としてドメインイベントに残す
+func (ar *attendanceRecordRepository) DeleteRecord(userId uint, recordId uint) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		record := model.AttendanceRecord{}
+		result := tx.Clauses(clause.Returning{}).Where("id = ? AND user_id = ?", recordId, userId).Delete(&record)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return model.ErrNotFound
+		}
+		data := model.NewAttendanceEventData(record)
+		data.Deleted = true
+		return createOutboxEvent(tx, model.EventAttendanceRecordCorrected, "attendance_record", recordId, data)
+	})
+}
+
+// lockOpenRecord は本人の退勤前の勤怠記録を更新用にロックして読み込む
+func lockOpenRecord(tx *gorm.DB, record *model.AttendanceRecord, userId uint, recordId uint) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", recordId, userId).First(record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrNotFound
+		}
 		return err
 	}
+	if record.ClockOutTime.After(record.ClockInTime) {
+		return model.NewConflictError("already_clocked_out", "attendance record %d is already clocked out", recordId)
+	}
 	return nil
 }
 
-func (ar *attendanceRecordRepository) UpdateRecord(record *model.AttendanceRecord, userId uint, recordId uint) error {
-	result := ar.db.Model(record).Where("id = ? AND user_id = ?", recordId, userId).Updates(record)
-	if result.Error != nil {
-		return result.Error
+// StartBreak は休憩の開始と休憩開始のドメインイベントを同じトランザクションで書き込む
+func (ar *attendanceRecordRepository) StartBreak(brk *model.AttendanceBreak, userId uint) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		record := model.AttendanceRecord{}
+		if err := lockOpenRecord(tx, &record, userId, brk.AttendanceRecordID); err != nil {
+			return err
+		}
+		var open int64
+		err := tx.Model(&model.AttendanceBreak{}).
+			Where("attendance_record_id = ? AND ended_at IS NULL", record.ID).Count(&open).Error
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return model.NewConflictError("already_on_break", "a break has already started")
+		}
+		if err := tx.Create(brk).Error; err != nil {
+			return err
+		}
+		return createOutboxEvent(tx, model.EventAttendanceBreakStart, "attendance_record", record.ID, model.AttendanceBreakEventData{
+			RecordID:  record.ID,
+			UserID:    userId,
+			BreakID:   brk.ID,
+			StartedAt: brk.StartedAt,
+		})
+	})
+}
+
+// EndBreak は進行中の休憩を終え、休憩終了のドメインイベントを同じトランザクションで書き込む
+func (ar *attendanceRecordRepository) EndBreak(brk *model.AttendanceBreak, userId uint, recordId uint, endedAt time.Time) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		record := model.AttendanceRecord{}
+		if err := lockOpenRecord(tx, &record, userId, recordId); err != nil {
+			return err
+		}
+		err := tx.Where("attendance_record_id = ? AND ended_at IS NULL", recordId).First(brk).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return model.NewConflictError("not_on_break", "no break is in progress")
+			}
+			return err
+		}
+		if endedAt.Before(brk.StartedAt) {
+			return model.NewInvalidError("invalid_break_end", "break end cannot be before break start")
+		}
+		if err := tx.Model(brk).Update("ended_at", endedAt).Error; err != nil {
+			return err
+		}
+		brk.EndedAt = &endedAt
+		return createOutboxEvent(tx, model.EventAttendanceBreakEnd, "attendance_record", recordId, model.AttendanceBreakEventData{
+			RecordID:  recordId,
+			UserID:    userId,
+			BreakID:   brk.ID,
+			StartedAt: brk.StartedAt,
+			EndedAt:   brk.EndedAt,
+		})
+	})
+}
+
+// workedMinutesSQL は勤怠記録 r の実働の分数（出勤から退勤までの時間から終了した休憩を除く）。
+// 退勤前の記録は 0 になる。AttendanceRecord.WorkedMinutes と同じ計算
+const workedMinutesSQL = `CASE WHEN r.clock_out_time > r.clock_in_time
+			THEN GREATEST(FLOOR((EXTRACT(EPOCH FROM r.clock_out_time - r.clock_in_time)
+				- COALESCE((SELECT SUM(EXTRACT(EPOCH FROM b.ended_at - b.started_at)) FROM attendance_breaks b
+					WHERE b.attendance_record_id = r.id AND b.ended_at > b.started_at), 0)) / 60), 0)
+			ELSE 0 END`
+
+// employeeStatsQuery は部署の従業員ごとに期間内（from 以上 to 未満）の勤怠を集計する。
+// 実働は出勤から退勤までの時間から休憩を除いたもの。遅刻は雇用区分の始業時刻で判定する。
+// 残業は日ごとの時間外しきい値を超えた分に、週（月曜始まり）の実働から日ごとの残業を除いた時間が週のしきい値を超えた分を加える。
+// 期間の境界をまたぐ週は期間内の記録だけで判定する。
+// 欠勤は在籍期間中の平日（今日まで、会社の休日を除く）のうち出勤記録も休暇もない日数。
+// 休暇の取得日数は期間内の休暇の合計（半休は 0.5 日）
+const employeeStatsQuery = `
+WITH days AS (
+	SELECT d::date AS day FROM generate_series(CAST(@from AS date), CAST(@to AS date) - 1, INTERVAL '1 day') AS d
+	WHERE EXTRACT(ISODOW FROM d) < 6 AND d::date <= CURRENT_DATE
+	AND NOT EXISTS (SELECT 1 FROM holidays h WHERE h.date = d::date)
+),
+records AS (
+	SELECT r.user_id, r.clock_in_time, r.clock_out_time, DATE_TRUNC('week', r.clock_in_time) AS week,
+		` + workedMinutesSQL + ` AS worked,
+		COALESCE(NULLIF(et.overtime_threshold_minutes, 0), @threshold) AS daily_threshold,
+		COALESCE(NULLIF(et.weekly_overtime_threshold, 0), @weekly_threshold) AS weekly_threshold
+	FROM attendance_records r
+	JOIN users ru ON ru.id = r.user_id
+	LEFT JOIN employment_types et ON et.id = ru.employment_type_id
+	WHERE r.clock_in_time >= @from AND r.clock_in_time < @to
+),
+weekly_overtime AS (
+	SELECT user_id, SUM(minutes) AS minutes FROM (
+		SELECT user_id, GREATEST(SUM(worked) - SUM(GREATEST(worked - daily_threshold, 0)) - MAX(weekly_threshold), 0) AS minutes
+		FROM records GROUP BY user_id, week
+	) w GROUP BY user_id
+)
+SELECT u.id AS user_id, u.name AS name, COALESCE(et.code, '') AS employment_type,
+	COUNT(r.user_id) AS days_worked,
+	COALESCE(SUM(r.worked), 0) AS worked_minutes,
+	COALESCE(SUM(GREATEST(r.worked - r.daily_threshold, 0)), 0) + COALESCE(MAX(wo.minutes), 0) AS overtime_minutes,
+	COUNT(r.user_id) FILTER (WHERE r.clock_in_time::time > COALESCE(NULLIF(et.scheduled_start_time, ''), @start)::time) AS late_count,
+	(SELECT COUNT(*) FROM days
+		WHERE (u.hire_date IS NULL OR days.day >= u.hire_date::date)
+		AND (u.termination_date IS NULL OR days.day <= u.termination_date::date)
+		AND NOT EXISTS (SELECT 1 FROM attendance_records a
+			WHERE a.user_id = u.id AND a.clock_in_time >= days.day AND a.clock_in_time < days.day + 1)
+		AND NOT EXISTS (SELECT 1 FROM leave_records l WHERE l.user_id = u.id AND l.date = days.day)) AS absence_count,
+	(SELECT COALESCE(SUM(l.days), 0) FROM leave_records l
+		WHERE l.user_id = u.id AND l.date >= CAST(@from AS date) AND l.date < CAST(@to AS date)) AS leave_used_days,
+	AVG(EXTRACT(EPOCH FROM r.clock_in_time::time)) AS avg_clock_in_seconds,
+	AVG(EXTRACT(EPOCH FROM r.clock_out_time::time)) FILTER (WHERE r.clock_out_time > r.clock_in_time) AS avg_clock_out_seconds
+FROM users u
+LEFT JOIN employment_types et ON et.id = u.employment_type_id
+LEFT JOIN records r ON r.user_id = u.id
+LEFT JOIN weekly_overtime wo ON wo.user_id = u.id
+WHERE u.department = @department AND u.deleted_at IS NULL
+GROUP BY u.id, u.name, et.code, et.scheduled_start_time`
+
+func statsParams(department string, from time.Time, to time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"department":       department,
+		"from":             from,
+		"to":               to,
+		"threshold":        model.DefaultOvertimeThresholdMinutes,
+		"weekly_threshold": model.DefaultWeeklyOvertimeThresholdMinutes,
+		"start":            model.DefaultScheduledStartTime,
 	}
-	if result.RowsAffected < 1 {
-		return fmt.Errorf("object does not exist")
+}
+
+func (ar *attendanceRecordRepository) GetEmployeeStats(stats *[]model.EmployeeAttendanceStats, department string, from time.Time, to time.Time) error {
+	err := ar.db.Raw(employeeStatsQuery+" ORDER BY u.name, u.id", statsParams(department, from, to)).Scan(stats).Error
+	if err != nil {
+		return err
 	}
 	return nil
 }
 
-func (ar *attendanceRecordRepository) DeleteRecord(userId uint, recordId uint) error {
-	result := ar.db.Where("id = ? AND user_id = ?", recordId, userId).Delete(&model.AttendanceRecord{})
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected < 1 {
-		return fmt.Errorf("object does not exist")
+// GetDepartmentStats は従業員ごとの集計をさらに部署単位でまとめる。平均時刻は全出勤記録の平均
+func (ar *attendanceRecordRepository) GetDepartmentStats(stats *model.DepartmentAttendanceStats, department string, from time.Time, to time.Time) error {
+	query := `
+WITH emp AS (` + employeeStatsQuery + `)
+SELECT COUNT(*) AS headcount,
+	COALESCE(SUM(days_worked), 0) AS days_worked,
+	COALESCE(SUM(worked_minutes), 0) AS worked_minutes,
+	COALESCE(SUM(overtime_minutes), 0) AS overtime_minutes,
+	COALESCE(SUM(late_count), 0) AS late_count,
+	COALESCE(SUM(absence_count), 0) AS absence_count,
+	COALESCE(SUM(leave_used_days), 0) AS leave_used_days,
+	SUM(worked_minutes)::float / NULLIF(SUM(days_worked), 0) AS avg_worked_minutes,
+	SUM(avg_clock_in_seconds * days_worked) / NULLIF(SUM(days_worked) FILTER (WHERE avg_clock_in_seconds IS NOT NULL), 0) AS avg_clock_in_seconds,
+	(SELECT AVG(EXTRACT(EPOCH FROM r.clock_out_time::time)) FROM attendance_records r
+		JOIN users u ON u.id = r.user_id
+		WHERE u.department = @department AND u.deleted_at IS NULL
+		AND r.clock_in_time >= @from AND r.clock_in_time < @to AND r.clock_out_time > r.clock_in_time) AS avg_clock_out_seconds
+FROM emp`
+	if err := ar.db.Raw(query, statsParams(department, from, to)).Scan(stats).Error; err != nil {
+		return err
 	}
 	return nil
 }
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITimesheetRepository interface {
+	GetTimesheetsByUser(timesheets *[]model.Timesheet, userId uint) error
+	GetTimesheetById(timesheet *model.Timesheet, timesheetId uint) error
+	GetTimesheetByWeek(timesheet *model.Timesheet, userId uint, weekStart time.Time) error
+	GetSubmittedForLead(timesheets *[]model.Timesheet, leadId uint) error
+	CountLeadProjects(timesheet model.Timesheet, leadId uint) (int64, error)
+	GetLeadIds(leadIds *[]uint, timesheet model.Timesheet) error
+	SubmitTimesheet(timesheet *model.Timesheet) error
+	ReviewTimesheet(timesheet *model.Timesheet, timesheetId uint) error
+	ApproveAsLead(timesheet *model.Timesheet, timesheetId uint, approval model.TimesheetApproval, leadIds []uint) error
+}
+
+type timesheetRepository struct {
+	db *gorm.DB
+}
+
+func NewTimesheetRepository(db *gorm.DB) ITimesheetRepository {
+	return &timesheetRepository{db}
+}
+
+// leadProjectEntries はプロジェクトリーダーが担当するプロジェクトの作業時間が、タイムシートの週に含まれる条件
+func leadProjectEntries(db *gorm.DB, leadId uint) *gorm.DB {
+	return db.Table("time_entries").Select("1").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id").
+		Joins("JOIN projects ON projects.id = tasks.project_id").
+		Where("projects.lead_id = ?", leadId).
+		Where("time_entries.user_id = timesheets.user_id").
+		Where("time_entries.started_at >= timesheets.week_start AND time_entries.started_at < timesheets.week_start + INTERVAL '7 days'")
+}
+
+func (tsr *timesheetRepository) GetTimesheetsByUser(timesheets *[]model.Timesheet, userId uint) error {
+	if err := tsr.db.Where("user_id = ?", userId).Order("week_start DESC").Find(timesheets).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tsr *timesheetRepository) GetTimesheetById(timesheet *model.Timesheet, timesheetId uint) error {
+	if err := tsr.db.Preload("User").Preload("Approvals").First(timesheet, timesheetId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tsr *timesheetRepository) GetTimesheetByWeek(timesheet *model.Timesheet, userId uint, weekStart time.Time) error {
+	if err := tsr.db.Where("user_id = ? AND week_start = ?", userId, weekStart).First(timesheet).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tsr *timesheetRepository) GetSubmittedForLead(timesheets *[]model.Timesheet, leadId uint) error {
+	err := tsr.db.Preload("User").
+		Where("status = ?", model.TimesheetStatusSubmitted).
+		Where("EXISTS (?)", leadProjectEntries(tsr.db, leadId)).
+		Where("NOT EXISTS (?)", tsr.db.Table("timesheet_approvals").Select("1").
+			Where("timesheet_approvals.timesheet_id = timesheets.id AND timesheet_approvals.lead_id = ?", leadId)).
+		Order("week_start, user_id").Find(timesheets).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tsr *timesheetRepository) CountLeadProjects(timesheet model.Timesheet, leadId uint) (int64, error) {
+	var count int64
+	err := tsr.db.Model(&model.Timesheet{}).
+		Where("id = ?", timesheet.ID).
+		Where("EXISTS (?)", leadProjectEntries(tsr.db, leadId)).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetLeadIds はタイムシートの週に作業時間のあるプロジェクトのリーダー（本人を除く）を返す
+func (tsr *timesheetRepository) GetLeadIds(leadIds *[]uint, timesheet model.Timesheet) error {
+	err := tsr.db.Table("time_entries").Distinct("projects.lead_id").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id").
+		Joins("JOIN projects ON projects.id = tasks.project_id").
+		Where("time_entries.user_id = ?", timesheet.UserID).
+		Where("time_entries.started_at >= ? AND time_entries.started_at < ?", timesheet.WeekStart, timesheet.WeekStart.AddDate(0, 0, 7)).
+		Where("projects.lead_id IS NOT NULL AND projects.lead_id <> ?", timesheet.UserID).
+		Pluck("projects.lead_id", leadIds).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SubmitTimesheet は未提出なら作成し、差し戻し済みなら再提出として上書きする
+func (tsr *timesheetRepository) SubmitTimesheet(timesheet *model.Timesheet) error {
+	result := tsr.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "week_start"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":        timesheet.Status,
+			"total_minutes": timesheet.TotalMinutes,
+			"submitted_at":  timesheet.SubmittedAt,
+			"reviewed_by":   nil,
+			"reviewed_at":   nil,
+			"updated_at":    time.Now(),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: "timesheets", Name: "status"}, Value: model.TimesheetStatusRejected},
+		}},
+	}).Create(timesheet)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.NewConflictError("timesheet_submitted", "timesheet has already been submitted")
+	}
+	return tsr.db.Where("user_id = ? AND week_start = ?", timesheet.UserID, timesheet.WeekStart).First(timesheet).Error
+}
+
+// ReviewTimesheet は提出中のタイムシートだけを承認・差し戻しする。差し戻しではリーダーごとの承認も取り消す
+func (tsr *timesheetRepository) ReviewTimesheet(timesheet *model.Timesheet, timesheetId uint) error {
+	return tsr.db.Transaction(func(tx *gorm.DB) error {
+		if timesheet.Status == model.TimesheetStatusRejected {
+			if err := tx.Where("timesheet_id = ?", timesheetId).Delete(&model.TimesheetApproval{}).Error; err != nil {
+				return err
+			}
+		}
+		return reviewTimesheet(tx, timesheet, timesheetId)
+	})
+}
+
+// ApproveAsLead はリーダーの承認を記録し、leadIds の全員がそろったときにタイムシートを承認済みにする。
+// そろっていなければ timesheet は提出中のまま読み直す
+func (tsr *timesheetRepository) ApproveAsLead(timesheet *model.Timesheet, timesheetId uint, approval model.TimesheetApproval, leadIds []uint) error {
+	return tsr.db.Transaction(func(tx *gorm.DB) error {
+		current := model.Timesheet{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", timesheetId, model.TimesheetStatusSubmitted).First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.NewConflictError("timesheet_not_submitted", "timesheet is not awaiting review")
+		}
+		if err != nil {
+			return err
+		}
+		approval.TimesheetID = timesheetId
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&approval).Error; err != nil {
+			return err
+		}
+		var approved int64
+		err = tx.Model(&model.TimesheetApproval{}).
+			Where("timesheet_id = ? AND lead_id IN ?", timesheetId, leadIds).Count(&approved).Error
+		if err != nil {
+			return err
+		}
+		if approved < int64(len(leadIds)) {
+			*timesheet = model.Timesheet{}
+			return tx.Preload("User").Preload("Approvals").First(timesheet, timesheetId).Error
+		}
+		return reviewTimesheet(tx, timesheet, timesheetId)
+	})
+}
+
+// reviewTimesheet は提出中のタイムシートの状態を更新する。承認で週が締まるため、
+// 期間の締めのドメインイベントを同じトランザクションで書き込む
+func reviewTimesheet(tx *gorm.DB, timesheet *model.Timesheet, timesheetId uint) error {
+	result := tx.Model(&model.Timesheet{}).
+		Where("id = ? AND status = ?", timesheetId, model.TimesheetStatusSubmitted).
+		Select("status", "reviewed_by", "reviewed_at", "comment").Updates(timesheet)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.NewConflictError("timesheet_not_submitted", "timesheet is not awaiting review")
+	}
+	*timesheet = model.Timesheet{}
+	if err := tx.Preload("User").Preload("Approvals").First(timesheet, timesheetId).Error; err != nil {
+		return err
+	}
+	if timesheet.Status != model.TimesheetStatusApproved {
+		return nil
+	}
+	return createOutboxEvent(tx, model.EventPeriodLocked, "timesheet", timesheet.ID, model.PeriodEventData{
+		UserID:      timesheet.UserID,
+		TimesheetID: timesheet.ID,
+		PeriodStart: timesheet.WeekStart,
+		PeriodEnd:   timesheet.WeekStart.AddDate(0, 0, 7),
+	})
+}
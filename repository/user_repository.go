@@ -1,16 +1,34 @@
 package repository
 
 import (
+	"fmt"
 	"go-rest-api/model"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type IUserRepository interface {
 	GetUserByEmail(user *model.User, email string) error
+	GetUserById(user *model.User, userId uint) error
+	GetUserByIdUnscoped(user *model.User, userId uint) error
+	FindUsers(users *[]model.User, email string, externalId string, offset int, limit int) (int64, error)
+	CountUsersByEmail(email string) (int64, error)
+	CountOtherUsersByEmail(email string, userId uint) (int64, error)
 	CreateUser(user *model.User) error
 	UpdateUser(user *model.User) error
+	UpdateProfile(user *model.User) error
+	UpdateEmployment(userId uint, hireDate *time.Time, terminationDate *time.Time) error
+	SetDeactivatedAt(userId uint, deactivatedAt *time.Time) error
+	SetEmploymentType(userId uint, employmentTypeId *uint) error
+	SetRole(userId uint, role string) error
+	GetDepartments(departments *[]string, name string) error
+	GetUsersByDepartment(users *[]model.User, department string) error
+	SetDepartment(userIds []uint, department string) error
+	ClearDepartment(department string, userIds []uint) error
 	DeleteUser(user *model.User) error
+	PurgeUsers(cutoff time.Time) (int64, error)
+	AnonymizeUser(userId uint, erasedAt time.Time) error
 }
 
 type userRepository struct {
@@ -28,6 +46,55 @@ func (ur *userRepository) GetUserByEmail(user *model.User, email string) error {
 	return nil
 }
 
+func (ur *userRepository) GetUserById(user *model.User, userId uint) error {
+	if err := ur.db.Preload("EmploymentType").First(user, userId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ur *userRepository) GetUserByIdUnscoped(user *model.User, userId uint) error {
+	if err := ur.db.Unscoped().Preload("EmploymentType").First(user, userId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ur *userRepository) FindUsers(users *[]model.User, email string, externalId string, offset int, limit int) (int64, error) {
+	query := ur.db.Model(&model.User{})
+	if email != "" {
+		query = query.Where("email = ?", email)
+	}
+	if externalId != "" {
+		query = query.Where("external_id = ?", externalId)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	if err := query.Order("id").Offset(offset).Limit(limit).Find(users).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (ur *userRepository) CountUsersByEmail(email string) (int64, error) {
+	var count int64
+	if err := ur.db.Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountOtherUsersByEmail は指定したユーザー以外で同じメールアドレスを使っているユーザーの数を返す
+func (ur *userRepository) CountOtherUsersByEmail(email string, userId uint) (int64, error) {
+	var count int64
+	if err := ur.db.Model(&model.User{}).Where("email = ? AND id <> ?", email, userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (ur *userRepository) CreateUser(user *model.User) error {
 	if err := ur.db.Create(user).Error; err != nil {
 		return err
@@ -36,15 +103,228 @@ func (ur *userRepository) CreateUser(user *model.User) error {
 }
 
 func (ur *userRepository) UpdateUser(user *model.User) error {
-	if err := ur.db.Save(user).Error; err != nil {
+	// 在籍情報など他のカラムを上書きしないよう、プロフィール項目のみ更新する
+	result := ur.db.Model(user).Select("email", "password", "name", "department").Updates(user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) UpdateProfile(user *model.User) error {
+	result := ur.db.Model(user).Select("email", "name", "department", "external_id").Updates(user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) UpdateEmployment(userId uint, hireDate *time.Time, terminationDate *time.Time) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).
+		Updates(map[string]interface{}{"hire_date": hireDate, "termination_date": terminationDate})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) SetDeactivatedAt(userId uint, deactivatedAt *time.Time) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).Update("deactivated_at", deactivatedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) SetEmploymentType(userId uint, employmentTypeId *uint) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).Update("employment_type_id", employmentTypeId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) SetRole(userId uint, role string) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) GetDepartments(departments *[]string, name string) error {
+	query := ur.db.Model(&model.User{}).Where("department <> ''")
+	if name != "" {
+		query = query.Where("department = ?", name)
+	}
+	if err := query.Distinct().Order("department").Pluck("department", departments).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ur *userRepository) GetUsersByDepartment(users *[]model.User, department string) error {
+	if err := ur.db.Where("department = ?", department).Order("id").Find(users).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ur *userRepository) SetDepartment(userIds []uint, department string) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	if err := ur.db.Model(&model.User{}).Where("id IN ?", userIds).Update("department", department).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ur *userRepository) ClearDepartment(department string, userIds []uint) error {
+	// userIds が nil の場合は部署の全メンバーを外す
+	query := ur.db.Model(&model.User{}).Where("department = ?", department)
+	if userIds != nil {
+		if len(userIds) == 0 {
+			return nil
+		}
+		query = query.Where("id IN ?", userIds)
+	}
+	if err := query.Update("department", "").Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 func (ur *userRepository) DeleteUser(user *model.User) error {
+	// DeletedAt による論理削除。勤怠記録は法定保存期間のため残す
 	if err := ur.db.Delete(user).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+func (ur *userRepository) PurgeUsers(cutoff time.Time) (int64, error) {
+	// 無効化または削除済みで、退職日（なければ削除日）が保存期間を過ぎたユーザーと関連データを物理削除する
+	var purged int64
+	err := ur.db.Transaction(func(tx *gorm.DB) error {
+		var userIds []uint
+		if err := tx.Unscoped().Model(&model.User{}).
+			Where("(deactivated_at IS NOT NULL OR deleted_at IS NOT NULL)").
+			Where("COALESCE(termination_date, deleted_at) < ?", cutoff).
+			Pluck("id", &userIds).Error; err != nil {
+			return err
+		}
+		if len(userIds) == 0 {
+			return nil
+		}
+		if err := tx.Where("user_id IN ?", userIds).Delete(&model.AttendanceRecord{}).Error; err != nil {
+			return err
+		}
+		// 部署ボードのタスクと、他のメンバーが工数を記録したタスクは、削除すると他のメンバーの工数
+		// （承認済みの週を含む）まで消えるため残す。その持ち主はユーザーごと削除せず仮名化する
+		var retainedIds []uint
+		err := tx.Model(&model.Task{}).Distinct("user_id").
+			Where("user_id IN ?", userIds).
+			Where("visibility <> ? OR EXISTS (SELECT 1 FROM time_entries te WHERE te.task_id = tasks.id AND te.user_id NOT IN ?)",
+				model.TaskVisibilityPrivate, userIds).
+			Pluck("user_id", &retainedIds).Error
+		if err != nil {
+			return err
+		}
+		if len(retainedIds) > 0 {
+			var pending []uint
+			if err := tx.Unscoped().Model(&model.User{}).Where("id IN ? AND erased_at IS NULL", retainedIds).Pluck("id", &pending).Error; err != nil {
+				return err
+			}
+			for _, id := range pending {
+				if err := anonymizeUser(tx, id, time.Now()); err != nil {
+					return err
+				}
+			}
+		}
+		purgeIds := []uint{}
+		retained := map[uint]bool{}
+		for _, id := range retainedIds {
+			retained[id] = true
+		}
+		for _, id := range userIds {
+			if !retained[id] {
+				purgeIds = append(purgeIds, id)
+			}
+		}
+		if len(purgeIds) == 0 {
+			return nil
+		}
+		if err := tx.Where("user_id IN ? OR manager_id IN ?", purgeIds, purgeIds).Delete(&model.ReportingLine{}).Error; err != nil {
+			return err
+		}
+		// タスクと本人の工数などはユーザーの削除に連動して削除される
+		result := tx.Unscoped().Where("id IN ?", purgeIds).Delete(&model.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+func (ur *userRepository) AnonymizeUser(userId uint, erasedAt time.Time) error {
+	// 勤怠記録は保存義務があるため残し、本人を特定できる項目のみ仮名化する
+	return ur.db.Transaction(func(tx *gorm.DB) error {
+		return anonymizeUser(tx, userId, erasedAt)
+	})
+}
+
+// anonymizeUser は本人を特定できる項目を仮名化し、無効化する
+func anonymizeUser(tx *gorm.DB, userId uint, erasedAt time.Time) error {
+	user := model.User{}
+	if err := tx.Unscoped().First(&user, userId).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ? OR email = ?", userId, user.Email).Delete(&model.Invitation{}).Error; err != nil {
+		return err
+	}
+	// タスクは工数や部署ボードで他のメンバーも参照するため削除せず、個人用のタスクの内容だけを仮名化する
+	err := tx.Model(&model.Task{}).Where("user_id = ? AND visibility = ?", userId, model.TaskVisibilityPrivate).
+		Updates(map[string]interface{}{"title": gorm.Expr("'erased-task-' || id"), "description": ""}).Error
+	if err != nil {
+		return err
+	}
+	// 通知の本文や Webhook の送信先には個人を特定できる情報が含まれうるため削除する
+	if err := tx.Where("user_id = ?", userId).Delete(&model.Notification{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userId).Delete(&model.NotificationSetting{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(&model.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+		"email":          fmt.Sprintf("erased-%d@invalid.example", userId),
+		"name":           fmt.Sprintf("erased-user-%d", userId),
+		"password":       "",
+		"external_id":    "",
+		"deactivated_at": erasedAt,
+		"erased_at":      erasedAt,
+	}).Error
+}
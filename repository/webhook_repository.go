@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWebhookRepository interface {
+	GetSubscriptions(subscriptions *[]model.WebhookSubscription) error
+	GetActiveSubscriptions(subscriptions *[]model.WebhookSubscription) error
+	GetSubscriptionById(subscription *model.WebhookSubscription, subscriptionId uint) error
+	CreateSubscription(subscription *model.WebhookSubscription) error
+	UpdateSubscription(subscription *model.WebhookSubscription, subscriptionId uint, updateActive bool) error
+	DeleteSubscription(subscriptionId uint) error
+	GetDeliveries(deliveries *[]model.WebhookDelivery, subscriptionId uint, limit int) error
+	GetDeliveryById(delivery *model.WebhookDelivery, deliveryId uint) error
+	GetDueDeliveries(deliveries *[]model.WebhookDelivery, now time.Time, limit int) error
+	CreateDeliveries(deliveries []model.WebhookDelivery) error
+	UpdateDelivery(delivery *model.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) IWebhookRepository {
+	return &webhookRepository{db}
+}
+
+func (wr *webhookRepository) GetSubscriptions(subscriptions *[]model.WebhookSubscription) error {
+	if err := wr.db.Order("id").Find(subscriptions).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *webhookRepository) GetActiveSubscriptions(subscriptions *[]model.WebhookSubscription) error {
+	if err := wr.db.Where("active = ?", true).Order("id").Find(subscriptions).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *webhookRepository) GetSubscriptionById(subscription *model.WebhookSubscription, subscriptionId uint) error {
+	if err := wr.db.First(subscription, subscriptionId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *webhookRepository) CreateSubscription(subscription *model.WebhookSubscription) error {
+	if err := wr.db.Create(subscription).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateSubscription は updateActive が false の場合、有効・無効の状態を変更しない
+func (wr *webhookRepository) UpdateSubscription(subscription *model.WebhookSubscription, subscriptionId uint, updateActive bool) error {
+	columns := []interface{}{"description", "events"}
+	if updateActive {
+		columns = append(columns, "active")
+	}
+	result := wr.db.Model(subscription).Clauses(clause.Returning{}).
+		Where("id = ?", subscriptionId).
+		Select("url", columns...).
+		Updates(subscription)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (wr *webhookRepository) DeleteSubscription(subscriptionId uint) error {
+	result := wr.db.Delete(&model.WebhookSubscription{}, subscriptionId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (wr *webhookRepository) GetDeliveries(deliveries *[]model.WebhookDelivery, subscriptionId uint, limit int) error {
+	err := wr.db.Where("subscription_id = ?", subscriptionId).
+		Order("id DESC").Limit(limit).Find(deliveries).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *webhookRepository) GetDeliveryById(delivery *model.WebhookDelivery, deliveryId uint) error {
+	if err := wr.db.Preload("Subscription").First(delivery, deliveryId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetDueDeliveries は送信時刻を迎えた未送信の配信を古い順に返す
+func (wr *webhookRepository) GetDueDeliveries(deliveries *[]model.WebhookDelivery, now time.Time, limit int) error {
+	err := wr.db.Preload("Subscription").
+		Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+		Order("next_attempt_at, id").Limit(limit).Find(deliveries).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateDeliveries は同じイベント・送信先の配信が登録済みなら作成しない
+func (wr *webhookRepository) CreateDeliveries(deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := wr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *webhookRepository) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	err := wr.db.Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "delivered_at").
+		Updates(delivery).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
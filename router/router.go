@@ -1,17 +1,21 @@
 package router
 
 import (
+	"crypto/subtle"
 	"go-rest-api/controller"
+	"go-rest-api/model"
 	"net/http"
 	"os"
+	"strings"
 
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func NewRouter(uc controller.IUserController, auc controller.IAuthUserController, tc controller.ITaskController, arc controller.IAttendanceRecordController) *echo.Echo {
+func NewRouter(uc controller.IUserController, auc controller.IAuthUserController, tc controller.ITaskController, arc controller.IAttendanceRecordController, rlc controller.IReportingLineController, etc controller.IEmploymentTypeController, ic controller.IInvitationController, sc controller.IScimController, pc controller.IPrivacyController, prc controller.IProjectController, tac controller.ITimeAllocationController, tec controller.ITimeEntryController, bc controller.IBillingController, tsc controller.ITimesheetController, tcc controller.ITaskActivityController, trc controller.ITaskRecurrenceController, psc controller.IPresenceController, aec controller.IAttendanceExceptionController, nc controller.INotificationController, wc controller.IWebhookController, jc controller.IJobController, lc controller.ILeaveController, hwc controller.IHolidayWorkRequestController, am controller.IAuthMiddleware) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = controller.HTTPErrorHandler
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"http://localhost:3000", os.Getenv("FE_URL")},
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept,
@@ -20,6 +24,10 @@ func NewRouter(uc controller.IUserController, auc controller.IAuthUserController
 		AllowCredentials: true,
 	}))
 	e.Use(middleware.CSRFWithConfig(middleware.CSRFConfig{
+		// SCIM は IdP からのサーバー間通信で Bearer トークン認証のため CSRF 対象外
+		Skipper: func(c echo.Context) bool {
+			return strings.HasPrefix(c.Request().URL.Path, "/scim/")
+		},
 		CookiePath:     "/",
 		CookieDomain:   os.Getenv("API_DOMAIN"),
 		CookieHTTPOnly: true,
@@ -34,6 +42,8 @@ func NewRouter(uc controller.IUserController, auc controller.IAuthUserController
 	e.PUT("/update-user", uc.UpdateUser)
 	e.DELETE("/delete-user", uc.DeleteUser)
 
+	e.POST("/invitations/accept", ic.AcceptInvitation)
+
 	e.POST("/auth/signup", auc.SignUp)
 	e.POST("/auth/login", auc.LogIn)
 	e.POST("/auth/logout", auc.LogOut)
@@ -43,18 +53,68 @@ func NewRouter(uc controller.IUserController, auc controller.IAuthUserController
 	t.Use(echojwt.WithConfig(echojwt.Config{
 		SigningKey:  []byte(os.Getenv("SECRET")),
 		TokenLookup: "cookie:token",
-	}))
+	}), am.RequireActive)
 	t.GET("", tc.GetAllTasks)
 	t.GET("/:taskId", tc.GetTaskById)
 	t.POST("", tc.CreateTask)
 	t.PUT("/:taskId", tc.UpdateTask)
 	t.DELETE("/:taskId", tc.DeleteTask)
+	t.GET("/timer", tec.GetRunningTimer)
+	t.POST("/:taskId/timer", tec.StartTimer)
+	t.DELETE("/:taskId/timer", tec.StopTimer)
+	t.POST("/:taskId/claim", tc.ClaimTask)
+	t.GET("/:taskId/activity", tcc.GetActivity)
+	t.GET("/:taskId/comments", tcc.GetComments)
+	t.POST("/:taskId/comments", tcc.CreateComment)
+	t.PUT("/:taskId/comments/:commentId", tcc.UpdateComment)
+	t.DELETE("/:taskId/comments/:commentId", tcc.DeleteComment)
+
+	rc := e.Group("/recurrences")
+	rc.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	rc.GET("", trc.GetRecurrences)
+	rc.GET("/:recurrenceId", trc.GetRecurrenceById)
+	rc.POST("", trc.CreateRecurrence)
+	rc.PUT("/:recurrenceId", trc.UpdateRecurrence)
+	rc.DELETE("/:recurrenceId", trc.DeleteRecurrence)
+
+	b := e.Group("/boards")
+	b.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	b.GET("/tasks", tc.GetBoardTasks)
+	b.POST("/tasks", tc.CreateBoardTask)
+
+	te := e.Group("/time-entries")
+	te.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	te.GET("", tec.GetEntries)
+	te.POST("", tec.CreateEntry)
+	te.DELETE("/:entryId", tec.DeleteEntry)
+	te.PUT("/:entryId/billable", tec.SetBillable)
+
+	ts := e.Group("/timesheets")
+	ts.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	ts.GET("", tsc.GetMyTimesheets)
+	ts.GET("/review", tsc.GetTimesheetsForReview)
+	ts.GET("/:timesheetId", tsc.GetTimesheetById)
+	ts.POST("", tsc.SubmitTimesheet)
+	ts.PUT("/:timesheetId/approve", tsc.ApproveTimesheet)
+	ts.PUT("/:timesheetId/reject", tsc.RejectTimesheet)
 
 	ar := e.Group("/attendance-records")
 	ar.Use(echojwt.WithConfig(echojwt.Config{
 		SigningKey:  []byte(os.Getenv("SECRET")),
 		TokenLookup: "cookie:token",
-	}))
+	}), am.RequireActive)
 
 	ar.GET("", arc.GetAllRecords)
 	ar.GET("/:recordId", arc.GetRecordById)
@@ -63,6 +123,10 @@ func NewRouter(uc controller.IUserController, auc controller.IAuthUserController
 	ar.POST("", arc.CreateRecord)
 	ar.POST("/clock-in", arc.ClockIn)
 	ar.POST("/clock-out", arc.ClockOut)
+	ar.POST("/break-start", arc.BreakStart)
+	ar.POST("/break-end", arc.BreakEnd)
+	ar.GET("/:recordId/allocations", tac.GetDayAllocations)
+	ar.PUT("/:recordId/allocations", tac.ReplaceDayAllocations)
 	ar.PUT("/:recordId", arc.UpdateRecord)
 	ar.DELETE("/:recordId", arc.DeleteRecord)
 
@@ -70,11 +134,209 @@ func NewRouter(uc controller.IUserController, auc controller.IAuthUserController
 	ar2.Use(echojwt.WithConfig(echojwt.Config{
 		SigningKey:  []byte(os.Getenv("SECRET")),
 		TokenLookup: "cookie:token",
-	}))
+	}), am.RequireActive)
 	ar2.GET("/date", arc.GetRecordsByDate)
 	ar2.GET("/department", arc.GetRecordsByDepartment)
 	ar2.GET("/date-department", arc.GetRecordsByDateDepartment)
 	ar2.GET("/users", arc.GetAllUsers)
+	ar2.GET("/dashboard", arc.GetDepartmentDashboard)
+
+	au := e.Group("/admin/users")
+	au.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	au.Use(am.RequireRole(model.RoleAdmin))
+	au.PUT("/:userId/employment", uc.UpdateEmployment)
+	au.PUT("/:userId/employment-type", uc.SetEmploymentType)
+	au.PUT("/:userId/role", uc.SetRole)
+	au.PUT("/:userId/deactivate", uc.DeactivateUser)
+	au.PUT("/:userId/reactivate", uc.ReactivateUser)
+	au.POST("/purge", uc.PurgeUsers)
+	au.POST("/import", ic.ImportUsers)
+	au.GET("/:userId/export", pc.ExportUserData)
+	au.POST("/:userId/erase", pc.EraseUserData)
+
+	me := e.Group("/me")
+	me.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	me.GET("/export", pc.ExportMyData)
+
+	inv := e.Group("/admin/invitations")
+	inv.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	inv.Use(am.RequireRole(model.RoleAdmin))
+	inv.GET("", ic.GetPendingInvitations)
+	inv.POST("", ic.CreateInvitation)
+	inv.POST("/:invitationId/resend", ic.ResendInvitation)
+	inv.DELETE("/:invitationId", ic.CancelInvitation)
+
+	scim := e.Group("/scim/v2")
+	scim.Use(middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		Validator: func(key string, c echo.Context) (bool, error) {
+			token := os.Getenv("SCIM_TOKEN")
+			return token != "" && subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+		},
+	}))
+	scim.GET("/Users", sc.ListUsers)
+	scim.GET("/Users/:userId", sc.GetUser)
+	scim.POST("/Users", sc.CreateUser)
+	scim.PUT("/Users/:userId", sc.ReplaceUser)
+	scim.PATCH("/Users/:userId", sc.PatchUser)
+	scim.DELETE("/Users/:userId", sc.DeleteUser)
+	scim.GET("/Groups", sc.ListGroups)
+	scim.GET("/Groups/:groupId", sc.GetGroup)
+	scim.POST("/Groups", sc.CreateGroup)
+	scim.PUT("/Groups/:groupId", sc.ReplaceGroup)
+	scim.PATCH("/Groups/:groupId", sc.PatchGroup)
+	scim.DELETE("/Groups/:groupId", sc.DeleteGroup)
+
+	et := e.Group("/employment-types")
+	et.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	et.GET("", etc.GetAllEmploymentTypes)
+	et.GET("/:typeId", etc.GetEmploymentTypeById)
+	// 所定時間・残業のしきい値・休暇の付与日数は給与計算に使うため、変更は管理者のみ
+	et.POST("", etc.CreateEmploymentType, am.RequireRole(model.RoleAdmin))
+	et.PUT("/:typeId", etc.UpdateEmploymentType, am.RequireRole(model.RoleAdmin))
+	et.DELETE("/:typeId", etc.DeleteEmploymentType, am.RequireRole(model.RoleAdmin))
+
+	pj := e.Group("/projects")
+	pj.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	pj.GET("", prc.GetAllProjects)
+	pj.GET("/:projectId", prc.GetProjectById)
+	// 予算時間やリーダー（タイムシートの承認者）を決めるため、プロジェクトの変更は管理者のみ
+	pj.POST("", prc.CreateProject, am.RequireRole(model.RoleAdmin))
+	pj.PUT("/:projectId", prc.UpdateProject, am.RequireRole(model.RoleAdmin))
+	pj.DELETE("/:projectId", prc.DeleteProject, am.RequireRole(model.RoleAdmin))
+	// 単価と請求ロールは請求書の金額に直結するため管理者のみ
+	pj.PUT("/:projectId/rates", prc.ReplaceRates, am.RequireRole(model.RoleAdmin))
+	pj.PUT("/:projectId/members", prc.ReplaceMembers, am.RequireRole(model.RoleAdmin))
+	pj.GET("/:projectId/burndown", prc.GetBurndown)
+	pj.GET("/:projectId/budget-alerts", prc.GetBudgetAlerts)
+
+	rp := e.Group("/admin/reports")
+	rp.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	rp.Use(am.RequireRole(model.RoleAdmin))
+	rp.GET("/billing", bc.GetBillingReport)
+
+	ae := e.Group("/admin/attendance-exceptions")
+	ae.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	// 部署の範囲の確認はユースケースで行う
+	ae.Use(am.RequireRole(model.RoleAdmin, model.RoleManager))
+	ae.GET("", aec.GetExceptions)
+	ae.POST("/detect", aec.DetectAnomalies, am.RequireRole(model.RoleAdmin))
+	ae.PUT("/:exceptionId/resolve", aec.ResolveException)
+
+	hd := e.Group("/admin/holidays")
+	hd.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	hd.Use(am.RequireRole(model.RoleAdmin))
+	hd.GET("", aec.GetHolidays)
+	hd.POST("", aec.CreateHoliday)
+	hd.DELETE("/:holidayId", aec.DeleteHoliday)
+
+	wh := e.Group("/admin/webhooks")
+	wh.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	wh.Use(am.RequireRole(model.RoleAdmin))
+	wh.GET("", wc.GetSubscriptions)
+	wh.POST("", wc.CreateSubscription)
+	wh.PUT("/:subscriptionId", wc.UpdateSubscription)
+	wh.DELETE("/:subscriptionId", wc.DeleteSubscription)
+	wh.GET("/:subscriptionId/deliveries", wc.GetDeliveries)
+	wh.POST("/deliveries/:deliveryId/redeliver", wc.Redeliver)
+
+	jb := e.Group("/admin/jobs")
+	jb.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	jb.Use(am.RequireRole(model.RoleAdmin))
+	jb.GET("", jc.GetJobs)
+	jb.GET("/:name/runs", jc.GetRuns)
+	jb.POST("/:name/run", jc.TriggerJob)
+	jb.PUT("/:name", jc.UpdateJob)
+
+	nt := e.Group("/notifications")
+	nt.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	nt.GET("", nc.GetNotifications)
+	nt.GET("/unread-count", nc.CountUnread)
+	nt.PUT("/read-all", nc.MarkAllRead)
+	nt.PUT("/:notificationId/read", nc.MarkRead)
+	nt.GET("/settings", nc.GetSettings)
+	nt.PUT("/settings", nc.UpdateSettings)
+
+	ps := e.Group("/presence")
+	ps.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	ps.GET("", psc.GetSnapshot)
+	ps.GET("/stream", psc.StreamPresence)
+
+	lv := e.Group("/leaves")
+	lv.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	lv.GET("", lc.GetLeaves)
+	lv.GET("/balance", lc.GetBalance)
+	lv.POST("", lc.CreateLeave)
+	lv.DELETE("/:leaveId", lc.DeleteLeave)
+
+	hw := e.Group("/holiday-work-requests")
+	hw.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	hw.GET("", hwc.GetMyRequests)
+	hw.POST("", hwc.CreateRequest)
+	hw.DELETE("/:requestId", hwc.DeleteRequest)
+	// 審査は管理者・マネージャーのみ。部署の範囲の確認はユースケースで行う
+	hw.GET("/review", hwc.GetRequests, am.RequireRole(model.RoleAdmin, model.RoleManager))
+	hw.PUT("/:requestId/approve", hwc.ApproveRequest, am.RequireRole(model.RoleAdmin, model.RoleManager))
+	hw.PUT("/:requestId/reject", hwc.RejectRequest, am.RequireRole(model.RoleAdmin, model.RoleManager))
+
+	tm := e.Group("/team")
+	tm.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	tm.GET("", rlc.GetTeam)
+
+	rl := e.Group("/reporting-lines")
+	rl.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  []byte(os.Getenv("SECRET")),
+		TokenLookup: "cookie:token",
+	}), am.RequireActive)
+	rl.GET("", rlc.GetReportingLines)
+	// 上長の設定はチームの勤怠の閲覧権限になるため管理者だけが変更できる
+	rl.POST("", rlc.CreateReportingLine, am.RequireRole(model.RoleAdmin))
+	rl.PUT("/:lineId/end", rlc.EndReportingLine, am.RequireRole(model.RoleAdmin))
+	rl.DELETE("/:lineId", rlc.DeleteReportingLine, am.RequireRole(model.RoleAdmin))
 
 	return e
 }
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+type IAttendanceExceptionUsecase interface {
+	GetExceptions(userId uint, status string, department string) ([]model.AttendanceExceptionResponse, error)
+	ResolveException(userId uint, exceptionId uint, note string) (model.AttendanceExceptionResponse, error)
+	DetectAnomalies(now time.Time) (int64, error)
+	GetHolidays() ([]model.Holiday, error)
+	CreateHoliday(holiday model.Holiday) (model.Holiday, error)
+	DeleteHoliday(holidayId uint) error
+}
+
+type attendanceExceptionUsecase struct {
+	aer repository.IAttendanceExceptionRepository
+	ur  repository.IUserRepository
+	nu  INotificationUsecase
+}
+
+func NewAttendanceExceptionUsecase(aer repository.IAttendanceExceptionRepository, ur repository.IUserRepository, nu INotificationUsecase) IAttendanceExceptionUsecase {
+	return &attendanceExceptionUsecase{aer, ur, nu}
+}
+
+// envInt は環境変数の値（正の整数）を返す。未設定や不正な値の場合は既定値を使う
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// anomalyRules は今日より前の ANOMALY_LOOKBACK_DAYS 日分（既定 7 日）を対象にする。
+// 勤務中の記録を誤検知しないよう当日は含めない
+func anomalyRules(now time.Time) model.AnomalyRules {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	return model.AnomalyRules{
+		Since:              today.AddDate(0, 0, -envInt("ANOMALY_LOOKBACK_DAYS", 7)),
+		Until:              today,
+		MaxShiftMinutes:    envInt("ANOMALY_MAX_SHIFT_MINUTES", 16*60),
+		ScheduleGapMinutes: envInt("ANOMALY_SCHEDULE_GAP_MINUTES", 120),
+	}
+}
+
+func toAttendanceExceptionResponse(exception model.AttendanceException) model.AttendanceExceptionResponse {
+	return model.AttendanceExceptionResponse{
+		ID:                 exception.ID,
+		UserID:             exception.UserID,
+		UserName:           exception.User.Name,
+		Department:         exception.User.Department,
+		AttendanceRecordID: exception.AttendanceRecordID,
+		Type:               exception.Type,
+		Date:               exception.Date,
+		Detail:             exception.Detail,
+		Status:             exception.Status,
+		ResolvedBy:         exception.ResolvedBy,
+		ResolvedAt:         exception.ResolvedAt,
+		ResolutionNote:     exception.ResolutionNote,
+		CreatedAt:          exception.CreatedAt,
+	}
+}
+
+// GetExceptions は管理者には全部署（部署の指定があればその部署）、マネージャーには自部署の例外を返す
+func (aeu *attendanceExceptionUsecase) GetExceptions(userId uint, status string, department string) ([]model.AttendanceExceptionResponse, error) {
+	user := model.User{}
+	if err := aeu.ur.GetUserById(&user, userId); err != nil {
+		return nil, err
+	}
+	if user.Role != model.RoleAdmin {
+		if department == "" {
+			department = user.Department
+		}
+		if !user.CanManageDepartment(department) {
+			return nil, model.NewForbiddenError("not_department_manager", "only managers of the department can view attendance exceptions")
+		}
+	}
+	exceptions := []model.AttendanceException{}
+	if err := aeu.aer.GetExceptions(&exceptions, status, department); err != nil {
+		return nil, err
+	}
+	resExceptions := make([]model.AttendanceExceptionResponse, len(exceptions))
+	for i, v := range exceptions {
+		resExceptions[i] = toAttendanceExceptionResponse(v)
+	}
+	return resExceptions, nil
+}
+
+func (aeu *attendanceExceptionUsecase) ResolveException(userId uint, exceptionId uint, note string) (model.AttendanceExceptionResponse, error) {
+	user := model.User{}
+	if err := aeu.ur.GetUserById(&user, userId); err != nil {
+		return model.AttendanceExceptionResponse{}, err
+	}
+	exception := model.AttendanceException{}
+	if err := aeu.aer.GetExceptionById(&exception, exceptionId); err != nil {
+		return model.AttendanceExceptionResponse{}, err
+	}
+	if !user.CanManageDepartment(exception.User.Department) {
+		return model.AttendanceExceptionResponse{}, model.NewForbiddenError("not_department_manager", "only managers of the department can resolve this exception")
+	}
+	now := time.Now()
+	exception.ResolvedBy = &userId
+	exception.ResolvedAt = &now
+	exception.ResolutionNote = note
+	if err := aeu.aer.ResolveException(&exception, exceptionId); err != nil {
+		return model.AttendanceExceptionResponse{}, err
+	}
+	return toAttendanceExceptionResponse(exception), nil
+}
+
+// DetectAnomalies は新たに検知した件数を返す。退勤の打刻漏れは本人に通知する
+func (aeu *attendanceExceptionUsecase) DetectAnomalies(now time.Time) (int64, error) {
+	exceptions := []model.AttendanceException{}
+	if err := aeu.aer.DetectAnomalies(&exceptions, anomalyRules(now)); err != nil {
+		return 0, err
+	}
+	for _, v := range exceptions {
+		if v.Type != model.AnomalyMissingClockOut {
+			continue
+		}
+		data := map[string]string{"Date": v.Date.Format("2006-01-02")}
+		if err := aeu.nu.Notify(v.UserID, model.NotificationForgottenClockOut, data); err != nil {
+			log.Printf("failed to notify missing clock-out of exception %d: %v", v.ID, err)
+		}
+	}
+	return int64(len(exceptions)), nil
+}
+
+func (aeu *attendanceExceptionUsecase) GetHolidays() ([]model.Holiday, error) {
+	holidays := []model.Holiday{}
+	if err := aeu.aer.GetHolidays(&holidays); err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+func (aeu *attendanceExceptionUsecase) CreateHoliday(holiday model.Holiday) (model.Holiday, error) {
+	if holiday.Name == "" {
+		return model.Holiday{}, model.NewInvalidError("holiday_name_required", "name is required")
+	}
+	if err := aeu.aer.CreateHoliday(&holiday); err != nil {
+		return model.Holiday{}, err
+	}
+	return holiday, nil
+}
+
+func (aeu *attendanceExceptionUsecase) DeleteHoliday(holidayId uint) error {
+	return aeu.aer.DeleteHoliday(holidayId)
+}
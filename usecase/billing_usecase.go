@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"math"
+	"sort"
+	"time"
+)
+
+type IBillingUsecase interface {
+	GetBillingReport(from time.Time, to time.Time, client string) (model.BillingReportResponse, error)
+}
+
+type billingUsecase struct {
+	ter repository.ITimeEntryRepository
+	pr  repository.IProjectRepository
+}
+
+func NewBillingUsecase(ter repository.ITimeEntryRepository, pr repository.IProjectRepository) IBillingUsecase {
+	return &billingUsecase{ter, pr}
+}
+
+// roundTo2 は金額・時間を小数第2位で四捨五入する
+func roundTo2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func (bu *billingUsecase) GetBillingReport(from time.Time, to time.Time, client string) (model.BillingReportResponse, error) {
+	rows := []model.BillingRow{}
+	if err := bu.ter.GetBillingRows(&rows, from, to, client); err != nil {
+		return model.BillingReportResponse{}, err
+	}
+	res := model.BillingReportResponse{From: from, To: to, Invoices: []model.ClientInvoice{}}
+	if len(rows) == 0 {
+		return res, nil
+	}
+
+	projectIds := []uint{}
+	seen := map[uint]bool{}
+	for _, v := range rows {
+		if !seen[v.ProjectID] {
+			seen[v.ProjectID] = true
+			projectIds = append(projectIds, v.ProjectID)
+		}
+	}
+	projects := []model.Project{}
+	if err := bu.pr.GetProjectsByIds(&projects, projectIds); err != nil {
+		return model.BillingReportResponse{}, err
+	}
+	projectMap := map[uint]model.Project{}
+	for _, p := range projects {
+		projectMap[p.ID] = p
+	}
+
+	// プロジェクト×請求ロール×単価ごとに請求対象・対象外の分数をまとめる。
+	// 単価は作業時間の記録時に保存したものを使い、保存前の作業時間だけ現在の単価で計算する
+	type lineKey struct {
+		projectId uint
+		role      string
+		rate      float64
+	}
+	billable := map[lineKey]float64{}
+	nonBillable := map[lineKey]float64{}
+	keys := []lineKey{}
+	for _, v := range rows {
+		rate := projectMap[v.ProjectID].RateFor(v.Role)
+		if v.HourlyRate != nil {
+			rate = *v.HourlyRate
+		}
+		key := lineKey{v.ProjectID, v.Role, rate}
+		_, hasBillable := billable[key]
+		_, hasNonBillable := nonBillable[key]
+		if !hasBillable && !hasNonBillable {
+			keys = append(keys, key)
+		}
+		if v.Billable {
+			billable[key] += v.Minutes
+		} else {
+			nonBillable[key] += v.Minutes
+		}
+	}
+
+	invoices := map[string]*model.ClientInvoice{}
+	clients := []string{}
+	for _, key := range keys {
+		project := projectMap[key.projectId]
+		rate := key.rate
+		billableHours := billable[key] / 60
+		line := model.InvoiceLine{
+			ProjectID:        project.ID,
+			ProjectCode:      project.Code,
+			ProjectName:      project.Name,
+			Role:             key.role,
+			HourlyRate:       rate,
+			BillableHours:    roundTo2(billableHours),
+			NonBillableHours: roundTo2(nonBillable[key] / 60),
+			Amount:           roundTo2(billableHours * rate),
+		}
+		invoice, ok := invoices[project.Client]
+		if !ok {
+			invoice = &model.ClientInvoice{Client: project.Client, PeriodFrom: from, PeriodTo: to}
+			invoices[project.Client] = invoice
+			clients = append(clients, project.Client)
+		}
+		invoice.Lines = append(invoice.Lines, line)
+		invoice.BillableHours = roundTo2(invoice.BillableHours + line.BillableHours)
+		invoice.NonBillableHours = roundTo2(invoice.NonBillableHours + line.NonBillableHours)
+		invoice.TotalAmount = roundTo2(invoice.TotalAmount + line.Amount)
+	}
+
+	sort.Strings(clients)
+	for _, c := range clients {
+		invoice := invoices[c]
+		sort.Slice(invoice.Lines, func(i, j int) bool {
+			if invoice.Lines[i].ProjectCode != invoice.Lines[j].ProjectCode {
+				return invoice.Lines[i].ProjectCode < invoice.Lines[j].ProjectCode
+			}
+			if invoice.Lines[i].Role != invoice.Lines[j].Role {
+				return invoice.Lines[i].Role < invoice.Lines[j].Role
+			}
+			return invoice.Lines[i].HourlyRate < invoice.Lines[j].HourlyRate
+		})
+		res.Invoices = append(res.Invoices, *invoice)
+		res.TotalAmount = roundTo2(res.TotalAmount + invoice.TotalAmount)
+	}
+	return res, nil
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type IEmploymentTypeUsecase interface {
+	GetAllEmploymentTypes() ([]model.EmploymentTypeResponse, error)
+	GetEmploymentTypeById(typeId uint) (model.EmploymentTypeResponse, error)
+	CreateEmploymentType(employmentType model.EmploymentType) (model.EmploymentTypeResponse, error)
+	UpdateEmploymentType(employmentType model.EmploymentType, typeId uint) (model.EmploymentTypeResponse, error)
+	DeleteEmploymentType(typeId uint) error
+}
+
+type employmentTypeUsecase struct {
+	etr repository.IEmploymentTypeRepository
+	etv validator.IEmploymentTypeValidator
+}
+
+func NewEmploymentTypeUsecase(etr repository.IEmploymentTypeRepository, etv validator.IEmploymentTypeValidator) IEmploymentTypeUsecase {
+	return &employmentTypeUsecase{etr, etv}
+}
+
+func (etu *employmentTypeUsecase) GetAllEmploymentTypes() ([]model.EmploymentTypeResponse, error) {
+	types := []model.EmploymentType{}
+	if err := etu.etr.GetAllEmploymentTypes(&types); err != nil {
+		return nil, err
+	}
+	resTypes := make([]model.EmploymentTypeResponse, len(types))
+	for i, v := range types {
+		resTypes[i] = model.EmploymentTypeResponse{
+			ID:                       v.ID,
+			Code:                     v.Code,
+			Name:                     v.Name,
+			ScheduledMinutesPerDay:   v.ScheduledMinutesPerDay,
+			ScheduledStartTime:       v.ScheduledStartTime,
+			OvertimeThresholdMinutes: v.OvertimeThresholdMinutes,
+			WeeklyOvertimeThreshold:  v.WeeklyOvertimeThreshold,
+			LeaveGrants:              v.LeaveGrants,
+		}
+	}
+	return resTypes, nil
+}
+
+func (etu *employmentTypeUsecase) GetEmploymentTypeById(typeId uint) (model.EmploymentTypeResponse, error) {
+	employmentType := model.EmploymentType{}
+	if err := etu.etr.GetEmploymentTypeById(&employmentType, typeId); err != nil {
+		return model.EmploymentTypeResponse{}, err
+	}
+	return model.EmploymentTypeResponse{
+		ID:                       employmentType.ID,
+		Code:                     employmentType.Code,
+		Name:                     employmentType.Name,
+		ScheduledMinutesPerDay:   employmentType.ScheduledMinutesPerDay,
+		ScheduledStartTime:       employmentType.ScheduledStartTime,
+		OvertimeThresholdMinutes: employmentType.OvertimeThresholdMinutes,
+		WeeklyOvertimeThreshold:  employmentType.WeeklyOvertimeThreshold,
+		LeaveGrants:              employmentType.LeaveGrants,
+	}, nil
+}
+
+func (etu *employmentTypeUsecase) CreateEmploymentType(employmentType model.EmploymentType) (model.EmploymentTypeResponse, error) {
+	if err := etu.etv.EmploymentTypeValidate(employmentType); err != nil {
+		return model.EmploymentTypeResponse{}, err
+	}
+	if err := etu.etr.CreateEmploymentType(&employmentType); err != nil {
+		return model.EmploymentTypeResponse{}, err
+	}
+	return model.EmploymentTypeResponse{
+		ID:                       employmentType.ID,
+		Code:                     employmentType.Code,
+		Name:                     employmentType.Name,
+		ScheduledMinutesPerDay:   employmentType.ScheduledMinutesPerDay,
+		ScheduledStartTime:       employmentType.ScheduledStartTime,
+		OvertimeThresholdMinutes: employmentType.OvertimeThresholdMinutes,
+		WeeklyOvertimeThreshold:  employmentType.WeeklyOvertimeThreshold,
+		LeaveGrants:              employmentType.LeaveGrants,
+	}, nil
+}
+
+func (etu *employmentTypeUsecase) UpdateEmploymentType(employmentType model.EmploymentType, typeId uint) (model.EmploymentTypeResponse, error) {
+	if err := etu.etv.EmploymentTypeValidate(employmentType); err != nil {
+		return model.EmploymentTypeResponse{}, err
+	}
+	if err := etu.etr.UpdateEmploymentType(&employmentType, typeId); err != nil {
+		return model.EmploymentTypeResponse{}, err
+	}
+	return model.EmploymentTypeResponse{
+		ID:                       employmentType.ID,
+		Code:                     employmentType.Code,
+		Name:                     employmentType.Name,
+		ScheduledMinutesPerDay:   employmentType.ScheduledMinutesPerDay,
+		ScheduledStartTime:       employmentType.ScheduledStartTime,
+		OvertimeThresholdMinutes: employmentType.OvertimeThresholdMinutes,
+		WeeklyOvertimeThreshold:  employmentType.WeeklyOvertimeThreshold,
+		LeaveGrants:              employmentType.LeaveGrants,
+	}, nil
+}
+
+func (etu *employmentTypeUsecase) DeleteEmploymentType(typeId uint) error {
+	return etu.etr.DeleteEmploymentType(typeId)
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"time"
+)
+
+type IHolidayWorkRequestUsecase interface {
+	GetMyRequests(userId uint) ([]model.HolidayWorkRequestResponse, error)
+	GetRequests(userId uint, status string, department string) ([]model.HolidayWorkRequestResponse, error)
+	CreateRequest(request model.HolidayWorkRequest) (model.HolidayWorkRequestResponse, error)
+	ReviewRequest(userId uint, requestId uint, approve bool) (model.HolidayWorkRequestResponse, error)
+	DeleteRequest(userId uint, requestId uint) error
+}
+
+type holidayWorkRequestUsecase struct {
+	hr repository.IHolidayWorkRequestRepository
+	ur repository.IUserRepository
+	hv validator.IHolidayWorkRequestValidator
+}
+
+func NewHolidayWorkRequestUsecase(hr repository.IHolidayWorkRequestRepository, ur repository.IUserRepository, hv validator.IHolidayWorkRequestValidator) IHolidayWorkRequestUsecase {
+	return &holidayWorkRequestUsecase{hr, ur, hv}
+}
+
+func toHolidayWorkRequestResponse(request model.HolidayWorkRequest) model.HolidayWorkRequestResponse {
+	return model.HolidayWorkRequestResponse{
+		ID:         request.ID,
+		UserID:     request.UserID,
+		UserName:   request.User.Name,
+		Department: request.User.Department,
+		Date:       request.Date,
+		Reason:     request.Reason,
+		Status:     request.Status,
+		ReviewedBy: request.ReviewedBy,
+		ReviewedAt: request.ReviewedAt,
+		CreatedAt:  request.CreatedAt,
+	}
+}
+
+func toHolidayWorkRequestResponses(requests []model.HolidayWorkRequest) []model.HolidayWorkRequestResponse {
+	res := make([]model.HolidayWorkRequestResponse, len(requests))
+	for i, v := range requests {
+		res[i] = toHolidayWorkRequestResponse(v)
+	}
+	return res
+}
+
+func (hu *holidayWorkRequestUsecase) GetMyRequests(userId uint) ([]model.HolidayWorkRequestResponse, error) {
+	requests := []model.HolidayWorkRequest{}
+	if err := hu.hr.GetRequestsByUser(&requests, userId); err != nil {
+		return nil, err
+	}
+	return toHolidayWorkRequestResponses(requests), nil
+}
+
+// GetRequests は管理者には全部署（部署の指定があればその部署）、マネージャーには自部署の申請を返す
+func (hu *holidayWorkRequestUsecase) GetRequests(userId uint, status string, department string) ([]model.HolidayWorkRequestResponse, error) {
+	user := model.User{}
+	if err := hu.ur.GetUserById(&user, userId); err != nil {
+		return nil, err
+	}
+	if user.Role != model.RoleAdmin {
+		if department == "" {
+			department = user.Department
+		}
+		if !user.CanManageDepartment(department) {
+			return nil, model.NewForbiddenError("not_department_manager", "only managers of the department can view holiday work requests")
+		}
+	}
+	requests := []model.HolidayWorkRequest{}
+	if err := hu.hr.GetRequests(&requests, status, department); err != nil {
+		return nil, err
+	}
+	return toHolidayWorkRequestResponses(requests), nil
+}
+
+// CreateRequest は会社の休日に対してだけ申請できる
+func (hu *holidayWorkRequestUsecase) CreateRequest(request model.HolidayWorkRequest) (model.HolidayWorkRequestResponse, error) {
+	if err := hu.hv.HolidayWorkRequestValidate(request); err != nil {
+		return model.HolidayWorkRequestResponse{}, err
+	}
+	holiday, err := hu.hr.IsHoliday(request.Date)
+	if err != nil {
+		return model.HolidayWorkRequestResponse{}, err
+	}
+	if !holiday {
+		return model.HolidayWorkRequestResponse{}, model.NewInvalidError("not_a_holiday", "%s is not a company holiday", request.Date.Format("2006-01-02"))
+	}
+	request.Status = model.HolidayWorkPending
+	if err := hu.hr.CreateRequest(&request); err != nil {
+		return model.HolidayWorkRequestResponse{}, err
+	}
+	if err := hu.hr.GetRequestById(&request, request.ID); err != nil {
+		return model.HolidayWorkRequestResponse{}, err
+	}
+	return toHolidayWorkRequestResponse(request), nil
+}
+
+// ReviewRequest は申請者の部署のマネージャー（または管理者）が承認・却下する。自分の申請は審査できない
+func (hu *holidayWorkRequestUsecase) ReviewRequest(userId uint, requestId uint, approve bool) (model.HolidayWorkRequestResponse, error) {
+	user := model.User{}
+	if err := hu.ur.GetUserById(&user, userId); err != nil {
+		return model.HolidayWorkRequestResponse{}, err
+	}
+	request := model.HolidayWorkRequest{}
+	if err := hu.hr.GetRequestById(&request, requestId); err != nil {
+		return model.HolidayWorkRequestResponse{}, err
+	}
+	if !user.CanManageDepartment(request.User.Department) {
+		return model.HolidayWorkRequestResponse{}, model.NewForbiddenError("not_department_manager", "only managers of the department can review this request")
+	}
+	if request.UserID == userId {
+		return model.HolidayWorkRequestResponse{}, model.NewForbiddenError("self_review", "cannot review your own request")
+	}
+	now := time.Now()
+	request.Status = model.HolidayWorkRejected
+	if approve {
+		request.Status = model.HolidayWorkApproved
+	}
+	request.ReviewedBy = &userId
+	request.ReviewedAt = &now
+	if err := hu.hr.ReviewRequest(&request, requestId); err != nil {
+		return model.HolidayWorkRequestResponse{}, err
+	}
+	return toHolidayWorkRequestResponse(request), nil
+}
+
+func (hu *holidayWorkRequestUsecase) DeleteRequest(userId uint, requestId uint) error {
+	return hu.hr.DeletePendingRequest(userId, requestId)
+}
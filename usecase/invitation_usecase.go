@@ -0,0 +1,361 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type IInvitationUsecase interface {
+	GetPendingInvitations() ([]model.InvitationResponse, error)
+	CreateInvitation(invitation model.Invitation) (model.InvitationResponse, error)
+	ResendInvitation(invitationId uint) (model.InvitationResponse, error)
+	CancelInvitation(invitationId uint) error
+	ImportUsersCSV(r io.Reader, dryRun bool) (model.UserImportResponse, error)
+	InviteExistingUser(user model.User) error
+	AcceptInvitation(token string, password string) (model.UserResponse, error)
+}
+
+// 招待リンクの有効期限（時間）。INVITATION_TTL_HOURS で上書きできる
+const defaultInvitationTTLHours = 72
+
+type invitationUsecase struct {
+	ir  repository.IInvitationRepository
+	ur  repository.IUserRepository
+	etr repository.IEmploymentTypeRepository
+	iv  validator.IInvitationValidator
+	m   mailer.IMailer
+}
+
+func NewInvitationUsecase(ir repository.IInvitationRepository, ur repository.IUserRepository, etr repository.IEmploymentTypeRepository, iv validator.IInvitationValidator, m mailer.IMailer) IInvitationUsecase {
+	return &invitationUsecase{ir, ur, etr, iv, m}
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func invitationTTL() time.Duration {
+	hours := defaultInvitationTTLHours
+	if v, err := strconv.Atoi(os.Getenv("INVITATION_TTL_HOURS")); err == nil && v > 0 {
+		hours = v
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// newInvitationToken は招待リンク用のトークンと、DB に保存するそのハッシュを返す
+func newInvitationToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+	return token, hashInvitationToken(token), nil
+}
+
+func toInvitationResponse(invitation model.Invitation, now time.Time) model.InvitationResponse {
+	return model.InvitationResponse{
+		ID:         invitation.ID,
+		Email:      invitation.Email,
+		Name:       invitation.Name,
+		Department: invitation.Department,
+		Role:       invitation.Role,
+		HireDate:   invitation.HireDate,
+		Status:     invitation.Status(now),
+		InvitedBy:  invitation.InvitedBy,
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
+
+// issueInvitation は新しいトークンを発行して招待を保存し、招待メールを送信する。
+// DB にはトークンのハッシュのみを保存する。送信に失敗した招待は削除し、招待し直せるようにする
+func (iu *invitationUsecase) issueInvitation(invitation *model.Invitation) error {
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return err
+	}
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = time.Now().Add(invitationTTL())
+	if err := iu.ir.CreateInvitation(invitation); err != nil {
+		return err
+	}
+	if err := iu.sendInvitationMail(*invitation, token); err != nil {
+		if derr := iu.ir.DeleteInvitation(invitation.ID); derr != nil {
+			log.Printf("failed to delete unsent invitation %d: %v", invitation.ID, derr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (iu *invitationUsecase) sendInvitationMail(invitation model.Invitation, token string) error {
+	link := fmt.Sprintf("%s/invitations/accept?token=%s", os.Getenv("FE_URL"), token)
+	body := fmt.Sprintf("%s さん\n\n勤怠管理システムに招待されました。\n以下のリンクからパスワードを設定してください（有効期限: %s）。\n\n%s\n",
+		invitation.Name, invitation.ExpiresAt.Format("2006-01-02 15:04"), link)
+	return iu.m.Send(invitation.Email, "勤怠管理システムへの招待", body)
+}
+
+// checkInvitable は既存ユーザーや有効な招待とメールアドレスが重複していないか確認する
+func (iu *invitationUsecase) checkInvitable(email string, now time.Time) error {
+	count, err := iu.ur.CountUsersByEmail(email)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return model.NewConflictError(model.ErrEmailTaken.Code, "user with this email already exists")
+	}
+	count, err = iu.ir.CountPendingByEmail(email, now)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return model.NewConflictError("invitation_pending", "invitation for this email is already pending")
+	}
+	return nil
+}
+
+func (iu *invitationUsecase) GetPendingInvitations() ([]model.InvitationResponse, error) {
+	invitations := []model.Invitation{}
+	if err := iu.ir.GetUnacceptedInvitations(&invitations); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	resInvitations := make([]model.InvitationResponse, len(invitations))
+	for i, v := range invitations {
+		resInvitations[i] = toInvitationResponse(v, now)
+	}
+	return resInvitations, nil
+}
+
+func (iu *invitationUsecase) CreateInvitation(invitation model.Invitation) (model.InvitationResponse, error) {
+	invitation.Email = strings.ToLower(strings.TrimSpace(invitation.Email))
+	if invitation.Role == "" {
+		invitation.Role = model.RoleMember
+	}
+	if err := iu.iv.InvitationValidate(invitation); err != nil {
+		return model.InvitationResponse{}, err
+	}
+	now := time.Now()
+	if err := iu.checkInvitable(invitation.Email, now); err != nil {
+		return model.InvitationResponse{}, err
+	}
+	newInvitation := model.Invitation{
+		Email:            invitation.Email,
+		Name:             invitation.Name,
+		Department:       invitation.Department,
+		Role:             invitation.Role,
+		HireDate:         invitation.HireDate,
+		EmploymentTypeID: invitation.EmploymentTypeID,
+		InvitedBy:        invitation.InvitedBy,
+	}
+	if err := iu.issueInvitation(&newInvitation); err != nil {
+		return model.InvitationResponse{}, err
+	}
+	return toInvitationResponse(newInvitation, now), nil
+}
+
+func (iu *invitationUsecase) ResendInvitation(invitationId uint) (model.InvitationResponse, error) {
+	invitation := model.Invitation{}
+	if err := iu.ir.GetInvitationById(&invitation, invitationId); err != nil {
+		return model.InvitationResponse{}, err
+	}
+	if invitation.AcceptedAt != nil {
+		return model.InvitationResponse{}, model.NewConflictError("invitation_used", "invitation has already been used")
+	}
+	// 再送時はトークンを作り直し、以前のリンクは無効にする
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return model.InvitationResponse{}, err
+	}
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = time.Now().Add(invitationTTL())
+	if err := iu.ir.UpdateToken(invitation.ID, invitation.TokenHash, invitation.ExpiresAt); err != nil {
+		return model.InvitationResponse{}, err
+	}
+	if err := iu.sendInvitationMail(invitation, token); err != nil {
+		return model.InvitationResponse{}, err
+	}
+	return toInvitationResponse(invitation, time.Now()), nil
+}
+
+func (iu *invitationUsecase) CancelInvitation(invitationId uint) error {
+	return iu.ir.DeleteInvitation(invitationId)
+}
+
+func (iu *invitationUsecase) ImportUsersCSV(r io.Reader, dryRun bool) (model.UserImportResponse, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return model.UserImportResponse{}, model.NewBadRequestError("invalid_csv", "failed to read csv header: %v", err)
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\uFEFF")))] = i
+	}
+	for _, required := range []string{"email", "name"} {
+		if _, ok := columns[required]; !ok {
+			return model.UserImportResponse{}, model.NewBadRequestError("invalid_csv", "csv header must contain %s column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	res := model.UserImportResponse{DryRun: dryRun, Rows: []model.UserImportRowResult{}}
+	seen := map[string]bool{}
+	employmentTypeIds := map[string]uint{}
+	now := time.Now()
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		res.Total++
+		result := model.UserImportRowResult{Row: row}
+		fail := func(err error) {
+			result.Status = model.ImportStatusError
+			result.Error = err.Error()
+			res.Failed++
+			res.Rows = append(res.Rows, result)
+		}
+		if err != nil {
+			fail(err)
+			continue
+		}
+		invitation := model.Invitation{
+			Email:      strings.ToLower(field(record, "email")),
+			Name:       field(record, "name"),
+			Department: field(record, "department"),
+			Role:       field(record, "role"),
+		}
+		if invitation.Role == "" {
+			invitation.Role = model.RoleMember
+		}
+		result.Email = invitation.Email
+		if err := iu.iv.InvitationValidate(invitation); err != nil {
+			fail(err)
+			continue
+		}
+		if v := field(record, "hire_date"); v != "" {
+			hireDate, err := time.ParseInLocation("2006-01-02", v, time.Local)
+			if err != nil {
+				fail(fmt.Errorf("invalid hire_date format"))
+				continue
+			}
+			invitation.HireDate = &hireDate
+		}
+		if code := field(record, "employment_type"); code != "" {
+			id, ok := employmentTypeIds[code]
+			if !ok {
+				employmentType := model.EmploymentType{}
+				if err := iu.etr.GetEmploymentTypeByCode(&employmentType, code); err != nil {
+					fail(fmt.Errorf("unknown employment_type: %s", code))
+					continue
+				}
+				id = employmentType.ID
+				employmentTypeIds[code] = id
+			}
+			invitation.EmploymentTypeID = &id
+		}
+		if seen[invitation.Email] {
+			fail(fmt.Errorf("duplicate email in file"))
+			continue
+		}
+		seen[invitation.Email] = true
+		if err := iu.checkInvitable(invitation.Email, now); err != nil {
+			fail(err)
+			continue
+		}
+		if dryRun {
+			result.Status = model.ImportStatusValid
+			res.Rows = append(res.Rows, result)
+			continue
+		}
+		if err := iu.issueInvitation(&invitation); err != nil {
+			fail(err)
+			continue
+		}
+		result.Status = model.ImportStatusInvited
+		res.Invited++
+		res.Rows = append(res.Rows, result)
+	}
+	return res, nil
+}
+
+func (iu *invitationUsecase) InviteExistingUser(user model.User) error {
+	invitation := model.Invitation{
+		Email:      user.Email,
+		Name:       user.Name,
+		Department: user.Department,
+		Role:       user.Role,
+		UserID:     &user.ID,
+	}
+	if invitation.Role == "" {
+		invitation.Role = model.RoleMember
+	}
+	return iu.issueInvitation(&invitation)
+}
+
+func (iu *invitationUsecase) AcceptInvitation(token string, password string) (model.UserResponse, error) {
+	if err := iu.iv.PasswordValidate(password); err != nil {
+		return model.UserResponse{}, err
+	}
+	invitation := model.Invitation{}
+	if err := iu.ir.GetInvitationByTokenHash(&invitation, hashInvitationToken(token)); err != nil {
+		return model.UserResponse{}, model.NewNotFoundError("invitation_not_found", "invalid invitation token")
+	}
+	now := time.Now()
+	if invitation.AcceptedAt != nil {
+		return model.UserResponse{}, model.NewConflictError("invitation_used", "invitation has already been used")
+	}
+	if invitation.ExpiresAt.Before(now) {
+		return model.UserResponse{}, model.NewInvalidError("invitation_expired", "invitation has expired")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return model.UserResponse{}, err
+	}
+	newUser := model.User{
+		Email:            invitation.Email,
+		Password:         string(hash),
+		Name:             invitation.Name,
+		Department:       invitation.Department,
+		Role:             invitation.Role,
+		HireDate:         invitation.HireDate,
+		EmploymentTypeID: invitation.EmploymentTypeID,
+	}
+	if err := iu.ir.AcceptInvitation(&invitation, &newUser, now); err != nil {
+		return model.UserResponse{}, err
+	}
+	return model.UserResponse{
+		ID:         newUser.ID,
+		Email:      newUser.Email,
+		Name:       newUser.Name,
+		Department: newUser.Department,
+		Role:       newUser.Role,
+		HireDate:   newUser.HireDate,
+		IsActive:   true,
+	}, nil
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// JobFunc はスケジューラーから呼ばれるジョブ本体。停止時は ctx がキャンセルされる
+type JobFunc func(ctx context.Context) error
+
+type IJobUsecase interface {
+	Register(name string, spec string, fn JobFunc) error
+	Start()
+	StartWorker(name string, interval time.Duration, fn JobFunc)
+	Stop(ctx context.Context) error
+	GetJobs() ([]model.Job, error)
+	GetRuns(name string, limit int) ([]model.JobRun, error)
+	TriggerJob(name string) error
+	SetEnabled(name string, enabled bool) (model.Job, error)
+}
+
+type registeredJob struct {
+	schedule model.CronSchedule
+	fn       JobFunc
+}
+
+type jobUsecase struct {
+	jr       repository.IJobRepository
+	instance string
+	mu       sync.RWMutex
+	jobs     map[string]registeredJob
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewJobUsecase(jr repository.IJobRepository) IJobUsecase {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &jobUsecase{jr: jr, instance: instance, jobs: map[string]registeredJob{}, ctx: ctx, cancel: cancel}
+}
+
+// jobPollInterval は実行時刻を迎えたジョブを確認する間隔。JOB_POLL_SECONDS で変更できる
+func jobPollInterval() time.Duration {
+	return time.Duration(envInt("JOB_POLL_SECONDS", 30)) * time.Second
+}
+
+// Register はジョブを登録する。状態は DB で共有するため、スケジュールが変わらない限り次回の実行時刻や有効・無効は引き継ぐ
+func (ju *jobUsecase) Register(name string, spec string, fn JobFunc) error {
+	schedule, err := model.ParseCron(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	job := model.Job{Name: name, Schedule: spec, Enabled: true, NextRunAt: schedule.Next(time.Now())}
+	if err := ju.jr.RegisterJob(&job); err != nil {
+		return err
+	}
+	ju.mu.Lock()
+	ju.jobs[name] = registeredJob{schedule, fn}
+	ju.mu.Unlock()
+	return nil
+}
+
+// Start はバックグラウンドで定期的に実行時刻を迎えたジョブを確認して実行する
+func (ju *jobUsecase) Start() {
+	ju.wg.Add(1)
+	go func() {
+		defer ju.wg.Done()
+		ticker := time.NewTicker(jobPollInterval())
+		defer ticker.Stop()
+		for {
+			ju.runDue(time.Now())
+			select {
+			case <-ju.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartWorker は cron の分単位より短い間隔で回す処理を起動する。履歴は残さず、ロックで1つのレプリカだけが実行する
+func (ju *jobUsecase) StartWorker(name string, interval time.Duration, fn JobFunc) {
+	ju.wg.Add(1)
+	go func() {
+		defer ju.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			unlock, locked, err := ju.jr.TryLock(name)
+			if err != nil {
+				log.Printf("failed to lock worker %s: %v", name, err)
+			} else if locked {
+				if err := fn(ju.ctx); err != nil {
+					log.Printf("worker %s failed: %v", name, err)
+				}
+				unlock()
+			}
+			select {
+			case <-ju.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop は新たなジョブの開始を止め、実行中のジョブの終了を ctx の期限まで待つ
+func (ju *jobUsecase) Stop(ctx context.Context) error {
+	ju.cancel()
+	done := make(chan struct{})
+	go func() {
+		ju.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ju *jobUsecase) runDue(now time.Time) {
+	jobs := []model.Job{}
+	if err := ju.jr.GetJobs(&jobs); err != nil {
+		log.Printf("failed to get jobs: %v", err)
+		return
+	}
+	for _, v := range jobs {
+		if !v.Enabled || v.NextRunAt.After(now) {
+			continue
+		}
+		ju.mu.RLock()
+		registered, ok := ju.jobs[v.Name]
+		ju.mu.RUnlock()
+		if !ok {
+			continue
+		}
+		ju.wg.Add(1)
+		go func(name string, registered registeredJob) {
+			defer ju.wg.Done()
+			if err := ju.run(name, registered, now); err != nil {
+				log.Printf("failed to run job %s: %v", name, err)
+			}
+		}(v.Name, registered)
+	}
+}
+
+// run はロックを取得できた場合のみジョブを実行する。他のレプリカが先に実行し終えている場合もあるため、
+// ロック後に状態を読み直してから実行する
+func (ju *jobUsecase) run(name string, registered registeredJob, now time.Time) error {
+	if ju.ctx.Err() != nil {
+		return nil
+	}
+	unlock, locked, err := ju.jr.TryLock(name)
+	if err != nil || !locked {
+		return err
+	}
+	defer unlock()
+	job := model.Job{}
+	if err := ju.jr.GetJobByName(&job, name); err != nil {
+		return err
+	}
+	if !job.Enabled || job.NextRunAt.After(now) {
+		return nil
+	}
+
+	start := time.Now()
+	run := model.JobRun{JobName: name, Instance: ju.instance, Status: model.JobRunRunning, StartedAt: start}
+	if err := ju.jr.StartRun(&run); err != nil {
+		return err
+	}
+	runErr := ju.call(registered.fn)
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = model.JobRunSucceeded
+	run.Error = ""
+	if runErr != nil {
+		run.Status = model.JobRunFailed
+		run.Error = runErr.Error()
+	}
+	if err := ju.jr.FinishRun(&run); err != nil {
+		return err
+	}
+	job.LastRunAt = &start
+	job.LastStatus = run.Status
+	job.LastError = run.Error
+	job.NextRunAt = registered.schedule.Next(finished)
+	return ju.jr.UpdateJobState(&job)
+}
+
+// call はジョブ内の panic を失敗として記録し、スケジューラーを止めない
+func (ju *jobUsecase) call(fn JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ju.ctx)
+}
+
+func (ju *jobUsecase) GetJobs() ([]model.Job, error) {
+	jobs := []model.Job{}
+	if err := ju.jr.GetJobs(&jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (ju *jobUsecase) GetRuns(name string, limit int) ([]model.JobRun, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if err := ju.checkJob(name); err != nil {
+		return nil, err
+	}
+	runs := []model.JobRun{}
+	if err := ju.jr.GetRuns(&runs, name, limit); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// TriggerJob は次回の確認時に実行されるよう実行時刻を現在にする
+func (ju *jobUsecase) TriggerJob(name string) error {
+	if err := ju.checkJob(name); err != nil {
+		return err
+	}
+	return ju.jr.SetNextRun(name, time.Now())
+}
+
+func (ju *jobUsecase) SetEnabled(name string, enabled bool) (model.Job, error) {
+	if err := ju.jr.SetEnabled(name, enabled); err != nil {
+		return model.Job{}, err
+	}
+	job := model.Job{}
+	if err := ju.jr.GetJobByName(&job, name); err != nil {
+		return model.Job{}, err
+	}
+	return job, nil
+}
+
+func (ju *jobUsecase) checkJob(name string) error {
+	job := model.Job{}
+	return ju.jr.GetJobByName(&job, name)
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"time"
+)
+
+type ILeaveUsecase interface {
+	GetLeaves(userId uint, from *time.Time, to *time.Time) ([]model.LeaveRecord, error)
+	GetBalance(userId uint, date time.Time) (model.LeaveBalanceResponse, error)
+	CreateLeave(leave model.LeaveRecord) (model.LeaveRecord, error)
+	DeleteLeave(userId uint, leaveId uint) error
+}
+
+type leaveUsecase struct {
+	lr  repository.ILeaveRepository
+	ur  repository.IUserRepository
+	etr repository.IEmploymentTypeRepository
+	lv  validator.ILeaveValidator
+}
+
+func NewLeaveUsecase(lr repository.ILeaveRepository, ur repository.IUserRepository, etr repository.IEmploymentTypeRepository, lv validator.ILeaveValidator) ILeaveUsecase {
+	return &leaveUsecase{lr, ur, etr, lv}
+}
+
+func (lu *leaveUsecase) GetLeaves(userId uint, from *time.Time, to *time.Time) ([]model.LeaveRecord, error) {
+	leaves := []model.LeaveRecord{}
+	if err := lu.lr.GetLeaves(&leaves, userId, from, to); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// GetBalance は date を含む付与期間の残日数を、雇用区分の付与テーブルと入社日から求める
+func (lu *leaveUsecase) GetBalance(userId uint, date time.Time) (model.LeaveBalanceResponse, error) {
+	user := model.User{}
+	if err := lu.ur.GetUserById(&user, userId); err != nil {
+		return model.LeaveBalanceResponse{}, err
+	}
+	if user.HireDate == nil || user.EmploymentTypeID == nil {
+		return model.LeaveBalanceResponse{}, nil
+	}
+	employmentType := model.EmploymentType{}
+	if err := lu.etr.GetEmploymentTypeById(&employmentType, *user.EmploymentTypeID); err != nil {
+		return model.LeaveBalanceResponse{}, err
+	}
+	granted, grantedAt, ok := employmentType.LeaveGrantPeriod(*user.HireDate, date)
+	if !ok {
+		return model.LeaveBalanceResponse{}, nil
+	}
+	nextGrantAt := grantedAt.AddDate(1, 0, 0)
+	used, err := lu.lr.SumLeaveDays(userId, grantedAt, nextGrantAt)
+	if err != nil {
+		return model.LeaveBalanceResponse{}, err
+	}
+	return model.LeaveBalanceResponse{
+		GrantedAt:     &grantedAt,
+		NextGrantAt:   &nextGrantAt,
+		GrantedDays:   granted,
+		UsedDays:      used,
+		RemainingDays: granted - used,
+	}, nil
+}
+
+func (lu *leaveUsecase) CreateLeave(leave model.LeaveRecord) (model.LeaveRecord, error) {
+	if err := lu.lv.LeaveValidate(leave); err != nil {
+		return model.LeaveRecord{}, err
+	}
+	balance, err := lu.GetBalance(leave.UserID, leave.Date)
+	if err != nil {
+		return model.LeaveRecord{}, err
+	}
+	if balance.RemainingDays < leave.Days {
+		return model.LeaveRecord{}, model.NewInvalidError("insufficient_leave_balance", "remaining leave is %.1f days", balance.RemainingDays)
+	}
+	newLeave := model.LeaveRecord{UserID: leave.UserID, Date: leave.Date, Days: leave.Days, Note: leave.Note}
+	if err := lu.lr.CreateLeave(&newLeave); err != nil {
+		return model.LeaveRecord{}, err
+	}
+	return newLeave, nil
+}
+
+func (lu *leaveUsecase) DeleteLeave(userId uint, leaveId uint) error {
+	return lu.lr.DeleteLeave(userId, leaveId)
+}
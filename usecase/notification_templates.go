@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"fmt"
+	"go-rest-api/model"
+	"strings"
+	"text/template"
+)
+
+type notificationTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+func newNotificationTemplate(title string, body string) notificationTemplate {
+	return notificationTemplate{
+		title: template.Must(template.New("title").Option("missingkey=zero").Parse(title)),
+		body:  template.Must(template.New("body").Option("missingkey=zero").Parse(body)),
+	}
+}
+
+// notificationTemplates はイベント・言語ごとの件名と本文
+var notificationTemplates = map[string]map[string]notificationTemplate{
+	model.NotificationForgottenClockOut: {
+		model.LocaleJa: newNotificationTemplate(
+			"退勤の打刻がありません",
+			"{{.Date}} の勤怠に退勤の打刻がありません。勤怠記録を修正してください。"),
+		model.LocaleEn: newNotificationTemplate(
+			"Missing clock-out",
+			"Your attendance on {{.Date}} has no clock-out. Please correct the record."),
+	},
+	model.NotificationApprovalRequested: {
+		model.LocaleJa: newNotificationTemplate(
+			"タイムシートの承認依頼",
+			"{{.Name}} さんが {{.Week}} の週のタイムシートを提出しました。内容を確認して承認または差し戻してください。"),
+		model.LocaleEn: newNotificationTemplate(
+			"Timesheet approval requested",
+			"{{.Name}} submitted the timesheet for the week of {{.Week}}. Please review and approve or reject it."),
+	},
+	model.NotificationTimesheetReviewed: {
+		model.LocaleJa: newNotificationTemplate(
+			`タイムシートが{{if eq .Status "approved"}}承認されました{{else}}差し戻されました{{end}}`,
+			`{{.Week}} の週のタイムシートが{{if eq .Status "approved"}}承認されました{{else}}差し戻されました{{end}}。{{if .Comment}}
+コメント: {{.Comment}}{{end}}`),
+		model.LocaleEn: newNotificationTemplate(
+			`Timesheet {{.Status}}`,
+			`Your timesheet for the week of {{.Week}} was {{.Status}}.{{if .Comment}}
+Comment: {{.Comment}}{{end}}`),
+	},
+	model.NotificationBudgetWarning: {
+		model.LocaleJa: newNotificationTemplate(
+			"予算の消化率が {{.Percent}}% に達しました",
+			"プロジェクト {{.Project}} の作業時間が予算の {{.Percent}}% に達しました（{{.Used}} / {{.Budget}} 時間）。"),
+		model.LocaleEn: newNotificationTemplate(
+			"Budget {{.Percent}}% used",
+			"Project {{.Project}} has used {{.Percent}}% of its budget hours ({{.Used}} / {{.Budget}} hours)."),
+	},
+	model.NotificationClockInReminder: {
+		model.LocaleJa: newNotificationTemplate(
+			"出勤の打刻を忘れていませんか",
+			"始業時刻（{{.Start}}）を過ぎましたが、本日の出勤の打刻がありません。"),
+		model.LocaleEn: newNotificationTemplate(
+			"Did you forget to clock in?",
+			"Your shift started at {{.Start}}, but you have not clocked in today."),
+	},
+	model.NotificationClockOutReminder: {
+		model.LocaleJa: newNotificationTemplate(
+			"退勤の打刻を忘れていませんか",
+			"終業時刻（{{.End}}）を過ぎましたが、本日の退勤の打刻がありません。"),
+		model.LocaleEn: newNotificationTemplate(
+			"Did you forget to clock out?",
+			"Your shift ended at {{.End}}, but you have not clocked out today."),
+	},
+}
+
+// renderNotification は指定した言語の件名と本文を作る。未対応の言語は日本語にする
+func renderNotification(event string, locale string, data map[string]string) (string, string, error) {
+	templates, ok := notificationTemplates[event]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification event: %s", event)
+	}
+	tmpl, ok := templates[locale]
+	if !ok {
+		tmpl = templates[model.LocaleJa]
+	}
+	var title, body strings.Builder
+	if err := tmpl.title.Execute(&title, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return title.String(), body.String(), nil
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"go-rest-api/webhook"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type INotificationUsecase interface {
+	Notify(userId uint, event string, data map[string]string) error
+	GetNotifications(userId uint, unreadOnly bool) ([]model.NotificationResponse, error)
+	CountUnread(userId uint) (int64, error)
+	MarkRead(userId uint, notificationId uint) error
+	MarkAllRead(userId uint) error
+	GetSettings(userId uint) (model.NotificationSettingsResponse, error)
+	UpdateSettings(setting model.NotificationSetting, preferences []model.NotificationPreference) (model.NotificationSettingsResponse, error)
+}
+
+type notificationUsecase struct {
+	nr repository.INotificationRepository
+	ur repository.IUserRepository
+	nv validator.INotificationValidator
+	m  mailer.IMailer
+	wc webhook.IWebhookClient
+}
+
+func NewNotificationUsecase(nr repository.INotificationRepository, ur repository.IUserRepository, nv validator.INotificationValidator, m mailer.IMailer, wc webhook.IWebhookClient) INotificationUsecase {
+	return &notificationUsecase{nr, ur, nv, m, wc}
+}
+
+func toNotificationResponse(notification model.Notification) model.NotificationResponse {
+	return model.NotificationResponse{
+		ID:        notification.ID,
+		Event:     notification.Event,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// loadSettings は未登録の項目を既定値（日本語・Webhook なし・全チャネル受信）で補った設定を返す
+func (nu *notificationUsecase) loadSettings(userId uint) (model.NotificationSetting, map[string]bool, error) {
+	setting := model.NotificationSetting{}
+	if err := nu.nr.GetSetting(&setting, userId); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.NotificationSetting{}, nil, err
+		}
+		setting = model.NotificationSetting{UserID: userId, Locale: model.LocaleJa}
+	}
+	preferences := []model.NotificationPreference{}
+	if err := nu.nr.GetPreferences(&preferences, userId); err != nil {
+		return model.NotificationSetting{}, nil, err
+	}
+	enabled := map[string]bool{}
+	for _, event := range model.NotificationEvents {
+		for _, channel := range model.NotificationChannels {
+			enabled[event+":"+channel] = true
+		}
+	}
+	for _, v := range preferences {
+		enabled[v.Event+":"+v.Channel] = v.Enabled
+	}
+	return setting, enabled, nil
+}
+
+// Notify はユーザーの設定に従って通知を送る。受信箱への登録は同期で行い、
+// メールと Webhook は呼び出し元の処理を待たせないよう非同期で送信して失敗はログに残す
+func (nu *notificationUsecase) Notify(userId uint, event string, data map[string]string) error {
+	user := model.User{}
+	if err := nu.ur.GetUserById(&user, userId); err != nil {
+		return err
+	}
+	setting, enabled, err := nu.loadSettings(userId)
+	if err != nil {
+		return err
+	}
+	title, body, err := renderNotification(event, setting.Locale, data)
+	if err != nil {
+		return err
+	}
+	if enabled[event+":"+model.NotificationChannelInApp] {
+		notification := model.Notification{UserID: userId, Event: event, Title: title, Body: body}
+		if err := nu.nr.CreateNotification(&notification); err != nil {
+			return err
+		}
+	}
+	if user.ErasedAt != nil {
+		return nil
+	}
+	sendEmail := enabled[event+":"+model.NotificationChannelEmail] && user.Email != ""
+	sendWebhook := enabled[event+":"+model.NotificationChannelWebhook] && setting.WebhookURL != ""
+	go func() {
+		if sendEmail {
+			if err := nu.m.Send(user.Email, title, body); err != nil {
+				log.Printf("failed to send %s notification to user %d by email: %v", event, userId, err)
+			}
+		}
+		if sendWebhook {
+			message := model.WebhookMessage{Text: title + "\n" + body, Event: event}
+			if err := nu.wc.Post(setting.WebhookURL, message); err != nil {
+				log.Printf("failed to send %s notification to user %d by webhook: %v", event, userId, err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (nu *notificationUsecase) GetNotifications(userId uint, unreadOnly bool) ([]model.NotificationResponse, error) {
+	notifications := []model.Notification{}
+	if err := nu.nr.GetNotifications(&notifications, userId, unreadOnly); err != nil {
+		return nil, err
+	}
+	resNotifications := make([]model.NotificationResponse, len(notifications))
+	for i, v := range notifications {
+		resNotifications[i] = toNotificationResponse(v)
+	}
+	return resNotifications, nil
+}
+
+func (nu *notificationUsecase) CountUnread(userId uint) (int64, error) {
+	return nu.nr.CountUnread(userId)
+}
+
+func (nu *notificationUsecase) MarkRead(userId uint, notificationId uint) error {
+	return nu.nr.MarkRead(userId, notificationId, time.Now())
+}
+
+func (nu *notificationUsecase) MarkAllRead(userId uint) error {
+	return nu.nr.MarkAllRead(userId, time.Now())
+}
+
+func (nu *notificationUsecase) GetSettings(userId uint) (model.NotificationSettingsResponse, error) {
+	setting, enabled, err := nu.loadSettings(userId)
+	if err != nil {
+		return model.NotificationSettingsResponse{}, err
+	}
+	res := model.NotificationSettingsResponse{
+		Locale:                  setting.Locale,
+		WebhookURL:              setting.WebhookURL,
+		ClockInReminderMinutes:  model.DefaultClockInReminderMinutes,
+		ClockOutReminderMinutes: model.DefaultClockOutReminderMinutes,
+		Preferences:             []model.NotificationPreferenceResponse{},
+	}
+	if setting.ClockInReminderMinutes != nil {
+		res.ClockInReminderMinutes = *setting.ClockInReminderMinutes
+	}
+	if setting.ClockOutReminderMinutes != nil {
+		res.ClockOutReminderMinutes = *setting.ClockOutReminderMinutes
+	}
+	for _, event := range model.NotificationEvents {
+		for _, channel := range model.NotificationChannels {
+			res.Preferences = append(res.Preferences, model.NotificationPreferenceResponse{
+				Event:   event,
+				Channel: channel,
+				Enabled: enabled[event+":"+channel],
+			})
+		}
+	}
+	return res, nil
+}
+
+// UpdateSettings は指定されたイベント・チャネルの受信可否のみ更新し、指定のないものは変更しない
+func (nu *notificationUsecase) UpdateSettings(setting model.NotificationSetting, preferences []model.NotificationPreference) (model.NotificationSettingsResponse, error) {
+	if setting.Locale == "" {
+		setting.Locale = model.LocaleJa
+	}
+	if err := nu.nv.NotificationSettingValidate(setting); err != nil {
+		return model.NotificationSettingsResponse{}, err
+	}
+	for i := range preferences {
+		preferences[i].UserID = setting.UserID
+		if err := nu.nv.NotificationPreferenceValidate(preferences[i]); err != nil {
+			return model.NotificationSettingsResponse{}, err
+		}
+	}
+	if err := nu.nr.SaveSettings(&setting, preferences); err != nil {
+		return model.NotificationSettingsResponse{}, err
+	}
+	return nu.GetSettings(setting.UserID)
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"log"
+	"sync"
+	"time"
+)
+
+// OutboxHandler はドメインイベントを受け取る購読者。同じイベントが複数回届くことがあるため冪等に処理する
+type OutboxHandler func(event model.OutboxEvent) error
+
+type IOutboxUsecase interface {
+	Subscribe(eventType string, handler OutboxHandler)
+	DispatchPending(now time.Time) (int, error)
+}
+
+type outboxUsecase struct {
+	or       repository.IOutboxRepository
+	mu       sync.RWMutex
+	handlers map[string][]OutboxHandler
+}
+
+func NewOutboxUsecase(or repository.IOutboxRepository) IOutboxUsecase {
+	return &outboxUsecase{or: or, handlers: map[string][]OutboxHandler{}}
+}
+
+// outboxBackoff は n 回目の失敗後に次の試行までに待つ時間。5 秒から倍々に延ばし、1 時間で頭打ちにする
+func outboxBackoff(attempts int) time.Duration {
+	backoff := 5 * time.Second
+	for i := 1; i < attempts && backoff < time.Hour; i++ {
+		backoff *= 2
+	}
+	if backoff > time.Hour {
+		backoff = time.Hour
+	}
+	return backoff
+}
+
+func (ou *outboxUsecase) Subscribe(eventType string, handler OutboxHandler) {
+	ou.mu.Lock()
+	defer ou.mu.Unlock()
+	ou.handlers[eventType] = append(ou.handlers[eventType], handler)
+}
+
+// dispatch はイベントを購読者全員に渡す。1人でも失敗すればイベントは未処理のまま残り、
+// 次回は成功した購読者にも再度届く（at-least-once）
+func (ou *outboxUsecase) dispatch(event model.OutboxEvent) error {
+	ou.mu.RLock()
+	handlers := ou.handlers[event.Type]
+	ou.mu.RUnlock()
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DispatchPending は未処理のイベントを発生順に購読者へ届け、処理できた件数を返す
+func (ou *outboxUsecase) DispatchPending(now time.Time) (int, error) {
+	events := []model.OutboxEvent{}
+	if err := ou.or.GetPendingEvents(&events, now, 100); err != nil {
+		return 0, err
+	}
+	processed := 0
+	for i := range events {
+		event := &events[i]
+		if err := ou.dispatch(*event); err != nil {
+			log.Printf("failed to dispatch outbox event %d (%s): %v", event.ID, event.Type, err)
+			event.Attempts++
+			event.LastError = err.Error()
+			event.NextAttemptAt = time.Now().Add(outboxBackoff(event.Attempts))
+			if err := ou.or.MarkFailed(event); err != nil {
+				return processed, err
+			}
+			continue
+		}
+		if err := ou.or.MarkProcessed(event.ID, time.Now()); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
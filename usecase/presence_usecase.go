@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/presence"
+	"go-rest-api/repository"
+	"log"
+	"time"
+)
+
+type IPresenceUsecase interface {
+	GetSnapshot(department string) ([]model.PresenceEvent, error)
+	Subscribe(department string) (<-chan model.PresenceEvent, func())
+	NotifyRecord(record model.AttendanceRecord)
+}
+
+type presenceUsecase struct {
+	ar  repository.IAttendanceRecordRepository
+	ur  repository.IUserRepository
+	hub presence.IHub
+}
+
+func NewPresenceUsecase(ar repository.IAttendanceRecordRepository, ur repository.IUserRepository, hub presence.IHub) IPresenceUsecase {
+	return &presenceUsecase{ar, ur, hub}
+}
+
+// presenceStatus は当日の勤怠記録から在席状況を判定する
+func presenceStatus(record *model.AttendanceRecord) string {
+	switch {
+	case record == nil:
+		return model.AttendanceStatusNotClockedIn
+	case record.ClockOutTime.IsZero() && record.OpenBreak() != nil:
+		return model.AttendanceStatusOnBreak
+	case record.ClockOutTime.IsZero():
+		return model.AttendanceStatusWorking
+	default:
+		return model.AttendanceStatusClockedOut
+	}
+}
+
+// presenceAt は在席状況が最後に変わった時刻（出勤・休憩の開始と終了・退勤のうち最も遅いもの）を返す
+func presenceAt(record model.AttendanceRecord) time.Time {
+	at := record.ClockInTime
+	for _, b := range record.Breaks {
+		if b.StartedAt.After(at) {
+			at = b.StartedAt
+		}
+		if b.EndedAt != nil && b.EndedAt.After(at) {
+			at = *b.EndedAt
+		}
+	}
+	if record.ClockOutTime.After(at) {
+		at = record.ClockOutTime
+	}
+	return at
+}
+
+// GetSnapshot は在籍中のユーザー全員の今日の在席状況を返す。department が空なら全部署
+func (pu *presenceUsecase) GetSnapshot(department string) ([]model.PresenceEvent, error) {
+	now := time.Now()
+	users := []model.User{}
+	records := []model.AttendanceRecord{}
+	if department == "" {
+		if _, err := pu.ar.GetAllUsers(&users, model.UserFilter{}, model.PageQuery{}); err != nil {
+			return nil, err
+		}
+		if err := pu.ar.GetRecordsByDate(&records, now, ""); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := pu.ur.GetUsersByDepartment(&users, department); err != nil {
+			return nil, err
+		}
+		if err := pu.ar.GetRecordsByDateDepartment(&records, now, department, ""); err != nil {
+			return nil, err
+		}
+	}
+	recordMap := map[uint]*model.AttendanceRecord{}
+	for i := range records {
+		recordMap[records[i].UserID] = &records[i]
+	}
+	snapshot := []model.PresenceEvent{}
+	for _, u := range users {
+		if !u.IsActive(now) {
+			continue
+		}
+		event := model.PresenceEvent{
+			UserID:     u.ID,
+			Name:       u.Name,
+			Department: u.Department,
+			Status:     presenceStatus(recordMap[u.ID]),
+		}
+		if r := recordMap[u.ID]; r != nil {
+			event.At = presenceAt(*r)
+		}
+		snapshot = append(snapshot, event)
+	}
+	return snapshot, nil
+}
+
+func (pu *presenceUsecase) Subscribe(department string) (<-chan model.PresenceEvent, func()) {
+	return pu.hub.Subscribe(department)
+}
+
+// NotifyRecord は出勤・休憩・退勤の記録後に在席状況の変化を配信する。失敗しても打刻自体は成功させる
+func (pu *presenceUsecase) NotifyRecord(record model.AttendanceRecord) {
+	user := model.User{}
+	if err := pu.ur.GetUserById(&user, record.UserID); err != nil {
+		log.Printf("failed to publish presence of user %d: %v", record.UserID, err)
+		return
+	}
+	event := model.PresenceEvent{
+		UserID:     user.ID,
+		Name:       user.Name,
+		Department: user.Department,
+		Status:     presenceStatus(&record),
+		At:         presenceAt(record),
+	}
+	pu.hub.Publish(event)
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"time"
+)
+
+type IPrivacyUsecase interface {
+	ExportPersonalData(userId uint) (model.PersonalDataExport, error)
+	ErasePersonalData(userId uint) error
+}
+
+type privacyUsecase struct {
+	ur  repository.IUserRepository
+	ar  repository.IAttendanceRecordRepository
+	tr  repository.ITaskRepository
+	rlr repository.IReportingLineRepository
+	ir  repository.IInvitationRepository
+	ter repository.ITimeEntryRepository
+	tsr repository.ITimesheetRepository
+	tar repository.ITaskActivityRepository
+	nr  repository.INotificationRepository
+	lr  repository.ILeaveRepository
+	hr  repository.IHolidayWorkRequestRepository
+}
+
+func NewPrivacyUsecase(ur repository.IUserRepository, ar repository.IAttendanceRecordRepository, tr repository.ITaskRepository, rlr repository.IReportingLineRepository, ir repository.IInvitationRepository, ter repository.ITimeEntryRepository, tsr repository.ITimesheetRepository, tar repository.ITaskActivityRepository, nr repository.INotificationRepository, lr repository.ILeaveRepository, hr repository.IHolidayWorkRequestRepository) IPrivacyUsecase {
+	return &privacyUsecase{ur, ar, tr, rlr, ir, ter, tsr, tar, nr, lr, hr}
+}
+
+func (pu *privacyUsecase) ExportPersonalData(userId uint) (model.PersonalDataExport, error) {
+	// 退職・削除済みのユーザーも開示対象に含める
+	user := model.User{}
+	if err := pu.ur.GetUserByIdUnscoped(&user, userId); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export := model.PersonalDataExport{
+		ExportedAt: time.Now(),
+		Profile: model.PersonalDataProfile{
+			ID:              user.ID,
+			Email:           user.Email,
+			Name:            user.Name,
+			Department:      user.Department,
+			Role:            user.Role,
+			ExternalID:      user.ExternalID,
+			HireDate:        user.HireDate,
+			TerminationDate: user.TerminationDate,
+			DeactivatedAt:   user.DeactivatedAt,
+			EmploymentType:  user.EmploymentTypeCode(),
+			CreatedAt:       user.CreatedAt,
+			UpdatedAt:       user.UpdatedAt,
+		},
+	}
+
+	records := []model.AttendanceRecord{}
+	if _, err := pu.ar.GetAllRecords(&records, userId, model.AttendanceRecordFilter{}, model.PageQuery{}); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.AttendanceRecords = make([]model.AttendanceRecordResponse, len(records))
+	for i, v := range records {
+		export.AttendanceRecords[i] = model.AttendanceRecordResponse{
+			ID:            v.ID,
+			UserID:        v.UserID,
+			ClockInTime:   v.ClockInTime,
+			ClockOutTime:  v.ClockOutTime,
+			WorkedMinutes: v.WorkedMinutes(),
+			CreatedAt:     v.CreatedAt,
+			UpdatedAt:     v.UpdatedAt,
+			Breaks:        v.BreakResponses(),
+		}
+	}
+
+	tasks := []model.Task{}
+	if _, err := pu.tr.GetAllTasks(&tasks, userId, model.TaskFilter{}, model.PageQuery{}); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.Tasks = make([]model.TaskResponse, len(tasks))
+	for i, v := range tasks {
+		export.Tasks[i] = toTaskResponse(v)
+	}
+
+	entries := []model.TimeEntry{}
+	if err := pu.ter.GetEntriesByUser(&entries, userId); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.TimeEntries = make([]model.TimeEntryResponse, len(entries))
+	for i, v := range entries {
+		export.TimeEntries[i] = toTimeEntryResponse(v, export.ExportedAt)
+	}
+
+	lines := []model.ReportingLine{}
+	if err := pu.rlr.GetLinesByUser(&lines, userId); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.ReportingLines = make([]model.ReportingLineResponse, len(lines))
+	for i, v := range lines {
+		export.ReportingLines[i] = model.ReportingLineResponse{
+			ID:            v.ID,
+			UserID:        v.UserID,
+			ManagerID:     v.ManagerID,
+			Type:          v.Type,
+			EffectiveFrom: v.EffectiveFrom,
+			EffectiveTo:   v.EffectiveTo,
+		}
+	}
+
+	invitations := []model.Invitation{}
+	if err := pu.ir.GetInvitationsByUser(&invitations, userId, user.Email); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.Invitations = make([]model.InvitationResponse, len(invitations))
+	for i, v := range invitations {
+		export.Invitations[i] = toInvitationResponse(v, export.ExportedAt)
+	}
+
+	timesheets := []model.Timesheet{}
+	if err := pu.tsr.GetTimesheetsByUser(&timesheets, userId); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.Timesheets = make([]model.TimesheetResponse, len(timesheets))
+	for i, v := range timesheets {
+		export.Timesheets[i] = toTimesheetResponse(v)
+	}
+
+	comments := []model.TaskComment{}
+	if err := pu.tar.GetCommentsByAuthor(&comments, userId); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.TaskComments = make([]model.TaskCommentResponse, len(comments))
+	for i, v := range comments {
+		export.TaskComments[i] = toTaskCommentResponse(v)
+	}
+
+	activities := []model.TaskActivity{}
+	if err := pu.tar.GetActivitiesByActor(&activities, userId); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.TaskActivities = make([]model.TaskActivityResponse, len(activities))
+	for i, v := range activities {
+		export.TaskActivities[i] = toTaskActivityResponse(v)
+	}
+
+	notifications := []model.Notification{}
+	if err := pu.nr.GetNotifications(&notifications, userId, false); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.Notifications = make([]model.NotificationResponse, len(notifications))
+	for i, v := range notifications {
+		export.Notifications[i] = toNotificationResponse(v)
+	}
+
+	export.LeaveRecords = []model.LeaveRecord{}
+	if err := pu.lr.GetLeaves(&export.LeaveRecords, userId, nil, nil); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+
+	holidayWorkRequests := []model.HolidayWorkRequest{}
+	if err := pu.hr.GetRequestsByUser(&holidayWorkRequests, userId); err != nil {
+		return model.PersonalDataExport{}, err
+	}
+	export.HolidayWorkRequests = toHolidayWorkRequestResponses(holidayWorkRequests)
+	return export, nil
+}
+
+func (pu *privacyUsecase) ErasePersonalData(userId uint) error {
+	user := model.User{}
+	if err := pu.ur.GetUserByIdUnscoped(&user, userId); err != nil {
+		return err
+	}
+	if user.ErasedAt != nil {
+		return model.NewConflictError("already_erased", "personal data has already been erased")
+	}
+	return pu.ur.AnonymizeUser(userId, time.Now())
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"fmt"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type IProjectBudgetUsecase interface {
+	GetBurndown(projectId uint, runRateWeeks int) (model.ProjectBurndownResponse, error)
+	GetBudgetAlerts(projectId uint) ([]model.BudgetAlert, error)
+	CheckThresholds(projectId uint) ([]model.BudgetAlert, error)
+}
+
+type projectBudgetUsecase struct {
+	pr  repository.IProjectRepository
+	ter repository.ITimeEntryRepository
+	nu  INotificationUsecase
+}
+
+func NewProjectBudgetUsecase(pr repository.IProjectRepository, ter repository.ITimeEntryRepository, nu INotificationUsecase) IProjectBudgetUsecase {
+	return &projectBudgetUsecase{pr, ter, nu}
+}
+
+// DefaultRunRateWeeks は消化ペースの算出に使う直近の週数
+const DefaultRunRateWeeks = 4
+
+// budgetAlertThresholds は警告を出す消化率（%）。BUDGET_ALERT_THRESHOLDS=80,100 のように指定する
+func budgetAlertThresholds() []int {
+	thresholds := []int{}
+	for _, v := range strings.Split(os.Getenv("BUDGET_ALERT_THRESHOLDS"), ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n > 0 {
+			thresholds = append(thresholds, n)
+		}
+	}
+	if len(thresholds) == 0 {
+		return []int{80, 100}
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// weekStart は日付を含む週の月曜0時を返す
+func weekStart(t time.Time) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+func (pbu *projectBudgetUsecase) usedHours(projectId uint) ([]model.WeeklyMinutesRow, float64, error) {
+	rows := []model.WeeklyMinutesRow{}
+	if err := pbu.ter.GetProjectWeeklyMinutes(&rows, projectId); err != nil {
+		return nil, 0, err
+	}
+	var minutes float64
+	for _, v := range rows {
+		minutes += v.Minutes
+	}
+	return rows, minutes / 60, nil
+}
+
+func (pbu *projectBudgetUsecase) GetBurndown(projectId uint, runRateWeeks int) (model.ProjectBurndownResponse, error) {
+	if runRateWeeks <= 0 {
+		runRateWeeks = DefaultRunRateWeeks
+	}
+	project := model.Project{}
+	if err := pbu.pr.GetProjectById(&project, projectId); err != nil {
+		return model.ProjectBurndownResponse{}, err
+	}
+	rows, used, err := pbu.usedHours(projectId)
+	if err != nil {
+		return model.ProjectBurndownResponse{}, err
+	}
+	// しきい値の確認と通知は作業時間の記録時に行うため、ここでは記録済みの警告を返すだけにする
+	alerts := []model.BudgetAlert{}
+	if err := pbu.pr.GetBudgetAlerts(&alerts, projectId); err != nil {
+		return model.ProjectBurndownResponse{}, err
+	}
+
+	res := model.ProjectBurndownResponse{
+		ProjectID:      project.ID,
+		Code:           project.Code,
+		Name:           project.Name,
+		BudgetHours:    project.BudgetHours,
+		UsedHours:      roundTo2(used),
+		RemainingHours: roundTo2(project.BudgetHours - used),
+		Weeks:          []model.BurndownWeek{},
+		Alerts:         alerts,
+	}
+	if project.BudgetHours > 0 {
+		res.UsedPercent = roundTo2(used / project.BudgetHours * 100)
+	}
+
+	// 開始日（なければ最初の記録）の週から今週まで、記録のない週も0時間で埋める
+	now := time.Now()
+	current := weekStart(now)
+	start := current
+	if project.StartDate != nil {
+		start = weekStart(*project.StartDate)
+	}
+	if len(rows) > 0 && weekStart(rows[0].WeekStart).Before(start) {
+		start = weekStart(rows[0].WeekStart)
+	}
+	minutesByWeek := map[time.Time]float64{}
+	for _, v := range rows {
+		minutesByWeek[weekStart(v.WeekStart)] += v.Minutes
+	}
+	var cumulative float64
+	for w := start; !w.After(current); w = w.AddDate(0, 0, 7) {
+		hours := minutesByWeek[w] / 60
+		cumulative += hours
+		res.Weeks = append(res.Weeks, model.BurndownWeek{
+			WeekStart:       w,
+			Hours:           roundTo2(hours),
+			CumulativeHours: roundTo2(cumulative),
+			RemainingHours:  roundTo2(project.BudgetHours - cumulative),
+		})
+	}
+
+	// 消化ペースは今週を除く直近 runRateWeeks 週の平均。完了した週がなければ今週の実績を使う
+	var recent float64
+	count := 0
+	for w := current.AddDate(0, 0, -7); count < runRateWeeks && !w.Before(start); w = w.AddDate(0, 0, -7) {
+		recent += minutesByWeek[w] / 60
+		count++
+	}
+	if count == 0 {
+		recent = minutesByWeek[current] / 60
+		count = 1
+	}
+	res.RunRateHoursPerWeek = roundTo2(recent / float64(count))
+
+	if project.BudgetHours > 0 && res.RunRateHoursPerWeek > 0 {
+		remaining := project.BudgetHours - used
+		if remaining <= 0 {
+			res.ProjectedExhaustionAt = &now
+		} else {
+			days := remaining / res.RunRateHoursPerWeek * 7
+			exhaustion := now.Add(time.Duration(days * float64(24*time.Hour)))
+			res.ProjectedExhaustionAt = &exhaustion
+		}
+	}
+	return res, nil
+}
+
+func (pbu *projectBudgetUsecase) GetBudgetAlerts(projectId uint) ([]model.BudgetAlert, error) {
+	alerts := []model.BudgetAlert{}
+	if err := pbu.pr.GetBudgetAlerts(&alerts, projectId); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// CheckThresholds は消化率が超えたしきい値ごとに警告イベントを記録し、記録済みのものも含めて返す
+func (pbu *projectBudgetUsecase) CheckThresholds(projectId uint) ([]model.BudgetAlert, error) {
+	project := model.Project{}
+	if err := pbu.pr.GetProjectById(&project, projectId); err != nil {
+		return nil, err
+	}
+	if project.BudgetHours > 0 {
+		_, used, err := pbu.usedHours(projectId)
+		if err != nil {
+			return nil, err
+		}
+		percent := used / project.BudgetHours * 100
+		for _, threshold := range budgetAlertThresholds() {
+			if percent < float64(threshold) {
+				break
+			}
+			alert := model.BudgetAlert{
+				ProjectID:        projectId,
+				ThresholdPercent: threshold,
+				UsedHours:        roundTo2(used),
+				BudgetHours:      project.BudgetHours,
+			}
+			created, err := pbu.pr.CreateBudgetAlert(&alert)
+			if err != nil {
+				return nil, err
+			}
+			if created {
+				log.Printf("project %s used %.0f%% of budget hours (%.2f / %.2f)", project.Code, percent, used, project.BudgetHours)
+				pbu.notifyLead(project, alert)
+			}
+		}
+	}
+	return pbu.GetBudgetAlerts(projectId)
+}
+
+// notifyLead はプロジェクトリーダーに予算の警告を通知する。リーダー未設定のプロジェクトはログのみ
+func (pbu *projectBudgetUsecase) notifyLead(project model.Project, alert model.BudgetAlert) {
+	if project.LeadID == nil {
+		return
+	}
+	data := map[string]string{
+		"Project": project.Code + " " + project.Name,
+		"Percent": strconv.Itoa(alert.ThresholdPercent),
+		"Used":    fmt.Sprintf("%.2f", alert.UsedHours),
+		"Budget":  fmt.Sprintf("%.2f", alert.BudgetHours),
+	}
+	if err := pbu.nu.Notify(*project.LeadID, model.NotificationBudgetWarning, data); err != nil {
+		log.Printf("failed to notify budget alert of project %s: %v", project.Code, err)
+	}
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type IProjectUsecase interface {
+	GetAllProjects() ([]model.ProjectResponse, error)
+	GetProjectById(projectId uint) (model.ProjectResponse, error)
+	CreateProject(project model.Project) (model.ProjectResponse, error)
+	UpdateProject(project model.Project, projectId uint) (model.ProjectResponse, error)
+	DeleteProject(projectId uint) error
+	ReplaceRates(projectId uint, rates []model.ProjectRate) (model.ProjectResponse, error)
+	ReplaceMembers(projectId uint, members []model.ProjectMember) (model.ProjectResponse, error)
+}
+
+type projectUsecase struct {
+	pr repository.IProjectRepository
+	pv validator.IProjectValidator
+}
+
+func NewProjectUsecase(pr repository.IProjectRepository, pv validator.IProjectValidator) IProjectUsecase {
+	return &projectUsecase{pr, pv}
+}
+
+func toProjectResponse(project model.Project) model.ProjectResponse {
+	rates := make([]model.ProjectRateResponse, len(project.Rates))
+	for i, v := range project.Rates {
+		rates[i] = model.ProjectRateResponse{Role: v.Role, HourlyRate: v.HourlyRate}
+	}
+	members := make([]model.ProjectMemberResponse, len(project.Members))
+	for i, v := range project.Members {
+		members[i] = model.ProjectMemberResponse{UserID: v.UserID, BillingRole: v.BillingRole}
+	}
+	return model.ProjectResponse{
+		ID:          project.ID,
+		Code:        project.Code,
+		Name:        project.Name,
+		Client:      project.Client,
+		BudgetHours: project.BudgetHours,
+		HourlyRate:  project.HourlyRate,
+		LeadID:      project.LeadID,
+		StartDate:   project.StartDate,
+		EndDate:     project.EndDate,
+		Rates:       rates,
+		Members:     members,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	}
+}
+
+func (pu *projectUsecase) GetAllProjects() ([]model.ProjectResponse, error) {
+	projects := []model.Project{}
+	if err := pu.pr.GetAllProjects(&projects); err != nil {
+		return nil, err
+	}
+	resProjects := make([]model.ProjectResponse, len(projects))
+	for i, v := range projects {
+		resProjects[i] = toProjectResponse(v)
+	}
+	return resProjects, nil
+}
+
+func (pu *projectUsecase) GetProjectById(projectId uint) (model.ProjectResponse, error) {
+	project := model.Project{}
+	if err := pu.pr.GetProjectById(&project, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+func (pu *projectUsecase) CreateProject(project model.Project) (model.ProjectResponse, error) {
+	if err := pu.pv.ProjectValidate(project); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.CreateProject(&project); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+func (pu *projectUsecase) UpdateProject(project model.Project, projectId uint) (model.ProjectResponse, error) {
+	if err := pu.pv.ProjectValidate(project); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.UpdateProject(&project, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+func (pu *projectUsecase) DeleteProject(projectId uint) error {
+	return pu.pr.DeleteProject(projectId)
+}
+
+func (pu *projectUsecase) ReplaceRates(projectId uint, rates []model.ProjectRate) (model.ProjectResponse, error) {
+	seen := map[string]bool{}
+	for _, v := range rates {
+		if err := pu.pv.ProjectRateValidate(v); err != nil {
+			return model.ProjectResponse{}, err
+		}
+		if seen[v.Role] {
+			return model.ProjectResponse{}, model.NewInvalidError("duplicate_rate", "duplicate rate for role %s", v.Role)
+		}
+		seen[v.Role] = true
+	}
+	project := model.Project{}
+	if err := pu.pr.GetProjectById(&project, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.ReplaceRates(projectId, rates); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.GetProjectById(&project, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+// ReplaceMembers はメンバーの請求ロールを置き換える。記録済みの作業時間の単価は変わらず、以降の記録に使われる
+func (pu *projectUsecase) ReplaceMembers(projectId uint, members []model.ProjectMember) (model.ProjectResponse, error) {
+	seen := map[uint]bool{}
+	for _, v := range members {
+		if err := pu.pv.ProjectMemberValidate(v); err != nil {
+			return model.ProjectResponse{}, err
+		}
+		if seen[v.UserID] {
+			return model.ProjectResponse{}, model.NewInvalidError("duplicate_member", "duplicate member %d", v.UserID)
+		}
+		seen[v.UserID] = true
+	}
+	project := model.Project{}
+	if err := pu.pr.GetProjectById(&project, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.ReplaceMembers(projectId, members); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.GetProjectById(&project, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(project), nil
+}
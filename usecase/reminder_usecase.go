@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"log"
+	"time"
+)
+
+type IReminderUsecase interface {
+	SendReminders(now time.Time) (int, error)
+}
+
+type reminderUsecase struct {
+	rr repository.IReminderRepository
+	nu INotificationUsecase
+}
+
+func NewReminderUsecase(rr repository.IReminderRepository, nu INotificationUsecase) IReminderUsecase {
+	return &reminderUsecase{rr, nu}
+}
+
+// remind は当日まだ送っていなければリマインダーを送る。
+// 先に記録して同時に実行されても二重に送らないようにし、送信に失敗した場合は記録を取り消す
+func (ru *reminderUsecase) remind(userId uint, day time.Time, reminderType string, event string, data map[string]string) (bool, error) {
+	reminder := model.Reminder{UserID: userId, Date: day, Type: reminderType}
+	created, err := ru.rr.CreateReminder(&reminder)
+	if err != nil || !created {
+		return false, err
+	}
+	if err := ru.nu.Notify(userId, event, data); err != nil {
+		if derr := ru.rr.DeleteReminder(reminder.ID); derr != nil {
+			log.Printf("failed to release reminder %d of user %d: %v", reminder.ID, userId, derr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// SendReminders は所定の始業から指定の分数が過ぎても出勤していない人、終業から指定の分数が過ぎても
+// 退勤していない人にリマインダーを送る。土日と会社の休日、休暇の日は送らない（半休の日は退勤のリマインダーのみ）
+func (ru *reminderUsecase) SendReminders(now time.Time) (int, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return 0, nil
+	}
+	targets := []model.ReminderTarget{}
+	if err := ru.rr.GetReminderTargets(&targets, day); err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, v := range targets {
+		startOfDay, err := time.ParseInLocation("15:04", v.ScheduledStartTime, time.Local)
+		if err != nil {
+			log.Printf("invalid scheduled start time %q of user %d: %v", v.ScheduledStartTime, v.UserID, err)
+			continue
+		}
+		start := day.Add(time.Duration(startOfDay.Hour())*time.Hour + time.Duration(startOfDay.Minute())*time.Minute)
+		end := start.Add(time.Duration(v.ScheduledMinutes) * time.Minute)
+
+		var ok bool
+		switch {
+		case !v.ClockedIn && !v.HalfDayLeave && !now.Before(start.Add(time.Duration(v.ClockInReminderMinutes)*time.Minute)) && now.Before(end):
+			ok, err = ru.remind(v.UserID, day, model.ReminderClockIn, model.NotificationClockInReminder,
+				map[string]string{"Start": start.Format("15:04")})
+		case v.OpenRecord && !now.Before(end.Add(time.Duration(v.ClockOutReminderMinutes)*time.Minute)):
+			ok, err = ru.remind(v.UserID, day, model.ReminderClockOut, model.NotificationClockOutReminder,
+				map[string]string{"End": end.Format("15:04")})
+		}
+		if err != nil {
+			// 1人の失敗で他のユーザーへの送信を止めない
+			log.Printf("failed to send reminder to user %d: %v", v.UserID, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"time"
+)
+
+type IReportingLineUsecase interface {
+	GetTeam(managerId uint, date time.Time) ([]model.TeamMemberResponse, error)
+	GetReportingLines(userId uint) ([]model.ReportingLineResponse, error)
+	CreateReportingLine(line model.ReportingLine) (model.ReportingLineResponse, error)
+	EndReportingLine(lineId uint, effectiveTo time.Time) error
+	DeleteReportingLine(lineId uint) error
+}
+
+type reportingLineUsecase struct {
+	rlr repository.IReportingLineRepository
+	ar  repository.IAttendanceRecordRepository
+	rlv validator.IReportingLineValidator
+}
+
+func NewReportingLineUsecase(rlr repository.IReportingLineRepository, ar repository.IAttendanceRecordRepository, rlv validator.IReportingLineValidator) IReportingLineUsecase {
+	return &reportingLineUsecase{rlr, ar, rlv}
+}
+
+// collectReports は managerId 配下の直属・間接の部下を階層順に返す
+func (rlu *reportingLineUsecase) collectReports(managerId uint, date time.Time) ([]model.TeamMemberResponse, error) {
+	members := []model.TeamMemberResponse{}
+	visited := map[uint]bool{managerId: true}
+	level := []uint{managerId}
+	for depth := 1; len(level) > 0; depth++ {
+		lines := []model.ReportingLine{}
+		if err := rlu.rlr.GetActiveLinesByManagers(&lines, level, date); err != nil {
+			return nil, err
+		}
+		next := []uint{}
+		for _, line := range lines {
+			// 代理・兼務で同じ部下に複数の経路がある場合は最初の経路のみ採用
+			if visited[line.UserID] {
+				continue
+			}
+			visited[line.UserID] = true
+			next = append(next, line.UserID)
+			members = append(members, model.TeamMemberResponse{
+				User: model.UserResponse{
+					ID:         line.User.ID,
+					Email:      line.User.Email,
+					Department: line.User.Department,
+					Name:       line.User.Name,
+					IsActive:   line.User.IsActive(date),
+				},
+				ManagerID: line.ManagerID,
+				LineType:  line.Type,
+				Depth:     depth,
+				Status:    model.AttendanceStatusNotClockedIn,
+			})
+		}
+		level = next
+	}
+	return members, nil
+}
+
+func (rlu *reportingLineUsecase) GetTeam(managerId uint, date time.Time) ([]model.TeamMemberResponse, error) {
+	members, err := rlu.collectReports(managerId, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return members, nil
+	}
+	userIds := make([]uint, len(members))
+	for i, m := range members {
+		userIds[i] = m.User.ID
+	}
+	records := []model.AttendanceRecord{}
+	if err := rlu.ar.GetRecordsByUsersDate(&records, userIds, date); err != nil {
+		return nil, err
+	}
+	// clock_in_time 昇順なので、各ユーザーの最後のレコードが最新の状態
+	latest := map[uint]model.AttendanceRecord{}
+	for _, r := range records {
+		latest[r.UserID] = r
+	}
+	for i, m := range members {
+		r, ok := latest[m.User.ID]
+		if !ok {
+			continue
+		}
+		clockIn := r.ClockInTime
+		members[i].ClockInTime = &clockIn
+		if r.ClockOutTime.IsZero() {
+			members[i].Status = model.AttendanceStatusWorking
+			continue
+		}
+		clockOut := r.ClockOutTime
+		members[i].ClockOutTime = &clockOut
+		members[i].Status = model.AttendanceStatusClockedOut
+	}
+	return members, nil
+}
+
+func (rlu *reportingLineUsecase) GetReportingLines(userId uint) ([]model.ReportingLineResponse, error) {
+	lines := []model.ReportingLine{}
+	if err := rlu.rlr.GetLinesByUser(&lines, userId); err != nil {
+		return nil, err
+	}
+	resLines := make([]model.ReportingLineResponse, len(lines))
+	for i, v := range lines {
+		resLines[i] = model.ReportingLineResponse{
+			ID:            v.ID,
+			UserID:        v.UserID,
+			ManagerID:     v.ManagerID,
+			Type:          v.Type,
+			EffectiveFrom: v.EffectiveFrom,
+			EffectiveTo:   v.EffectiveTo,
+		}
+	}
+	return resLines, nil
+}
+
+func (rlu *reportingLineUsecase) CreateReportingLine(line model.ReportingLine) (model.ReportingLineResponse, error) {
+	if line.Type == "" {
+		line.Type = model.ReportingLineTypePrimary
+	}
+	if err := rlu.rlv.ReportingLineValidate(line); err != nil {
+		return model.ReportingLineResponse{}, err
+	}
+	// 上司が部下の配下にいる場合は循環するため登録しない
+	reports, err := rlu.collectReports(line.UserID, line.EffectiveFrom)
+	if err != nil {
+		return model.ReportingLineResponse{}, err
+	}
+	for _, r := range reports {
+		if r.User.ID == line.ManagerID {
+			return model.ReportingLineResponse{}, model.NewInvalidError("reporting_cycle", "reporting line would create a cycle")
+		}
+	}
+	if err := rlu.rlr.CreateReportingLine(&line); err != nil {
+		return model.ReportingLineResponse{}, err
+	}
+	return model.ReportingLineResponse{
+		ID:            line.ID,
+		UserID:        line.UserID,
+		ManagerID:     line.ManagerID,
+		Type:          line.Type,
+		EffectiveFrom: line.EffectiveFrom,
+		EffectiveTo:   line.EffectiveTo,
+	}, nil
+}
+
+func (rlu *reportingLineUsecase) EndReportingLine(lineId uint, effectiveTo time.Time) error {
+	return rlu.rlr.EndReportingLine(lineId, effectiveTo)
+}
+
+func (rlu *reportingLineUsecase) DeleteReportingLine(lineId uint) error {
+	return rlu.rlr.DeleteReportingLine(lineId)
+}
@@ -0,0 +1,547 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrScimNotFound      = errors.New("resource not found")
+	ErrScimConflict      = errors.New("resource already exists")
+	ErrScimInvalidFilter = errors.New("unsupported filter")
+	// 以下はリクエストの誤りで、詳細を付けてラップして返す
+	ErrScimInvalidSyntax = errors.New("invalid request")
+	ErrScimInvalidValue  = errors.New("invalid value")
+	ErrScimInvalidPath   = errors.New("invalid path")
+)
+
+type IScimUsecase interface {
+	ListUsers(filter string, startIndex int, count int) (model.ScimListResponse, error)
+	GetUser(userId uint) (model.ScimUser, error)
+	CreateUser(scimUser model.ScimUser) (model.ScimUser, error)
+	ReplaceUser(userId uint, scimUser model.ScimUser) (model.ScimUser, error)
+	PatchUser(userId uint, patch model.ScimPatchRequest) (model.ScimUser, error)
+	DeleteUser(userId uint) error
+	ListGroups(filter string, startIndex int, count int) (model.ScimListResponse, error)
+	GetGroup(name string) (model.ScimGroup, error)
+	CreateGroup(group model.ScimGroup) (model.ScimGroup, error)
+	ReplaceGroup(name string, group model.ScimGroup) (model.ScimGroup, error)
+	PatchGroup(name string, patch model.ScimPatchRequest) (model.ScimGroup, error)
+	DeleteGroup(name string) error
+}
+
+type scimUsecase struct {
+	ur repository.IUserRepository
+	uv validator.IUserValidator
+	iu IInvitationUsecase
+}
+
+func NewScimUsecase(ur repository.IUserRepository, uv validator.IUserValidator, iu IInvitationUsecase) IScimUsecase {
+	return &scimUsecase{ur, uv, iu}
+}
+
+var scimFilterPattern = regexp.MustCompile(`^\s*([\w.:]+)\s+eq\s+"([^"]*)"\s*$`)
+
+// parseScimFilter は IdP が送ってくる `attr eq "value"` 形式のフィルタのみ解釈する
+func parseScimFilter(filter string) (string, string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+	m := scimFilterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", ErrScimInvalidFilter
+	}
+	return m[1], m[2], nil
+}
+
+func toScimUser(user model.User) model.ScimUser {
+	active := user.IsActive(time.Now())
+	created := user.CreatedAt
+	updated := user.UpdatedAt
+	return model.ScimUser{
+		Schemas:     []string{model.ScimUserSchema, model.ScimEnterpriseSchema},
+		ID:          strconv.FormatUint(uint64(user.ID), 10),
+		ExternalID:  user.ExternalID,
+		UserName:    user.Email,
+		Name:        &model.ScimName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []model.ScimEmail{{Value: user.Email, Primary: true}},
+		Active:      &active,
+		Enterprise:  &model.ScimEnterpriseUser{Department: user.Department},
+		Meta:        &model.ScimMeta{ResourceType: "User", Created: &created, LastModified: &updated},
+	}
+}
+
+func toScimGroup(name string, members []model.User) model.ScimGroup {
+	group := model.ScimGroup{
+		Schemas:     []string{model.ScimGroupSchema},
+		ID:          name,
+		DisplayName: name,
+		Members:     []model.ScimMember{},
+		Meta:        &model.ScimMeta{ResourceType: "Group"},
+	}
+	for _, m := range members {
+		group.Members = append(group.Members, model.ScimMember{
+			Value:   strconv.FormatUint(uint64(m.ID), 10),
+			Display: m.Name,
+		})
+	}
+	return group
+}
+
+func scimPage(startIndex int, count int) (int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count <= 0 || count > 200 {
+		count = 100
+	}
+	return startIndex, count
+}
+
+func (su *scimUsecase) getUser(user *model.User, userId uint) error {
+	if err := su.ur.GetUserById(user, userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrScimNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// applyScimUser は SCIM リソースの属性を User に反映する
+func applyScimUser(user *model.User, scimUser model.ScimUser) {
+	user.Email = strings.ToLower(scimUser.UserName)
+	user.ExternalID = scimUser.ExternalID
+	switch {
+	case scimUser.DisplayName != "":
+		user.Name = scimUser.DisplayName
+	case scimUser.Name != nil && scimUser.Name.Formatted != "":
+		user.Name = scimUser.Name.Formatted
+	}
+	if scimUser.Enterprise != nil {
+		user.Department = scimUser.Enterprise.Department
+	}
+}
+
+func (su *scimUsecase) validateUser(user model.User) error {
+	// パスワードは招待メールから本人が設定するため、検証用にダミー値を入れる
+	user.Password = "provisioned"
+	return su.uv.UserValidate(user)
+}
+
+func (su *scimUsecase) setActive(user *model.User, active bool) error {
+	if active == user.IsActive(time.Now()) {
+		return nil
+	}
+	if active {
+		user.DeactivatedAt = nil
+	} else {
+		now := time.Now()
+		user.DeactivatedAt = &now
+	}
+	return su.ur.SetDeactivatedAt(user.ID, user.DeactivatedAt)
+}
+
+func (su *scimUsecase) ListUsers(filter string, startIndex int, count int) (model.ScimListResponse, error) {
+	attr, value, err := parseScimFilter(filter)
+	if err != nil {
+		return model.ScimListResponse{}, err
+	}
+	email, externalId := "", ""
+	switch attr {
+	case "":
+	case "userName", "emails.value":
+		email = strings.ToLower(value)
+	case "externalId":
+		externalId = value
+	default:
+		return model.ScimListResponse{}, ErrScimInvalidFilter
+	}
+	startIndex, count = scimPage(startIndex, count)
+	users := []model.User{}
+	total, err := su.ur.FindUsers(&users, email, externalId, startIndex-1, count)
+	if err != nil {
+		return model.ScimListResponse{}, err
+	}
+	resources := make([]model.ScimUser, len(users))
+	for i, u := range users {
+		resources[i] = toScimUser(u)
+	}
+	return model.ScimListResponse{
+		Schemas:      []string{model.ScimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+func (su *scimUsecase) GetUser(userId uint) (model.ScimUser, error) {
+	user := model.User{}
+	if err := su.getUser(&user, userId); err != nil {
+		return model.ScimUser{}, err
+	}
+	return toScimUser(user), nil
+}
+
+func (su *scimUsecase) CreateUser(scimUser model.ScimUser) (model.ScimUser, error) {
+	user := model.User{}
+	applyScimUser(&user, scimUser)
+	if err := su.validateUser(user); err != nil {
+		return model.ScimUser{}, err
+	}
+	count, err := su.ur.CountUsersByEmail(user.Email)
+	if err != nil {
+		return model.ScimUser{}, err
+	}
+	if count > 0 {
+		return model.ScimUser{}, ErrScimConflict
+	}
+	if scimUser.Active != nil && !*scimUser.Active {
+		now := time.Now()
+		user.DeactivatedAt = &now
+	}
+	if err := su.ur.CreateUser(&user); err != nil {
+		return model.ScimUser{}, err
+	}
+	if user.DeactivatedAt == nil {
+		// メール送信に失敗してもプロビジョニング自体は成功扱いにする
+		if err := su.iu.InviteExistingUser(user); err != nil {
+			log.Printf("failed to invite provisioned user %d: %v", user.ID, err)
+		}
+	}
+	return toScimUser(user), nil
+}
+
+// checkEmailAvailable は userName（メールアドレス）を他のユーザーが使っていないかを確認する
+func (su *scimUsecase) checkEmailAvailable(user model.User) error {
+	count, err := su.ur.CountOtherUsersByEmail(user.Email, user.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrScimConflict
+	}
+	return nil
+}
+
+func (su *scimUsecase) ReplaceUser(userId uint, scimUser model.ScimUser) (model.ScimUser, error) {
+	user := model.User{}
+	if err := su.getUser(&user, userId); err != nil {
+		return model.ScimUser{}, err
+	}
+	if scimUser.Enterprise == nil {
+		scimUser.Enterprise = &model.ScimEnterpriseUser{}
+	}
+	applyScimUser(&user, scimUser)
+	if err := su.validateUser(user); err != nil {
+		return model.ScimUser{}, err
+	}
+	if err := su.checkEmailAvailable(user); err != nil {
+		return model.ScimUser{}, err
+	}
+	if err := su.ur.UpdateProfile(&user); err != nil {
+		return model.ScimUser{}, err
+	}
+	if scimUser.Active != nil {
+		if err := su.setActive(&user, *scimUser.Active); err != nil {
+			return model.ScimUser{}, err
+		}
+	}
+	return toScimUser(user), nil
+}
+
+// scimBool は "False" のように文字列で送ってくる IdP にも対応する
+func scimBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%w: invalid boolean value: %v", ErrScimInvalidValue, value)
+}
+
+func (su *scimUsecase) PatchUser(userId uint, patch model.ScimPatchRequest) (model.ScimUser, error) {
+	user := model.User{}
+	if err := su.getUser(&user, userId); err != nil {
+		return model.ScimUser{}, err
+	}
+	var active *bool
+	apply := func(path string, value interface{}) error {
+		switch strings.ToLower(path) {
+		case "active":
+			b, err := scimBool(value)
+			if err != nil {
+				return err
+			}
+			active = &b
+		case "username":
+			user.Email = strings.ToLower(fmt.Sprint(value))
+		case "displayname", "name.formatted":
+			user.Name = fmt.Sprint(value)
+		case "externalid":
+			user.ExternalID = fmt.Sprint(value)
+		case strings.ToLower(model.ScimEnterpriseSchema + ":department"):
+			user.Department = fmt.Sprint(value)
+		}
+		return nil
+	}
+	for _, op := range patch.Operations {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+		case "remove":
+			if strings.EqualFold(op.Path, model.ScimEnterpriseSchema+":department") {
+				user.Department = ""
+			}
+			continue
+		default:
+			return model.ScimUser{}, fmt.Errorf("%w: unsupported patch op: %s", ErrScimInvalidSyntax, op.Op)
+		}
+		if op.Path != "" {
+			if err := apply(op.Path, op.Value); err != nil {
+				return model.ScimUser{}, err
+			}
+			continue
+		}
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return model.ScimUser{}, fmt.Errorf("%w: patch value must be an object when path is omitted", ErrScimInvalidSyntax)
+		}
+		for k, v := range values {
+			if err := apply(k, v); err != nil {
+				return model.ScimUser{}, err
+			}
+		}
+	}
+	if err := su.validateUser(user); err != nil {
+		return model.ScimUser{}, err
+	}
+	if err := su.checkEmailAvailable(user); err != nil {
+		return model.ScimUser{}, err
+	}
+	if err := su.ur.UpdateProfile(&user); err != nil {
+		return model.ScimUser{}, err
+	}
+	if active != nil {
+		if err := su.setActive(&user, *active); err != nil {
+			return model.ScimUser{}, err
+		}
+	}
+	return toScimUser(user), nil
+}
+
+func (su *scimUsecase) DeleteUser(userId uint) error {
+	user := model.User{}
+	if err := su.getUser(&user, userId); err != nil {
+		return err
+	}
+	return su.ur.DeleteUser(&user)
+}
+
+func (su *scimUsecase) ListGroups(filter string, startIndex int, count int) (model.ScimListResponse, error) {
+	attr, value, err := parseScimFilter(filter)
+	if err != nil {
+		return model.ScimListResponse{}, err
+	}
+	if attr != "" && attr != "displayName" {
+		return model.ScimListResponse{}, ErrScimInvalidFilter
+	}
+	departments := []string{}
+	if err := su.ur.GetDepartments(&departments, value); err != nil {
+		return model.ScimListResponse{}, err
+	}
+	startIndex, count = scimPage(startIndex, count)
+	total := len(departments)
+	from := startIndex - 1
+	if from > total {
+		from = total
+	}
+	to := from + count
+	if to > total {
+		to = total
+	}
+	resources := []model.ScimGroup{}
+	for _, name := range departments[from:to] {
+		members := []model.User{}
+		if err := su.ur.GetUsersByDepartment(&members, name); err != nil {
+			return model.ScimListResponse{}, err
+		}
+		resources = append(resources, toScimGroup(name, members))
+	}
+	return model.ScimListResponse{
+		Schemas:      []string{model.ScimListSchema},
+		TotalResults: int64(total),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+func (su *scimUsecase) GetGroup(name string) (model.ScimGroup, error) {
+	members := []model.User{}
+	if err := su.ur.GetUsersByDepartment(&members, name); err != nil {
+		return model.ScimGroup{}, err
+	}
+	if len(members) == 0 {
+		return model.ScimGroup{}, ErrScimNotFound
+	}
+	return toScimGroup(name, members), nil
+}
+
+func parseScimMemberIds(members []model.ScimMember) ([]uint, error) {
+	ids := []uint{}
+	for _, m := range members {
+		id, err := strconv.ParseUint(m.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid member id: %s", ErrScimInvalidValue, m.Value)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+func (su *scimUsecase) CreateGroup(group model.ScimGroup) (model.ScimGroup, error) {
+	if strings.TrimSpace(group.DisplayName) == "" {
+		return model.ScimGroup{}, fmt.Errorf("%w: displayName is required", ErrScimInvalidValue)
+	}
+	ids, err := parseScimMemberIds(group.Members)
+	if err != nil {
+		return model.ScimGroup{}, err
+	}
+	// 部署はユーザーの属性として保持しているため、メンバーのいない部署は作成時点では保存されない
+	if err := su.ur.SetDepartment(ids, group.DisplayName); err != nil {
+		return model.ScimGroup{}, err
+	}
+	members := []model.User{}
+	if err := su.ur.GetUsersByDepartment(&members, group.DisplayName); err != nil {
+		return model.ScimGroup{}, err
+	}
+	return toScimGroup(group.DisplayName, members), nil
+}
+
+func (su *scimUsecase) ReplaceGroup(name string, group model.ScimGroup) (model.ScimGroup, error) {
+	if strings.TrimSpace(group.DisplayName) == "" {
+		group.DisplayName = name
+	}
+	ids, err := parseScimMemberIds(group.Members)
+	if err != nil {
+		return model.ScimGroup{}, err
+	}
+	if err := su.ur.ClearDepartment(name, nil); err != nil {
+		return model.ScimGroup{}, err
+	}
+	if err := su.ur.SetDepartment(ids, group.DisplayName); err != nil {
+		return model.ScimGroup{}, err
+	}
+	members := []model.User{}
+	if err := su.ur.GetUsersByDepartment(&members, group.DisplayName); err != nil {
+		return model.ScimGroup{}, err
+	}
+	return toScimGroup(group.DisplayName, members), nil
+}
+
+var scimMemberPathPattern = regexp.MustCompile(`^members\[value eq "([^"]+)"\]$`)
+
+// scimMembersValue は PATCH の value（[{"value": "1"}, ...]）をメンバー一覧に変換する
+func scimMembersValue(value interface{}) []model.ScimMember {
+	members := []model.ScimMember{}
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			members = append(members, model.ScimMember{Value: fmt.Sprint(m["value"])})
+		}
+	}
+	return members
+}
+
+func (su *scimUsecase) PatchGroup(name string, patch model.ScimPatchRequest) (model.ScimGroup, error) {
+	current := name
+	for _, op := range patch.Operations {
+		path := op.Path
+		switch strings.ToLower(op.Op) {
+		case "add":
+			if !strings.EqualFold(path, "members") {
+				return model.ScimGroup{}, fmt.Errorf("%w: unsupported patch path: %s", ErrScimInvalidPath, path)
+			}
+			ids, err := parseScimMemberIds(scimMembersValue(op.Value))
+			if err != nil {
+				return model.ScimGroup{}, err
+			}
+			if err := su.ur.SetDepartment(ids, current); err != nil {
+				return model.ScimGroup{}, err
+			}
+		case "remove":
+			members := scimMembersValue(op.Value)
+			if m := scimMemberPathPattern.FindStringSubmatch(path); m != nil {
+				members = append(members, model.ScimMember{Value: m[1]})
+			} else if !strings.EqualFold(path, "members") {
+				return model.ScimGroup{}, fmt.Errorf("%w: unsupported patch path: %s", ErrScimInvalidPath, path)
+			}
+			ids, err := parseScimMemberIds(members)
+			if err != nil {
+				return model.ScimGroup{}, err
+			}
+			if len(members) == 0 {
+				ids = nil
+			}
+			if err := su.ur.ClearDepartment(current, ids); err != nil {
+				return model.ScimGroup{}, err
+			}
+		case "replace":
+			switch {
+			case strings.EqualFold(path, "displayName"):
+				renamed := fmt.Sprint(op.Value)
+				users := []model.User{}
+				if err := su.ur.GetUsersByDepartment(&users, current); err != nil {
+					return model.ScimGroup{}, err
+				}
+				ids := make([]uint, len(users))
+				for i, u := range users {
+					ids[i] = u.ID
+				}
+				if err := su.ur.SetDepartment(ids, renamed); err != nil {
+					return model.ScimGroup{}, err
+				}
+				current = renamed
+			case strings.EqualFold(path, "members"):
+				ids, err := parseScimMemberIds(scimMembersValue(op.Value))
+				if err != nil {
+					return model.ScimGroup{}, err
+				}
+				if err := su.ur.ClearDepartment(current, nil); err != nil {
+					return model.ScimGroup{}, err
+				}
+				if err := su.ur.SetDepartment(ids, current); err != nil {
+					return model.ScimGroup{}, err
+				}
+			default:
+				return model.ScimGroup{}, fmt.Errorf("%w: unsupported patch path: %s", ErrScimInvalidPath, path)
+			}
+		default:
+			return model.ScimGroup{}, fmt.Errorf("%w: unsupported patch op: %s", ErrScimInvalidSyntax, op.Op)
+		}
+	}
+	members := []model.User{}
+	if err := su.ur.GetUsersByDepartment(&members, current); err != nil {
+		return model.ScimGroup{}, err
+	}
+	return toScimGroup(current, members), nil
+}
+
+func (su *scimUsecase) DeleteGroup(name string) error {
+	return su.ur.ClearDepartment(name, nil)
+}
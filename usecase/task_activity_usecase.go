@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type ITaskActivityUsecase interface {
+	GetComments(userId uint, taskId uint) ([]model.TaskCommentResponse, error)
+	CreateComment(comment model.TaskComment) (model.TaskCommentResponse, error)
+	UpdateComment(comment model.TaskComment) (model.TaskCommentResponse, error)
+	DeleteComment(userId uint, taskId uint, commentId uint) error
+	GetActivity(userId uint, taskId uint) ([]model.TaskActivityResponse, error)
+}
+
+type taskActivityUsecase struct {
+	tar repository.ITaskActivityRepository
+	tu  ITaskUsecase
+	tv  validator.ITaskValidator
+}
+
+func NewTaskActivityUsecase(tar repository.ITaskActivityRepository, tu ITaskUsecase, tv validator.ITaskValidator) ITaskActivityUsecase {
+	return &taskActivityUsecase{tar, tu, tv}
+}
+
+func toTaskCommentResponse(comment model.TaskComment) model.TaskCommentResponse {
+	revisions := make([]model.TaskCommentRevisionResponse, len(comment.Revisions))
+	for i, v := range comment.Revisions {
+		revisions[i] = model.TaskCommentRevisionResponse{Body: v.Body, EditedAt: v.EditedAt}
+	}
+	return model.TaskCommentResponse{
+		ID:         comment.ID,
+		TaskID:     comment.TaskID,
+		AuthorID:   comment.AuthorID,
+		AuthorName: comment.Author.Name,
+		Body:       comment.Body,
+		Edited:     len(comment.Revisions) > 0,
+		Revisions:  revisions,
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
+	}
+}
+
+func toTaskActivityResponse(activity model.TaskActivity) model.TaskActivityResponse {
+	return model.TaskActivityResponse{
+		ID:        activity.ID,
+		TaskID:    activity.TaskID,
+		Type:      activity.Type,
+		ActorID:   activity.ActorID,
+		ActorName: activity.Actor.Name,
+		From:      activity.FromValue,
+		To:        activity.ToValue,
+		CreatedAt: activity.CreatedAt,
+	}
+}
+
+// checkTaskAccess はタスクを閲覧できるユーザーか確認する（作成者・担当者・部署ボードのメンバー）
+func (tau *taskActivityUsecase) checkTaskAccess(userId uint, taskId uint) error {
+	_, err := tau.tu.GetTaskById(userId, taskId)
+	return err
+}
+
+func (tau *taskActivityUsecase) GetComments(userId uint, taskId uint) ([]model.TaskCommentResponse, error) {
+	if err := tau.checkTaskAccess(userId, taskId); err != nil {
+		return nil, err
+	}
+	comments := []model.TaskComment{}
+	if err := tau.tar.GetComments(&comments, taskId); err != nil {
+		return nil, err
+	}
+	resComments := make([]model.TaskCommentResponse, len(comments))
+	for i, v := range comments {
+		resComments[i] = toTaskCommentResponse(v)
+	}
+	return resComments, nil
+}
+
+func (tau *taskActivityUsecase) CreateComment(comment model.TaskComment) (model.TaskCommentResponse, error) {
+	if err := tau.tv.TaskCommentValidate(comment); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	if err := tau.checkTaskAccess(comment.AuthorID, comment.TaskID); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	if err := tau.tar.CreateComment(&comment); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	if err := tau.tar.GetCommentById(&comment, comment.TaskID, comment.ID); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	return toTaskCommentResponse(comment), nil
+}
+
+func (tau *taskActivityUsecase) UpdateComment(comment model.TaskComment) (model.TaskCommentResponse, error) {
+	if err := tau.tv.TaskCommentValidate(comment); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	if err := tau.checkTaskAccess(comment.AuthorID, comment.TaskID); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	current := model.TaskComment{}
+	if err := tau.tar.GetCommentById(&current, comment.TaskID, comment.ID); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	if current.AuthorID != comment.AuthorID {
+		return model.TaskCommentResponse{}, model.NewForbiddenError("not_comment_author", "only the author can edit this comment")
+	}
+	if current.Body == comment.Body {
+		return toTaskCommentResponse(current), nil
+	}
+	if err := tau.tar.UpdateComment(&current, comment.Body); err != nil {
+		return model.TaskCommentResponse{}, err
+	}
+	return toTaskCommentResponse(current), nil
+}
+
+func (tau *taskActivityUsecase) DeleteComment(userId uint, taskId uint, commentId uint) error {
+	if err := tau.checkTaskAccess(userId, taskId); err != nil {
+		return err
+	}
+	comment := model.TaskComment{}
+	if err := tau.tar.GetCommentById(&comment, taskId, commentId); err != nil {
+		return err
+	}
+	if comment.AuthorID != userId {
+		return model.NewForbiddenError("not_comment_author", "only the author can delete this comment")
+	}
+	return tau.tar.DeleteComment(comment, userId)
+}
+
+// GetActivity はタスクの変更履歴とコメントを時系列にまとめて返す
+func (tau *taskActivityUsecase) GetActivity(userId uint, taskId uint) ([]model.TaskActivityResponse, error) {
+	if err := tau.checkTaskAccess(userId, taskId); err != nil {
+		return nil, err
+	}
+	activities := []model.TaskActivity{}
+	if err := tau.tar.GetActivities(&activities, taskId); err != nil {
+		return nil, err
+	}
+	comments := []model.TaskComment{}
+	if err := tau.tar.GetComments(&comments, taskId); err != nil {
+		return nil, err
+	}
+	commentMap := map[uint]model.TaskCommentResponse{}
+	for _, v := range comments {
+		commentMap[v.ID] = toTaskCommentResponse(v)
+	}
+	resActivities := make([]model.TaskActivityResponse, len(activities))
+	for i, v := range activities {
+		resActivities[i] = toTaskActivityResponse(v)
+		// コメントの追加時のみ本文を添える。編集は同じコメントの履歴として参照できる
+		if v.CommentID != nil && v.Type == model.TaskActivityCommentAdded {
+			if comment, ok := commentMap[*v.CommentID]; ok {
+				resActivities[i].Comment = &comment
+			}
+		}
+	}
+	return resActivities, nil
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+type ITaskRecurrenceUsecase interface {
+	GetRecurrences(userId uint) ([]model.TaskRecurrenceResponse, error)
+	GetRecurrenceById(userId uint, recurrenceId uint) (model.TaskRecurrenceResponse, error)
+	CreateRecurrence(recurrence model.TaskRecurrence) (model.TaskRecurrenceResponse, error)
+	UpdateRecurrence(recurrence model.TaskRecurrence, userId uint, recurrenceId uint) (model.TaskRecurrenceResponse, error)
+	DeleteRecurrence(userId uint, recurrenceId uint) error
+	GenerateOccurrences(now time.Time) (int64, error)
+}
+
+type taskRecurrenceUsecase struct {
+	rr repository.ITaskRecurrenceRepository
+	ur repository.IUserRepository
+	tv validator.ITaskValidator
+}
+
+func NewTaskRecurrenceUsecase(rr repository.ITaskRecurrenceRepository, ur repository.IUserRepository, tv validator.ITaskValidator) ITaskRecurrenceUsecase {
+	return &taskRecurrenceUsecase{rr, ur, tv}
+}
+
+// recurrenceHorizon は何日先の回まで作成しておくか。RECURRENCE_HORIZON_DAYS で変更できる
+func recurrenceHorizon() int {
+	days := 14
+	if v, err := strconv.Atoi(os.Getenv("RECURRENCE_HORIZON_DAYS")); err == nil && v > 0 {
+		days = v
+	}
+	return days
+}
+
+func toTaskRecurrenceResponse(recurrence model.TaskRecurrence) model.TaskRecurrenceResponse {
+	return model.TaskRecurrenceResponse{
+		ID:             recurrence.ID,
+		Title:          recurrence.Title,
+		Description:    recurrence.Description,
+		Priority:       recurrence.Priority,
+		EstimatedHours: recurrence.EstimatedHours,
+		Visibility:     recurrence.Visibility,
+		Department:     recurrence.Department,
+		AssigneeID:     recurrence.AssigneeID,
+		ProjectID:      recurrence.ProjectID,
+		RRule:          recurrence.RRule,
+		StartDate:      recurrence.StartDate,
+		Until:          recurrence.Until,
+		GeneratedUntil: recurrence.GeneratedUntil,
+		CreatedAt:      recurrence.CreatedAt,
+		UpdatedAt:      recurrence.UpdatedAt,
+	}
+}
+
+// validate はシリーズから作られるタスクとして妥当か、部署ボードに出す場合は所属部署か確認する
+func (tru *taskRecurrenceUsecase) validate(recurrence *model.TaskRecurrence) error {
+	if recurrence.Priority == "" {
+		recurrence.Priority = model.TaskPriorityMedium
+	}
+	if recurrence.Visibility == "" {
+		recurrence.Visibility = model.TaskVisibilityPrivate
+	}
+	if recurrence.Visibility == model.TaskVisibilityPrivate {
+		recurrence.Department = ""
+	}
+	if err := tru.tv.TaskRecurrenceValidate(*recurrence); err != nil {
+		return err
+	}
+	if err := tru.tv.TaskValidate(recurrence.NewOccurrence(recurrence.StartDate)); err != nil {
+		return err
+	}
+	if recurrence.Visibility == model.TaskVisibilityDepartment {
+		user := model.User{}
+		if err := tru.ur.GetUserById(&user, recurrence.UserID); err != nil {
+			return err
+		}
+		if !user.CanViewDepartment(recurrence.Department) {
+			return errTaskForbidden
+		}
+	}
+	return nil
+}
+
+// generate は生成済みの翌日（過去は作らない）から期間の先までの回を作成する
+func (tru *taskRecurrenceUsecase) generate(recurrence model.TaskRecurrence, now time.Time) (int64, error) {
+	rule, err := model.ParseRecurrenceRule(recurrence.RRule)
+	if err != nil {
+		return 0, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := today
+	if recurrence.GeneratedUntil != nil && !recurrence.GeneratedUntil.Before(from) {
+		from = recurrence.GeneratedUntil.AddDate(0, 0, 1)
+	}
+	to := today.AddDate(0, 0, recurrenceHorizon())
+	if recurrence.Until != nil && recurrence.Until.Before(to) {
+		to = *recurrence.Until
+	}
+	if from.After(to) {
+		return 0, nil
+	}
+	start := time.Date(recurrence.StartDate.Year(), recurrence.StartDate.Month(), recurrence.StartDate.Day(), 0, 0, 0, 0, time.Local)
+
+	exceptions := []time.Time{}
+	if err := tru.rr.GetExceptionDates(&exceptions, recurrence.ID); err != nil {
+		return 0, err
+	}
+	skip := map[string]bool{}
+	for _, v := range exceptions {
+		skip[v.Format("2006-01-02")] = true
+	}
+	tasks := []model.Task{}
+	for _, d := range rule.Occurrences(start, from, to) {
+		if !skip[d.Format("2006-01-02")] {
+			tasks = append(tasks, recurrence.NewOccurrence(d))
+		}
+	}
+	return tru.rr.CreateOccurrences(recurrence.ID, tasks, to)
+}
+
+func (tru *taskRecurrenceUsecase) GetRecurrences(userId uint) ([]model.TaskRecurrenceResponse, error) {
+	recurrences := []model.TaskRecurrence{}
+	if err := tru.rr.GetRecurrencesByUser(&recurrences, userId); err != nil {
+		return nil, err
+	}
+	resRecurrences := make([]model.TaskRecurrenceResponse, len(recurrences))
+	for i, v := range recurrences {
+		resRecurrences[i] = toTaskRecurrenceResponse(v)
+	}
+	return resRecurrences, nil
+}
+
+func (tru *taskRecurrenceUsecase) GetRecurrenceById(userId uint, recurrenceId uint) (model.TaskRecurrenceResponse, error) {
+	recurrence := model.TaskRecurrence{}
+	if err := tru.rr.GetRecurrenceById(&recurrence, userId, recurrenceId); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	return toTaskRecurrenceResponse(recurrence), nil
+}
+
+func (tru *taskRecurrenceUsecase) CreateRecurrence(recurrence model.TaskRecurrence) (model.TaskRecurrenceResponse, error) {
+	recurrence.GeneratedUntil = nil
+	if err := tru.validate(&recurrence); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	if err := tru.rr.CreateRecurrence(&recurrence); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	if _, err := tru.generate(recurrence, time.Now()); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	if err := tru.rr.GetRecurrenceById(&recurrence, recurrence.UserID, recurrence.ID); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	return toTaskRecurrenceResponse(recurrence), nil
+}
+
+// UpdateRecurrence はシリーズ全体を変更する。今日以降の未着手の回は作り直し、個別に編集した回や着手済みの回はそのまま残す
+func (tru *taskRecurrenceUsecase) UpdateRecurrence(recurrence model.TaskRecurrence, userId uint, recurrenceId uint) (model.TaskRecurrenceResponse, error) {
+	current := model.TaskRecurrence{}
+	if err := tru.rr.GetRecurrenceById(&current, userId, recurrenceId); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	recurrence.UserID = userId
+	if err := tru.validate(&recurrence); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if err := tru.rr.DeletePendingOccurrences(recurrenceId, today); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	yesterday := today.AddDate(0, 0, -1)
+	recurrence.GeneratedUntil = &yesterday
+	if err := tru.rr.UpdateRecurrence(&recurrence, userId, recurrenceId); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	if _, err := tru.generate(recurrence, now); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	if err := tru.rr.GetRecurrenceById(&recurrence, userId, recurrenceId); err != nil {
+		return model.TaskRecurrenceResponse{}, err
+	}
+	return toTaskRecurrenceResponse(recurrence), nil
+}
+
+// DeleteRecurrence はシリーズを終了する。作成済みの回のうち今日以降の未着手のものは削除し、それ以外は通常のタスクとして残す
+func (tru *taskRecurrenceUsecase) DeleteRecurrence(userId uint, recurrenceId uint) error {
+	recurrence := model.TaskRecurrence{}
+	if err := tru.rr.GetRecurrenceById(&recurrence, userId, recurrenceId); err != nil {
+		return err
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if err := tru.rr.DeletePendingOccurrences(recurrenceId, today); err != nil {
+		return err
+	}
+	return tru.rr.DeleteRecurrence(userId, recurrenceId)
+}
+
+func (tru *taskRecurrenceUsecase) GenerateOccurrences(now time.Time) (int64, error) {
+	recurrences := []model.TaskRecurrence{}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if err := tru.rr.GetActiveRecurrences(&recurrences, today); err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, v := range recurrences {
+		created, err := tru.generate(v, now)
+		if err != nil {
+			// 1件の失敗で他のシリーズの生成を止めない
+			log.Printf("failed to generate occurrences of recurrence %d: %v", v.ID, err)
+			continue
+		}
+		total += created
+	}
+	return total, nil
+}
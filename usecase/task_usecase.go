@@ -1,95 +1,307 @@
 package usecase
 
 import (
+	"errors"
 	"go-rest-api/model"
 	"go-rest-api/repository"
 	"go-rest-api/validator"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type ITaskUsecase interface {
-	GetAllTasks(userId uint) ([]model.TaskResponse, error)
+	GetAllTasks(userId uint, filter model.TaskFilter, page model.PageQuery) ([]model.TaskResponse, model.PageInfo, error)
 	GetTaskById(userId uint, taskId uint) (model.TaskResponse, error)
 	CreateTask(task model.Task) (model.TaskResponse, error)
 	UpdateTask(task model.Task, userId uint, taskId uint) (model.TaskResponse, error)
 	DeleteTask(userId uint, taskId uint) error
+	GetBoardTasks(userId uint, department string, filter model.TaskFilter) ([]model.TaskResponse, error)
+	CreateBoardTask(task model.Task) (model.TaskResponse, error)
+	ClaimTask(userId uint, taskId uint) (model.TaskResponse, error)
 }
 
 type taskUsecase struct {
-	tr repository.ITaskRepository
-	tv validator.ITaskValidator
+	tr  repository.ITaskRepository
+	ur  repository.IUserRepository
+	tar repository.ITaskActivityRepository
+	rr  repository.ITaskRecurrenceRepository
+	tv  validator.ITaskValidator
+}
+
+func NewTaskUsecase(tr repository.ITaskRepository, ur repository.IUserRepository, tar repository.ITaskActivityRepository, rr repository.ITaskRecurrenceRepository, tv validator.ITaskValidator) ITaskUsecase {
+	return &taskUsecase{tr, ur, tar, rr, tv}
+}
+
+// editsOccurrence は繰り返しの回に対してシリーズ由来の内容を変更したかを返す。状態の変更だけなら対象外
+func editsOccurrence(before model.Task, after model.Task) bool {
+	if before.RecurrenceID == nil {
+		return false
+	}
+	return before.Title != after.Title ||
+		before.Description != after.Description ||
+		before.Priority != after.Priority ||
+		formatDueDate(before.DueDate) != formatDueDate(after.DueDate) ||
+		before.EstimatedHours != after.EstimatedHours ||
+		formatAssignee(before.AssigneeID) != formatAssignee(after.AssigneeID) ||
+		formatAssignee(before.ProjectID) != formatAssignee(after.ProjectID)
+}
+
+func formatAssignee(assigneeId *uint) string {
+	if assigneeId == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*assigneeId), 10)
+}
+
+func formatDueDate(dueDate *time.Time) string {
+	if dueDate == nil {
+		return ""
+	}
+	return dueDate.Format("2006-01-02")
+}
+
+// taskChanges は状態・担当者・期日の変更をアクティビティとして返す
+func taskChanges(before model.Task, after model.Task, actorId uint) []model.TaskActivity {
+	activities := []model.TaskActivity{}
+	add := func(activityType string, from string, to string) {
+		if from != to {
+			activities = append(activities, model.TaskActivity{
+				TaskID:    after.ID,
+				ActorID:   actorId,
+				Type:      activityType,
+				FromValue: from,
+				ToValue:   to,
+			})
+		}
+	}
+	add(model.TaskActivityStatusChanged, before.Status, after.Status)
+	add(model.TaskActivityAssigneeChanged, formatAssignee(before.AssigneeID), formatAssignee(after.AssigneeID))
+	add(model.TaskActivityDueDateChanged, formatDueDate(before.DueDate), formatDueDate(after.DueDate))
+	return activities
+}
+
+// recordActivities は履歴を記録する。タスク自体の更新は済んでいるため失敗してもログに残すだけにする
+func (tu *taskUsecase) recordActivities(activities []model.TaskActivity) {
+	if err := tu.tar.CreateActivities(activities); err != nil {
+		log.Printf("failed to record task activity: %v", err)
+	}
+}
+
+var errTaskForbidden = model.NewForbiddenError("task_forbidden", "you do not have permission for this task")
+
+// findTask は自分が作成者・担当者のタスクを探し、なければ閲覧可能な部署ボードのタスクを探す。
+// 部署ボード経由で見つかった場合は shared が true になる
+func (tu *taskUsecase) findTask(task *model.Task, user *model.User, userId uint, taskId uint) (bool, error) {
+	err := tu.tr.GetTaskById(task, userId, taskId)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if err := tu.ur.GetUserById(user, userId); err != nil {
+		return false, err
+	}
+	department := user.Department
+	if user.Role == model.RoleAdmin {
+		department = ""
+	}
+	if err := tu.tr.GetSharedTaskById(task, department, taskId); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func NewTaskUsecase(tr repository.ITaskRepository, tv validator.ITaskValidator) ITaskUsecase {
-	return &taskUsecase{tr, tv}
+func toTaskResponse(task model.Task) model.TaskResponse {
+	return model.TaskResponse{
+		ID:             task.ID,
+		Title:          task.Title,
+		Description:    task.Description,
+		Status:         task.Status,
+		Priority:       task.Priority,
+		DueDate:        task.DueDate,
+		EstimatedHours: task.EstimatedHours,
+		Visibility:     task.Visibility,
+		Department:     task.Department,
+		UserId:         task.UserId,
+		AssigneeID:     task.AssigneeID,
+		ProjectID:      task.ProjectID,
+		RecurrenceID:   task.RecurrenceID,
+		OccurrenceDate: task.OccurrenceDate,
+		Detached:       task.Detached,
+		CreatedAt:      task.CreatedAt,
+		UpdatedAt:      task.UpdatedAt,
+	}
 }
 
-func (tu *taskUsecase) GetAllTasks(userId uint) ([]model.TaskResponse, error) {
+func (tu *taskUsecase) GetAllTasks(userId uint, filter model.TaskFilter, page model.PageQuery) ([]model.TaskResponse, model.PageInfo, error) {
 	tasks := []model.Task{}
-	if err := tu.tr.GetAllTasks(&tasks, userId); err != nil {
-		return nil, err
+	pageInfo, err := tu.tr.GetAllTasks(&tasks, userId, filter, page)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
 	resTasks := []model.TaskResponse{}
 	for _, v := range tasks {
-		t := model.TaskResponse{
-			ID:        v.ID,
-			Title:     v.Title,
-			CreatedAt: v.CreatedAt,
-			UpdatedAt: v.UpdatedAt,
-		}
-		resTasks = append(resTasks, t)
+		resTasks = append(resTasks, toTaskResponse(v))
 	}
-	return resTasks, nil
+	return resTasks, pageInfo, nil
 }
 
 func (tu *taskUsecase) GetTaskById(userId uint, taskId uint) (model.TaskResponse, error) {
 	task := model.Task{}
-	if err := tu.tr.GetTaskById(&task, userId, taskId); err != nil {
+	if _, err := tu.findTask(&task, &model.User{}, userId, taskId); err != nil {
 		return model.TaskResponse{}, err
 	}
-	resTask := model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
-	}
-	return resTask, nil
+	return toTaskResponse(task), nil
 }
 
+// CreateTask は個人用のタスクを作成する。部署ボードのタスクは部署の権限を確認する CreateBoardTask で作成する
 func (tu *taskUsecase) CreateTask(task model.Task) (model.TaskResponse, error) {
+	task.Visibility = model.TaskVisibilityPrivate
+	task.Department = ""
+	return tu.createTask(task)
+}
+
+func (tu *taskUsecase) createTask(task model.Task) (model.TaskResponse, error) {
+	if task.Status == "" {
+		task.Status = model.TaskStatusTodo
+	}
+	if task.Priority == "" {
+		task.Priority = model.TaskPriorityMedium
+	}
 	if err := tu.tv.TaskValidate(task); err != nil {
 		return model.TaskResponse{}, err
 	}
 	if err := tu.tr.CreateTask(&task); err != nil {
 		return model.TaskResponse{}, err
 	}
-	resTask := model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
-	}
-	return resTask, nil
+	tu.recordActivities([]model.TaskActivity{{TaskID: task.ID, ActorID: task.UserId, Type: model.TaskActivityCreated, ToValue: task.Status}})
+	return toTaskResponse(task), nil
 }
 
 func (tu *taskUsecase) UpdateTask(task model.Task, userId uint, taskId uint) (model.TaskResponse, error) {
-	if err := tu.tv.TaskValidate(task); err != nil {
+	current := model.Task{}
+	user := model.User{}
+	shared, err := tu.findTask(&current, &user, userId, taskId)
+	if err != nil {
 		return model.TaskResponse{}, err
 	}
-	if err := tu.tr.UpdateTask(&task, userId, taskId); err != nil {
+	// 公開範囲と部署は作成時に決まり、更新では変更しない
+	task.Visibility = current.Visibility
+	task.Department = current.Department
+	if task.Status == "" {
+		task.Status = current.Status
+	}
+	if task.Priority == "" {
+		task.Priority = current.Priority
+	}
+	if err := tu.tv.TaskValidate(task); err != nil {
 		return model.TaskResponse{}, err
 	}
-	resTask := model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
+	if !current.CanTransitionTo(task.Status) {
+		return model.TaskResponse{}, model.NewInvalidError("invalid_status_transition", "cannot change status from %s to %s", current.Status, task.Status)
 	}
-	return resTask, nil
+	task.Detached = current.Detached || editsOccurrence(current, task)
+	if shared {
+		if err := tu.tr.UpdateSharedTask(&task, current.Department, taskId); err != nil {
+			return model.TaskResponse{}, err
+		}
+	} else {
+		if err := tu.tr.UpdateTask(&task, userId, taskId); err != nil {
+			return model.TaskResponse{}, err
+		}
+	}
+	tu.recordActivities(taskChanges(current, task, userId))
+	return toTaskResponse(task), nil
 }
 
 func (tu *taskUsecase) DeleteTask(userId uint, taskId uint) error {
-	if err := tu.tr.DeleteTask(userId, taskId); err != nil {
+	task := model.Task{}
+	user := model.User{}
+	shared, err := tu.findTask(&task, &user, userId, taskId)
+	if err != nil {
 		return err
 	}
-	return nil
+	// 担当者は削除できない。共有タスクは作成者のほか部署のマネージャーと管理者が削除できる
+	if task.UserId != userId {
+		if !shared {
+			if err := tu.ur.GetUserById(&user, userId); err != nil {
+				return err
+			}
+		}
+		if !task.IsShared() || !user.CanManageDepartment(task.Department) {
+			return errTaskForbidden
+		}
+	}
+	// 繰り返しの回を削除した場合は、同じ日が再生成されないよう例外日として残す
+	if task.RecurrenceID != nil && task.OccurrenceDate != nil {
+		if err := tu.rr.CreateException(*task.RecurrenceID, *task.OccurrenceDate); err != nil {
+			return err
+		}
+	}
+	if task.UserId == userId {
+		return tu.tr.DeleteTask(userId, taskId)
+	}
+	return tu.tr.DeleteSharedTask(task.Department, taskId)
+}
+
+func (tu *taskUsecase) GetBoardTasks(userId uint, department string, filter model.TaskFilter) ([]model.TaskResponse, error) {
+	user := model.User{}
+	if err := tu.ur.GetUserById(&user, userId); err != nil {
+		return nil, err
+	}
+	if department == "" {
+		department = user.Department
+	}
+	if !user.CanViewDepartment(department) {
+		return nil, errTaskForbidden
+	}
+	tasks := []model.Task{}
+	if err := tu.tr.GetBoardTasks(&tasks, department, filter); err != nil {
+		return nil, err
+	}
+	resTasks := []model.TaskResponse{}
+	for _, v := range tasks {
+		resTasks = append(resTasks, toTaskResponse(v))
+	}
+	return resTasks, nil
+}
+
+func (tu *taskUsecase) CreateBoardTask(task model.Task) (model.TaskResponse, error) {
+	user := model.User{}
+	if err := tu.ur.GetUserById(&user, task.UserId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	if task.Department == "" {
+		task.Department = user.Department
+	}
+	if !user.CanViewDepartment(task.Department) {
+		return model.TaskResponse{}, errTaskForbidden
+	}
+	task.Visibility = model.TaskVisibilityDepartment
+	return tu.createTask(task)
+}
+
+func (tu *taskUsecase) ClaimTask(userId uint, taskId uint) (model.TaskResponse, error) {
+	user := model.User{}
+	if err := tu.ur.GetUserById(&user, userId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	task := model.Task{}
+	department := user.Department
+	if user.Role == model.RoleAdmin {
+		department = ""
+	}
+	if err := tu.tr.GetSharedTaskById(&task, department, taskId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	before := task
+	if err := tu.tr.ClaimTask(&task, task.Department, taskId, userId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	tu.recordActivities(taskChanges(before, task, userId))
+	return toTaskResponse(task), nil
 }
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type ITimeAllocationUsecase interface {
+	GetDayAllocations(userId uint, recordId uint) (model.DayAllocationResponse, error)
+	ReplaceDayAllocations(userId uint, recordId uint, allocations []model.TimeAllocation) (model.DayAllocationResponse, error)
+}
+
+type timeAllocationUsecase struct {
+	tar repository.ITimeAllocationRepository
+	ar  repository.IAttendanceRecordRepository
+	tr  repository.ITaskRepository
+	pr  repository.IProjectRepository
+	pv  validator.IProjectValidator
+}
+
+func NewTimeAllocationUsecase(tar repository.ITimeAllocationRepository, ar repository.IAttendanceRecordRepository, tr repository.ITaskRepository, pr repository.IProjectRepository, pv validator.IProjectValidator) ITimeAllocationUsecase {
+	return &timeAllocationUsecase{tar, ar, tr, pr, pv}
+}
+
+func toDayAllocationResponse(record model.AttendanceRecord, allocations []model.TimeAllocation) model.DayAllocationResponse {
+	res := model.DayAllocationResponse{
+		AttendanceRecordID: record.ID,
+		WorkedMinutes:      record.WorkedMinutes(),
+		Allocations:        make([]model.TimeAllocationResponse, len(allocations)),
+	}
+	for i, v := range allocations {
+		res.AllocatedMinutes += v.Minutes
+		res.Allocations[i] = model.TimeAllocationResponse{
+			ID:                 v.ID,
+			AttendanceRecordID: v.AttendanceRecordID,
+			TaskID:             v.TaskID,
+			ProjectID:          v.ProjectID,
+			Minutes:            v.Minutes,
+			Note:               v.Note,
+		}
+	}
+	res.UnallocatedMinutes = res.WorkedMinutes - res.AllocatedMinutes
+	return res
+}
+
+func (tau *timeAllocationUsecase) GetDayAllocations(userId uint, recordId uint) (model.DayAllocationResponse, error) {
+	record := model.AttendanceRecord{}
+	if err := tau.ar.GetRecordById(&record, userId, recordId); err != nil {
+		return model.DayAllocationResponse{}, err
+	}
+	allocations := []model.TimeAllocation{}
+	if err := tau.tar.GetAllocationsByRecord(&allocations, userId, recordId); err != nil {
+		return model.DayAllocationResponse{}, err
+	}
+	return toDayAllocationResponse(record, allocations), nil
+}
+
+func (tau *timeAllocationUsecase) ReplaceDayAllocations(userId uint, recordId uint, allocations []model.TimeAllocation) (model.DayAllocationResponse, error) {
+	record := model.AttendanceRecord{}
+	if err := tau.ar.GetRecordById(&record, userId, recordId); err != nil {
+		return model.DayAllocationResponse{}, err
+	}
+	if record.ClockOutTime.IsZero() {
+		return model.DayAllocationResponse{}, model.NewInvalidError("not_clocked_out", "cannot allocate time before clocking out")
+	}
+	total := 0
+	projects := map[uint]model.Project{}
+	for i := range allocations {
+		a := &allocations[i]
+		a.ID = 0
+		a.UserID = userId
+		a.AttendanceRecordID = recordId
+		if err := tau.pv.TimeAllocationValidate(*a); err != nil {
+			return model.DayAllocationResponse{}, err
+		}
+		// タスク指定の場合はタスクの所属プロジェクトに計上する
+		if a.TaskID != nil {
+			task := model.Task{}
+			if err := tau.tr.GetTaskById(&task, userId, *a.TaskID); err != nil {
+				return model.DayAllocationResponse{}, model.NewNotFoundError("task_not_found", "task %d not found", *a.TaskID)
+			}
+			if a.ProjectID == nil {
+				a.ProjectID = task.ProjectID
+			} else if task.ProjectID != nil && *task.ProjectID != *a.ProjectID {
+				return model.DayAllocationResponse{}, model.NewInvalidError("task_project_mismatch", "task %d does not belong to project %d", *a.TaskID, *a.ProjectID)
+			}
+		}
+		if a.ProjectID != nil {
+			project, ok := projects[*a.ProjectID]
+			if !ok {
+				if err := tau.pr.GetProjectById(&project, *a.ProjectID); err != nil {
+					return model.DayAllocationResponse{}, model.NewNotFoundError("project_not_found", "project %d not found", *a.ProjectID)
+				}
+				projects[*a.ProjectID] = project
+			}
+			if !project.IsActiveOn(record.ClockInTime) {
+				return model.DayAllocationResponse{}, model.NewInvalidError("project_inactive", "project %s is not active on %s", project.Code, record.ClockInTime.Format("2006-01-02"))
+			}
+		}
+		total += a.Minutes
+	}
+	if worked := record.WorkedMinutes(); total > worked {
+		return model.DayAllocationResponse{}, model.NewInvalidError("allocation_exceeds_worked", "allocated %d minutes exceeds worked %d minutes", total, worked)
+	}
+	if err := tau.tar.ReplaceAllocations(userId, recordId, &allocations); err != nil {
+		return model.DayAllocationResponse{}, err
+	}
+	return toDayAllocationResponse(record, allocations), nil
+}
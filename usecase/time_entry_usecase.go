@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ITimeEntryUsecase interface {
+	GetRunningTimer(userId uint) (model.TimeEntryResponse, error)
+	StartTimer(userId uint, taskId uint) (model.TimeEntryResponse, error)
+	StopTimer(userId uint, taskId uint) (model.TimeEntryResponse, error)
+	GetEntries(userId uint, from time.Time, to time.Time) (model.TimeEntryListResponse, error)
+	CreateManualEntry(entry model.TimeEntry) (model.TimeEntryResponse, error)
+	DeleteEntry(userId uint, entryId uint) error
+	SetBillable(userId uint, entryId uint, billable bool) (model.TimeEntryResponse, error)
+}
+
+type timeEntryUsecase struct {
+	ter repository.ITimeEntryRepository
+	tr  repository.ITaskRepository
+	ar  repository.IAttendanceRecordRepository
+	tev validator.ITimeEntryValidator
+	pbu IProjectBudgetUsecase
+	tsr repository.ITimesheetRepository
+	pr  repository.IProjectRepository
+}
+
+func NewTimeEntryUsecase(ter repository.ITimeEntryRepository, tr repository.ITaskRepository, ar repository.IAttendanceRecordRepository, tev validator.ITimeEntryValidator, pbu IProjectBudgetUsecase, tsr repository.ITimesheetRepository, pr repository.IProjectRepository) ITimeEntryUsecase {
+	return &timeEntryUsecase{ter, tr, ar, tev, pbu, tsr, pr}
+}
+
+// checkWeekOpen はその時刻を含む週のタイムシートが提出中・承認済みでないか確認する
+func (teu *timeEntryUsecase) checkWeekOpen(userId uint, t time.Time) error {
+	timesheet := model.Timesheet{}
+	err := teu.tsr.GetTimesheetByWeek(&timesheet, userId, weekStart(t))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if timesheet.IsLocked() {
+		return model.NewConflictError("timesheet_locked", "timesheet for the week of %s is %s", timesheet.WeekStart.Format("2006-01-02"), timesheet.Status)
+	}
+	return nil
+}
+
+// checkBudget は作業時間の記録後にプロジェクト予算のしきい値を確認する。失敗しても記録自体は成功させる
+func (teu *timeEntryUsecase) checkBudget(task model.Task) {
+	if task.ProjectID == nil {
+		return
+	}
+	if _, err := teu.pbu.CheckThresholds(*task.ProjectID); err != nil {
+		log.Printf("failed to check budget of project %d: %v", *task.ProjectID, err)
+	}
+}
+
+// applyRate は記録する時点のメンバーの請求ロールと単価を作業時間に保存する。プロジェクトのないタスクは対象外
+func (teu *timeEntryUsecase) applyRate(entry *model.TimeEntry, task model.Task) error {
+	if task.ProjectID == nil {
+		return nil
+	}
+	project := model.Project{}
+	if err := teu.pr.GetProjectById(&project, *task.ProjectID); err != nil {
+		return err
+	}
+	role := project.BillingRoleOf(entry.UserID)
+	rate := project.RateFor(role)
+	entry.BillingRole = role
+	entry.HourlyRate = &rate
+	return nil
+}
+
+func toTimeEntryResponse(entry model.TimeEntry, now time.Time) model.TimeEntryResponse {
+	return model.TimeEntryResponse{
+		ID:              entry.ID,
+		TaskID:          entry.TaskID,
+		TaskTitle:       entry.Task.Title,
+		StartedAt:       entry.StartedAt,
+		EndedAt:         entry.EndedAt,
+		DurationMinutes: entry.DurationMinutes(now),
+		Source:          entry.Source,
+		Billable:        entry.Billable,
+		Note:            entry.Note,
+	}
+}
+
+func (teu *timeEntryUsecase) GetRunningTimer(userId uint) (model.TimeEntryResponse, error) {
+	entry := model.TimeEntry{}
+	if err := teu.ter.GetRunningEntry(&entry, userId); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	return toTimeEntryResponse(entry, time.Now()), nil
+}
+
+func (teu *timeEntryUsecase) StartTimer(userId uint, taskId uint) (model.TimeEntryResponse, error) {
+	task := model.Task{}
+	if err := teu.tr.GetTaskById(&task, userId, taskId); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	if err := teu.checkWeekOpen(userId, time.Now()); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	// 計測中のタイマーは1ユーザーにつき1つまで（DB の部分ユニークインデックスでも保証）
+	running := model.TimeEntry{}
+	err := teu.ter.GetRunningEntry(&running, userId)
+	if err == nil {
+		return model.TimeEntryResponse{}, model.NewConflictError("timer_running", "timer is already running for task %d", running.TaskID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.TimeEntryResponse{}, err
+	}
+	entry := model.TimeEntry{
+		UserID:    userId,
+		TaskID:    taskId,
+		StartedAt: time.Now(),
+		Source:    model.TimeEntrySourceTimer,
+		// プロジェクトに紐づくタスクの作業は請求対象として記録する
+		Billable: task.ProjectID != nil,
+	}
+	if err := teu.applyRate(&entry, task); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	if err := teu.ter.CreateEntry(&entry); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	entry.Task = task
+	return toTimeEntryResponse(entry, entry.StartedAt), nil
+}
+
+func (teu *timeEntryUsecase) StopTimer(userId uint, taskId uint) (model.TimeEntryResponse, error) {
+	entry := model.TimeEntry{}
+	if err := teu.ter.GetRunningEntry(&entry, userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.TimeEntryResponse{}, model.NewConflictError("timer_not_running", "timer is not running")
+		}
+		return model.TimeEntryResponse{}, err
+	}
+	if entry.TaskID != taskId {
+		return model.TimeEntryResponse{}, model.NewConflictError("timer_running", "timer is running for task %d", entry.TaskID)
+	}
+	now := time.Now()
+	if err := teu.ter.StopEntry(&entry, now); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	entry.EndedAt = &now
+	teu.checkBudget(entry.Task)
+	return toTimeEntryResponse(entry, now), nil
+}
+
+func (teu *timeEntryUsecase) GetEntries(userId uint, from time.Time, to time.Time) (model.TimeEntryListResponse, error) {
+	entries := []model.TimeEntry{}
+	if err := teu.ter.GetEntriesByRange(&entries, userId, from, to); err != nil {
+		return model.TimeEntryListResponse{}, err
+	}
+	now := time.Now()
+	res := model.TimeEntryListResponse{
+		From:    from,
+		To:      to,
+		Entries: make([]model.TimeEntryResponse, len(entries)),
+	}
+	for i, v := range entries {
+		res.Entries[i] = toTimeEntryResponse(v, now)
+		res.TotalMinutes += res.Entries[i].DurationMinutes
+	}
+	return res, nil
+}
+
+func (teu *timeEntryUsecase) CreateManualEntry(entry model.TimeEntry) (model.TimeEntryResponse, error) {
+	if err := teu.tev.ManualEntryValidate(entry); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	task := model.Task{}
+	if err := teu.tr.GetTaskById(&task, entry.UserID, entry.TaskID); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	if err := teu.checkWeekOpen(entry.UserID, entry.StartedAt); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	// 後から追加する作業時間は、その日の出勤〜退勤の範囲内に収まっている必要がある
+	record := model.AttendanceRecord{}
+	if err := teu.ar.GetRecordByDate(&record, entry.UserID, entry.StartedAt); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.TimeEntryResponse{}, model.NewInvalidError("no_attendance_record", "no attendance record on %s", entry.StartedAt.Format("2006-01-02"))
+		}
+		return model.TimeEntryResponse{}, err
+	}
+	clockOut := record.ClockOutTime
+	if clockOut.IsZero() {
+		clockOut = time.Now()
+	}
+	if entry.StartedAt.Before(record.ClockInTime) || entry.EndedAt.After(clockOut) {
+		return model.TimeEntryResponse{}, model.NewInvalidError("outside_attendance", "time entry must be between clock-in and clock-out")
+	}
+	count, err := teu.ter.CountOverlapping(entry.UserID, entry.StartedAt, *entry.EndedAt)
+	if err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	if count > 0 {
+		return model.TimeEntryResponse{}, model.NewConflictError("time_entry_overlap", "time entry overlaps an existing entry")
+	}
+	newEntry := model.TimeEntry{
+		UserID:    entry.UserID,
+		TaskID:    entry.TaskID,
+		StartedAt: entry.StartedAt,
+		EndedAt:   entry.EndedAt,
+		Source:    model.TimeEntrySourceManual,
+		Billable:  task.ProjectID != nil,
+		Note:      entry.Note,
+	}
+	if err := teu.applyRate(&newEntry, task); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	if err := teu.ter.CreateEntry(&newEntry); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	newEntry.Task = task
+	teu.checkBudget(task)
+	return toTimeEntryResponse(newEntry, time.Now()), nil
+}
+
+func (teu *timeEntryUsecase) DeleteEntry(userId uint, entryId uint) error {
+	entry := model.TimeEntry{}
+	if err := teu.ter.GetEntryById(&entry, userId, entryId); err != nil {
+		return err
+	}
+	if err := teu.checkWeekOpen(userId, entry.StartedAt); err != nil {
+		return err
+	}
+	return teu.ter.DeleteEntry(userId, entryId)
+}
+
+func (teu *timeEntryUsecase) SetBillable(userId uint, entryId uint, billable bool) (model.TimeEntryResponse, error) {
+	entry := model.TimeEntry{}
+	if err := teu.ter.GetEntryById(&entry, userId, entryId); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	if err := teu.checkWeekOpen(userId, entry.StartedAt); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	if err := teu.ter.SetBillable(&entry, userId, entryId, billable); err != nil {
+		return model.TimeEntryResponse{}, err
+	}
+	return toTimeEntryResponse(entry, time.Now()), nil
+}
@@ -1,32 +1,42 @@
 package usecase
 
 import (
+	"errors"
+	"fmt"
 	"go-rest-api/model"
 	"go-rest-api/repository"
 	"go-rest-api/validator"
+	"math"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type IAttendanceRecordUsecase interface {
 	GetRecordByDate(uint, time.Time) (model.AttendanceRecordResponse, error)
-	GetRecordsByDate(date time.Time) ([]model.AttendanceRecordResponse, error)
-	GetRecordsByDepartment(department string) ([]model.AttendanceRecordResponse, error)
-	GetRecordsByDateDepartment(date time.Time, department string) ([]model.AttendanceRecordResponse, error)
-	GetAllRecords(userId uint) ([]model.AttendanceRecordResponse, error)
+	GetRecordsByDate(date time.Time, employmentType string) ([]model.AttendanceRecordResponse, error)
+	GetRecordsByDepartment(filter model.AttendanceRecordFilter, page model.PageQuery) ([]model.AttendanceRecordResponse, model.PageInfo, error)
+	GetRecordsByDateDepartment(date time.Time, department string, employmentType string) ([]model.AttendanceRecordResponse, error)
+	GetAllRecords(userId uint, filter model.AttendanceRecordFilter, page model.PageQuery) ([]model.AttendanceRecordResponse, model.PageInfo, error)
 	GetRecordById(userId uint, recordId uint) (model.AttendanceRecordResponse, error)
-	GetAllUsers() ([]model.UserResponse, error)
+	GetAllUsers(filter model.UserFilter, page model.PageQuery) ([]model.UserResponse, model.PageInfo, error)
 	CreateRecord(record model.AttendanceRecord) (model.AttendanceRecordResponse, error)
 	UpdateRecord(record model.AttendanceRecord, userId uint, recordId uint) (model.AttendanceRecordResponse, error)
 	DeleteRecord(userId uint, recordId uint) error
+	StartBreak(userId uint, at time.Time) (model.AttendanceRecordResponse, error)
+	EndBreak(userId uint, at time.Time) (model.AttendanceRecordResponse, error)
+	GetDepartmentDashboard(userId uint, department string, from time.Time, to time.Time) (model.DepartmentDashboardResponse, error)
 }
 
 type attendanceRecordUsecase struct {
 	ar repository.IAttendanceRecordRepository
 	av validator.IAttendanceRecordValidator
+	pu IPresenceUsecase
+	ur repository.IUserRepository
 }
 
-func NewAttendanceRecordUsecase(ar repository.IAttendanceRecordRepository, av validator.IAttendanceRecordValidator) IAttendanceRecordUsecase {
-	return &attendanceRecordUsecase{ar, av}
+func NewAttendanceRecordUsecase(ar repository.IAttendanceRecordRepository, av validator.IAttendanceRecordValidator, pu IPresenceUsecase, ur repository.IUserRepository) IAttendanceRecordUsecase {
+	return &attendanceRecordUsecase{ar, av, pu, ur}
 }
 
 func (aru *attendanceRecordUsecase) GetRecordByDate(userId uint, date time.Time) (model.AttendanceRecordResponse, error) {
@@ -41,113 +51,140 @@ func (aru *attendanceRecordUsecase) GetRecordByDate(userId uint, date time.Time)
 		ClockOutTime: record.ClockOutTime,
 		CreatedAt:    record.CreatedAt,
 		UpdatedAt:    record.UpdatedAt,
+		Breaks:       record.BreakResponses(),
 	}, nil
 }
-func (aru *attendanceRecordUsecase) GetRecordsByDate(date time.Time) ([]model.AttendanceRecordResponse, error) {
+func (aru *attendanceRecordUsecase) GetRecordsByDate(date time.Time, employmentType string) ([]model.AttendanceRecordResponse, error) {
 	var records []model.AttendanceRecord
 
-	if err := aru.ar.GetRecordsByDate(&records, date); err != nil {
+	if err := aru.ar.GetRecordsByDate(&records, date, employmentType); err != nil {
 		return nil, err
 	}
 
 	var responses []model.AttendanceRecordResponse
 	for _, record := range records {
+		worked := record.WorkedMinutes()
 		responses = append(responses, model.AttendanceRecordResponse{
-			ID:           record.ID,
-			UserID:       record.UserID,
-			ClockInTime:  record.ClockInTime,
-			ClockOutTime: record.ClockOutTime,
-			CreatedAt:    record.CreatedAt,
-			UpdatedAt:    record.UpdatedAt,
+			ID:              record.ID,
+			UserID:          record.UserID,
+			ClockInTime:     record.ClockInTime,
+			ClockOutTime:    record.ClockOutTime,
+			WorkedMinutes:   worked,
+			OvertimeMinutes: record.User.EmploymentType.OvertimeMinutes(worked),
+			CreatedAt:       record.CreatedAt,
+			UpdatedAt:       record.UpdatedAt,
+			Breaks:          record.BreakResponses(),
 		})
 	}
 	println("usecase GetRecordsByDate")
 	return responses, nil
 }
 
-func (aru *attendanceRecordUsecase) GetRecordsByDepartment(department string) ([]model.AttendanceRecordResponse, error) {
+func (aru *attendanceRecordUsecase) GetRecordsByDepartment(filter model.AttendanceRecordFilter, page model.PageQuery) ([]model.AttendanceRecordResponse, model.PageInfo, error) {
 	var records []model.AttendanceRecord
 
-	if err := aru.ar.GetRecordsByDepartment(&records, department); err != nil {
-		return nil, err
+	pageInfo, err := aru.ar.GetRecordsByDepartment(&records, filter, page)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
 
-	var responses []model.AttendanceRecordResponse
+	responses := []model.AttendanceRecordResponse{}
 	for _, record := range records {
+		worked := record.WorkedMinutes()
 		responses = append(responses, model.AttendanceRecordResponse{
-			ID:           record.ID,
-			UserID:       record.UserID,
-			ClockInTime:  record.ClockInTime,
-			ClockOutTime: record.ClockOutTime,
-			CreatedAt:    record.CreatedAt,
-			UpdatedAt:    record.UpdatedAt,
+			ID:              record.ID,
+			UserID:          record.UserID,
+			ClockInTime:     record.ClockInTime,
+			ClockOutTime:    record.ClockOutTime,
+			WorkedMinutes:   worked,
+			OvertimeMinutes: record.User.EmploymentType.OvertimeMinutes(worked),
+			CreatedAt:       record.CreatedAt,
+			UpdatedAt:       record.UpdatedAt,
+			Breaks:          record.BreakResponses(),
 		})
 	}
-	return responses, nil
+	return responses, pageInfo, nil
 }
 
-func (aru *attendanceRecordUsecase) GetRecordsByDateDepartment(date time.Time, department string) ([]model.AttendanceRecordResponse, error) {
+func (aru *attendanceRecordUsecase) GetRecordsByDateDepartment(date time.Time, department string, employmentType string) ([]model.AttendanceRecordResponse, error) {
 	var records []model.AttendanceRecord
 
-	if err := aru.ar.GetRecordsByDateDepartment(&records, date, department); err != nil {
+	if err := aru.ar.GetRecordsByDateDepartment(&records, date, department, employmentType); err != nil {
 		return nil, err
 	}
 
 	var responses []model.AttendanceRecordResponse
 	for _, record := range records {
 		userResponse := model.UserResponse{
-			ID:         record.User.ID,
-			Email:      record.User.Email,
-			Department: record.User.Department,
-			Name:       record.User.Name,
+			ID:             record.User.ID,
+			Email:          record.User.Email,
+			Department:     record.User.Department,
+			Name:           record.User.Name,
+			EmploymentType: record.User.EmploymentTypeCode(),
+			IsActive:       record.User.IsActive(time.Now()),
 		}
 
+		worked := record.WorkedMinutes()
 		responses = append(responses, model.AttendanceRecordResponse{
-			ID:           record.ID,
-			UserID:       record.UserID,
-			ClockInTime:  record.ClockInTime,
-			ClockOutTime: record.ClockOutTime,
-			CreatedAt:    record.CreatedAt,
-			UpdatedAt:    record.UpdatedAt,
-			User:         userResponse,
+			ID:              record.ID,
+			UserID:          record.UserID,
+			ClockInTime:     record.ClockInTime,
+			ClockOutTime:    record.ClockOutTime,
+			WorkedMinutes:   worked,
+			OvertimeMinutes: record.User.EmploymentType.OvertimeMinutes(worked),
+			CreatedAt:       record.CreatedAt,
+			UpdatedAt:       record.UpdatedAt,
+			Breaks:          record.BreakResponses(),
+			User:            userResponse,
 		})
 	}
 	return responses, nil
 }
-func (aru *attendanceRecordUsecase) GetAllRecords(userId uint) ([]model.AttendanceRecordResponse, error) {
+func (aru *attendanceRecordUsecase) GetAllRecords(userId uint, filter model.AttendanceRecordFilter, page model.PageQuery) ([]model.AttendanceRecordResponse, model.PageInfo, error) {
 	records := []model.AttendanceRecord{}
-	if err := aru.ar.GetAllRecords(&records, userId); err != nil {
-		return nil, err
+	pageInfo, err := aru.ar.GetAllRecords(&records, userId, filter, page)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
 	resRecords := make([]model.AttendanceRecordResponse, len(records))
 	for i, v := range records {
+		worked := v.WorkedMinutes()
 		resRecords[i] = model.AttendanceRecordResponse{
-			ID:           v.ID,
-			UserID:       v.UserID,
-			ClockInTime:  v.ClockInTime,
-			ClockOutTime: v.ClockOutTime,
-			CreatedAt:    v.CreatedAt,
-			UpdatedAt:    v.UpdatedAt,
+			ID:              v.ID,
+			UserID:          v.UserID,
+			ClockInTime:     v.ClockInTime,
+			ClockOutTime:    v.ClockOutTime,
+			WorkedMinutes:   worked,
+			OvertimeMinutes: v.User.EmploymentType.OvertimeMinutes(worked),
+			CreatedAt:       v.CreatedAt,
+			UpdatedAt:       v.UpdatedAt,
+			Breaks:          v.BreakResponses(),
 		}
 	}
-	return resRecords, nil
+	return resRecords, pageInfo, nil
 }
 
-func (aru *attendanceRecordUsecase) GetAllUsers() ([]model.UserResponse, error) {
+func (aru *attendanceRecordUsecase) GetAllUsers(filter model.UserFilter, page model.PageQuery) ([]model.UserResponse, model.PageInfo, error) {
 	users := []model.User{}
-	if err := aru.ar.GetAllUsers(&users); err != nil {
-		return nil, err
+	pageInfo, err := aru.ar.GetAllUsers(&users, filter, page)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
 	resUsers := make([]model.UserResponse, len(users))
 	for i, v := range users {
 		resUsers[i] = model.UserResponse{
-			ID:         v.ID,
-			Email:      v.Email,
-			Department: v.Department,
-			Name:       v.Name,
+			ID:              v.ID,
+			Email:           v.Email,
+			Department:      v.Department,
+			Name:            v.Name,
+			Role:            v.Role,
+			HireDate:        v.HireDate,
+			TerminationDate: v.TerminationDate,
+			EmploymentType:  v.EmploymentTypeCode(),
+			IsActive:        v.IsActive(time.Now()),
 		}
 	}
-	return resUsers, nil
+	return resUsers, pageInfo, nil
 }
 func (aru *attendanceRecordUsecase) GetRecordById(userId uint, recordId uint) (model.AttendanceRecordResponse, error) {
 	record := model.AttendanceRecord{}
@@ -161,6 +198,7 @@ func (aru *attendanceRecordUsecase) GetRecordById(userId uint, recordId uint) (m
 		ClockOutTime: record.ClockOutTime,
 		CreatedAt:    record.CreatedAt,
 		UpdatedAt:    record.UpdatedAt,
+		Breaks:       record.BreakResponses(),
 	}, nil
 }
 
@@ -172,6 +210,7 @@ func (aru *attendanceRecordUsecase) CreateRecord(record model.AttendanceRecord)
 	if err := aru.ar.CreateRecord(&record); err != nil {
 		return model.AttendanceRecordResponse{}, err
 	}
+	aru.pu.NotifyRecord(record)
 	return model.AttendanceRecordResponse{
 		ID:           record.ID,
 		UserID:       record.UserID,
@@ -179,6 +218,7 @@ func (aru *attendanceRecordUsecase) CreateRecord(record model.AttendanceRecord)
 		ClockOutTime: record.ClockOutTime,
 		CreatedAt:    record.CreatedAt,
 		UpdatedAt:    record.UpdatedAt,
+		Breaks:       record.BreakResponses(),
 	}, nil
 }
 
@@ -190,6 +230,8 @@ func (aru *attendanceRecordUsecase) UpdateRecord(record model.AttendanceRecord,
 	if err := aru.ar.UpdateRecord(&record, userId, recordId); err != nil {
 		return model.AttendanceRecordResponse{}, err
 	}
+	record.UserID = userId
+	aru.pu.NotifyRecord(record)
 	return model.AttendanceRecordResponse{
 		ID:           record.ID,
 		UserID:       record.UserID,
@@ -197,9 +239,135 @@ func (aru *attendanceRecordUsecase) UpdateRecord(record model.AttendanceRecord,
 		ClockOutTime: record.ClockOutTime,
 		CreatedAt:    record.CreatedAt,
 		UpdatedAt:    record.UpdatedAt,
+		Breaks:       record.BreakResponses(),
 	}, nil
 }
 
 func (aru *attendanceRecordUsecase) DeleteRecord(userId uint, recordId uint) error {
 	return aru.ar.DeleteRecord(userId, recordId)
 }
+
+// openRecordOn は休憩の打刻の対象になるその日の勤怠記録を返す
+func (aru *attendanceRecordUsecase) openRecordOn(userId uint, at time.Time) (model.AttendanceRecord, error) {
+	if err := aru.av.ValidateBreakTime(at); err != nil {
+		return model.AttendanceRecord{}, err
+	}
+	record := model.AttendanceRecord{}
+	if err := aru.ar.GetRecordByDate(&record, userId, at); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.AttendanceRecord{}, model.NewConflictError("not_clocked_in", "not clocked in on this day")
+		}
+		return model.AttendanceRecord{}, err
+	}
+	return record, nil
+}
+
+// breakResponse は休憩の打刻後の記録を読み直し、在席状況を配信してから返す
+func (aru *attendanceRecordUsecase) breakResponse(userId uint, at time.Time) (model.AttendanceRecordResponse, error) {
+	record := model.AttendanceRecord{}
+	if err := aru.ar.GetRecordByDate(&record, userId, at); err != nil {
+		return model.AttendanceRecordResponse{}, err
+	}
+	aru.pu.NotifyRecord(record)
+	return model.AttendanceRecordResponse{
+		ID:           record.ID,
+		UserID:       record.UserID,
+		ClockInTime:  record.ClockInTime,
+		ClockOutTime: record.ClockOutTime,
+		CreatedAt:    record.CreatedAt,
+		UpdatedAt:    record.UpdatedAt,
+		Breaks:       record.BreakResponses(),
+	}, nil
+}
+
+func (aru *attendanceRecordUsecase) StartBreak(userId uint, at time.Time) (model.AttendanceRecordResponse, error) {
+	record, err := aru.openRecordOn(userId, at)
+	if err != nil {
+		return model.AttendanceRecordResponse{}, err
+	}
+	if at.Before(record.ClockInTime) {
+		return model.AttendanceRecordResponse{}, model.NewInvalidError("invalid_break_start", "break start cannot be before clock-in time")
+	}
+	brk := model.AttendanceBreak{AttendanceRecordID: record.ID, StartedAt: at}
+	if err := aru.ar.StartBreak(&brk, userId); err != nil {
+		return model.AttendanceRecordResponse{}, err
+	}
+	return aru.breakResponse(userId, at)
+}
+
+func (aru *attendanceRecordUsecase) EndBreak(userId uint, at time.Time) (model.AttendanceRecordResponse, error) {
+	record, err := aru.openRecordOn(userId, at)
+	if err != nil {
+		return model.AttendanceRecordResponse{}, err
+	}
+	brk := model.AttendanceBreak{}
+	if err := aru.ar.EndBreak(&brk, userId, record.ID, at); err != nil {
+		return model.AttendanceRecordResponse{}, err
+	}
+	return aru.breakResponse(userId, at)
+}
+
+// formatSecondsOfDay は0時からの秒数を HH:MM 形式にする
+func formatSecondsOfDay(seconds *float64) string {
+	if seconds == nil {
+		return ""
+	}
+	minutes := int(math.Round(*seconds / 60))
+	return fmt.Sprintf("%02d:%02d", minutes/60%24, minutes%60)
+}
+
+// GetDepartmentDashboard は管理者と、その部署を管理するマネージャーだけが参照できる
+func (aru *attendanceRecordUsecase) GetDepartmentDashboard(userId uint, department string, from time.Time, to time.Time) (model.DepartmentDashboardResponse, error) {
+	user := model.User{}
+	if err := aru.ur.GetUserById(&user, userId); err != nil {
+		return model.DepartmentDashboardResponse{}, err
+	}
+	if user.Role != model.RoleAdmin && !user.CanManageDepartment(department) {
+		return model.DepartmentDashboardResponse{}, model.NewForbiddenError("not_department_manager", "only managers of the department can view the dashboard")
+	}
+	employees := []model.EmployeeAttendanceStats{}
+	if err := aru.ar.GetEmployeeStats(&employees, department, from, to); err != nil {
+		return model.DepartmentDashboardResponse{}, err
+	}
+	summary := model.DepartmentAttendanceStats{}
+	if err := aru.ar.GetDepartmentStats(&summary, department, from, to); err != nil {
+		return model.DepartmentDashboardResponse{}, err
+	}
+
+	res := model.DepartmentDashboardResponse{
+		Department: department,
+		From:       from,
+		To:         to,
+		Summary: model.DepartmentSummaryResponse{
+			Headcount:       summary.Headcount,
+			DaysWorked:      summary.DaysWorked,
+			WorkedMinutes:   summary.WorkedMinutes,
+			OvertimeMinutes: summary.OvertimeMinutes,
+			LateCount:       summary.LateCount,
+			AbsenceCount:    summary.AbsenceCount,
+			LeaveUsedDays:   summary.LeaveUsedDays,
+			AvgClockIn:      formatSecondsOfDay(summary.AvgClockInSeconds),
+			AvgClockOut:     formatSecondsOfDay(summary.AvgClockOutSeconds),
+		},
+		Employees: make([]model.EmployeeDashboardResponse, len(employees)),
+	}
+	if summary.AvgWorkedMinutes != nil {
+		res.Summary.AvgWorkedMinutesDay = int(math.Round(*summary.AvgWorkedMinutes))
+	}
+	for i, v := range employees {
+		res.Employees[i] = model.EmployeeDashboardResponse{
+			UserID:          v.UserID,
+			Name:            v.Name,
+			EmploymentType:  v.EmploymentType,
+			DaysWorked:      v.DaysWorked,
+			WorkedMinutes:   v.WorkedMinutes,
+			OvertimeMinutes: v.OvertimeMinutes,
+			LateCount:       v.LateCount,
+			AbsenceCount:    v.AbsenceCount,
+			LeaveUsedDays:   v.LeaveUsedDays,
+			AvgClockIn:      formatSecondsOfDay(v.AvgClockInSeconds),
+			AvgClockOut:     formatSecondsOfDay(v.AvgClockOutSeconds),
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ITimesheetUsecase interface {
+	GetMyTimesheets(userId uint) ([]model.TimesheetResponse, error)
+	GetTimesheetById(userId uint, timesheetId uint) (model.TimesheetResponse, error)
+	GetTimesheetsForReview(userId uint) ([]model.TimesheetResponse, error)
+	SubmitTimesheet(userId uint, week time.Time) (model.TimesheetResponse, error)
+	ApproveTimesheet(reviewerId uint, timesheetId uint, comment string) (model.TimesheetResponse, error)
+	RejectTimesheet(reviewerId uint, timesheetId uint, comment string) (model.TimesheetResponse, error)
+}
+
+type timesheetUsecase struct {
+	tsr repository.ITimesheetRepository
+	ter repository.ITimeEntryRepository
+	ur  repository.IUserRepository
+	nu  INotificationUsecase
+}
+
+func NewTimesheetUsecase(tsr repository.ITimesheetRepository, ter repository.ITimeEntryRepository, ur repository.IUserRepository, nu INotificationUsecase) ITimesheetUsecase {
+	return &timesheetUsecase{tsr, ter, ur, nu}
+}
+
+func toTimesheetResponse(timesheet model.Timesheet) model.TimesheetResponse {
+	res := model.TimesheetResponse{
+		ID:           timesheet.ID,
+		UserID:       timesheet.UserID,
+		UserName:     timesheet.User.Name,
+		WeekStart:    timesheet.WeekStart,
+		Status:       timesheet.Status,
+		TotalMinutes: timesheet.TotalMinutes,
+		SubmittedAt:  timesheet.SubmittedAt,
+		ReviewedBy:   timesheet.ReviewedBy,
+		ReviewedAt:   timesheet.ReviewedAt,
+		Comment:      timesheet.Comment,
+	}
+	for _, v := range timesheet.Approvals {
+		res.ApprovedBy = append(res.ApprovedBy, v.LeadID)
+	}
+	return res
+}
+
+// canReview は対象週に作業時間のあるプロジェクトのリーダー、または管理者であれば true を返す
+func (tsu *timesheetUsecase) canReview(userId uint, timesheet model.Timesheet) (bool, error) {
+	user := model.User{}
+	if err := tsu.ur.GetUserById(&user, userId); err != nil {
+		return false, err
+	}
+	if user.Role == model.RoleAdmin {
+		return true, nil
+	}
+	return tsu.isLead(userId, timesheet)
+}
+
+func (tsu *timesheetUsecase) isLead(userId uint, timesheet model.Timesheet) (bool, error) {
+	count, err := tsu.tsr.CountLeadProjects(timesheet, userId)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (tsu *timesheetUsecase) GetMyTimesheets(userId uint) ([]model.TimesheetResponse, error) {
+	timesheets := []model.Timesheet{}
+	if err := tsu.tsr.GetTimesheetsByUser(&timesheets, userId); err != nil {
+		return nil, err
+	}
+	resTimesheets := make([]model.TimesheetResponse, len(timesheets))
+	for i, v := range timesheets {
+		resTimesheets[i] = toTimesheetResponse(v)
+	}
+	return resTimesheets, nil
+}
+
+func (tsu *timesheetUsecase) GetTimesheetById(userId uint, timesheetId uint) (model.TimesheetResponse, error) {
+	timesheet := model.Timesheet{}
+	if err := tsu.tsr.GetTimesheetById(&timesheet, timesheetId); err != nil {
+		return model.TimesheetResponse{}, err
+	}
+	if timesheet.UserID != userId {
+		ok, err := tsu.canReview(userId, timesheet)
+		if err != nil {
+			return model.TimesheetResponse{}, err
+		}
+		if !ok {
+			return model.TimesheetResponse{}, model.ErrNotFound
+		}
+	}
+	entries := []model.TimeEntry{}
+	if err := tsu.ter.GetEntriesByRange(&entries, timesheet.UserID, timesheet.WeekStart, timesheet.WeekStart.AddDate(0, 0, 7)); err != nil {
+		return model.TimesheetResponse{}, err
+	}
+	res := toTimesheetResponse(timesheet)
+	now := time.Now()
+	res.Entries = make([]model.TimeEntryResponse, len(entries))
+	for i, v := range entries {
+		res.Entries[i] = toTimeEntryResponse(v, now)
+	}
+	return res, nil
+}
+
+func (tsu *timesheetUsecase) GetTimesheetsForReview(userId uint) ([]model.TimesheetResponse, error) {
+	timesheets := []model.Timesheet{}
+	if err := tsu.tsr.GetSubmittedForLead(&timesheets, userId); err != nil {
+		return nil, err
+	}
+	resTimesheets := make([]model.TimesheetResponse, len(timesheets))
+	for i, v := range timesheets {
+		resTimesheets[i] = toTimesheetResponse(v)
+	}
+	return resTimesheets, nil
+}
+
+func (tsu *timesheetUsecase) SubmitTimesheet(userId uint, week time.Time) (model.TimesheetResponse, error) {
+	from := weekStart(week)
+	to := from.AddDate(0, 0, 7)
+	now := time.Now()
+	if from.After(now) {
+		return model.TimesheetResponse{}, model.NewInvalidError("future_week", "cannot submit a future week")
+	}
+	// 計測中のタイマーが対象週にあると提出後に時間が変わるため、停止してから提出させる
+	running := model.TimeEntry{}
+	err := tsu.ter.GetRunningEntry(&running, userId)
+	if err == nil && !running.StartedAt.Before(from) && running.StartedAt.Before(to) {
+		return model.TimesheetResponse{}, model.NewConflictError("timer_running", "stop the running timer before submitting")
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.TimesheetResponse{}, err
+	}
+
+	entries := []model.TimeEntry{}
+	if err := tsu.ter.GetEntriesByRange(&entries, userId, from, to); err != nil {
+		return model.TimesheetResponse{}, err
+	}
+	if len(entries) == 0 {
+		return model.TimesheetResponse{}, model.NewInvalidError("no_time_entries", "no time entries in the week of %s", from.Format("2006-01-02"))
+	}
+	timesheet := model.Timesheet{
+		UserID:      userId,
+		WeekStart:   from,
+		Status:      model.TimesheetStatusSubmitted,
+		SubmittedAt: now,
+	}
+	for _, v := range entries {
+		timesheet.TotalMinutes += v.DurationMinutes(now)
+	}
+	if err := tsu.tsr.SubmitTimesheet(&timesheet); err != nil {
+		return model.TimesheetResponse{}, err
+	}
+	tsu.notifyLeads(timesheet)
+	return toTimesheetResponse(timesheet), nil
+}
+
+// notifyLeads は承認者となるプロジェクトリーダーに承認依頼を通知する。通知の失敗は提出を取り消さずログに残す
+func (tsu *timesheetUsecase) notifyLeads(timesheet model.Timesheet) {
+	user := model.User{}
+	if err := tsu.ur.GetUserById(&user, timesheet.UserID); err != nil {
+		log.Printf("failed to notify leads of timesheet %d: %v", timesheet.ID, err)
+		return
+	}
+	leadIds := []uint{}
+	if err := tsu.tsr.GetLeadIds(&leadIds, timesheet); err != nil {
+		log.Printf("failed to notify leads of timesheet %d: %v", timesheet.ID, err)
+		return
+	}
+	data := map[string]string{"Name": user.Name, "Week": timesheet.WeekStart.Format("2006-01-02")}
+	for _, v := range leadIds {
+		if err := tsu.nu.Notify(v, model.NotificationApprovalRequested, data); err != nil {
+			log.Printf("failed to notify lead %d of timesheet %d: %v", v, timesheet.ID, err)
+		}
+	}
+}
+
+// review は承認・差し戻しする。管理者の承認はそのまま確定し、リーダーの承認は週に作業時間のある
+// 全プロジェクトのリーダーがそろったときに確定する（他のリーダーのプロジェクト分まで締めないため）
+func (tsu *timesheetUsecase) review(reviewerId uint, timesheetId uint, status string, comment string) (model.TimesheetResponse, error) {
+	timesheet := model.Timesheet{}
+	if err := tsu.tsr.GetTimesheetById(&timesheet, timesheetId); err != nil {
+		return model.TimesheetResponse{}, err
+	}
+	if timesheet.UserID == reviewerId {
+		return model.TimesheetResponse{}, model.NewForbiddenError("own_timesheet", "cannot review your own timesheet")
+	}
+	reviewer := model.User{}
+	if err := tsu.ur.GetUserById(&reviewer, reviewerId); err != nil {
+		return model.TimesheetResponse{}, err
+	}
+	isAdmin := reviewer.Role == model.RoleAdmin
+	if !isAdmin {
+		ok, err := tsu.isLead(reviewerId, timesheet)
+		if err != nil {
+			return model.TimesheetResponse{}, err
+		}
+		if !ok {
+			return model.TimesheetResponse{}, model.NewForbiddenError("not_project_lead", "you are not a lead of projects in this timesheet")
+		}
+	}
+	now := time.Now()
+	timesheet.Status = status
+	timesheet.ReviewedBy = &reviewerId
+	timesheet.ReviewedAt = &now
+	timesheet.Comment = comment
+	if status == model.TimesheetStatusApproved && !isAdmin {
+		leadIds := []uint{}
+		if err := tsu.tsr.GetLeadIds(&leadIds, timesheet); err != nil {
+			return model.TimesheetResponse{}, err
+		}
+		approval := model.TimesheetApproval{LeadID: reviewerId, Comment: comment}
+		if err := tsu.tsr.ApproveAsLead(&timesheet, timesheetId, approval, leadIds); err != nil {
+			return model.TimesheetResponse{}, err
+		}
+		if timesheet.Status == model.TimesheetStatusSubmitted {
+			// 他のリーダーの承認待ち
+			return toTimesheetResponse(timesheet), nil
+		}
+	} else if err := tsu.tsr.ReviewTimesheet(&timesheet, timesheetId); err != nil {
+		return model.TimesheetResponse{}, err
+	}
+	data := map[string]string{"Week": timesheet.WeekStart.Format("2006-01-02"), "Status": status, "Comment": comment}
+	if err := tsu.nu.Notify(timesheet.UserID, model.NotificationTimesheetReviewed, data); err != nil {
+		log.Printf("failed to notify review of timesheet %d: %v", timesheetId, err)
+	}
+	return toTimesheetResponse(timesheet), nil
+}
+
+func (tsu *timesheetUsecase) ApproveTimesheet(reviewerId uint, timesheetId uint, comment string) (model.TimesheetResponse, error) {
+	return tsu.review(reviewerId, timesheetId, model.TimesheetStatusApproved, comment)
+}
+
+// RejectTimesheet は差し戻す。差し戻された週は再び編集でき、修正後に再提出できる
+func (tsu *timesheetUsecase) RejectTimesheet(reviewerId uint, timesheetId uint, comment string) (model.TimesheetResponse, error) {
+	if comment == "" {
+		return model.TimesheetResponse{}, model.NewInvalidError("comment_required", "comment is required to reject a timesheet")
+	}
+	return tsu.review(reviewerId, timesheetId, model.TimesheetStatusRejected, comment)
+}
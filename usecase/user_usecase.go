@@ -1,10 +1,14 @@
 package usecase
 
 import (
+	"errors"
+	"fmt"
 	"go-rest-api/model"
 	"go-rest-api/repository"
 	"go-rest-api/validator"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -16,8 +20,19 @@ type IUserUsecase interface {
 	Login(user model.User) (string, error)
 	UpdateUser(user model.User) (model.UserResponse, error)
 	DeleteUser(user model.User) error
+	UpdateEmployment(userId uint, hireDate *time.Time, terminationDate *time.Time) (model.UserResponse, error)
+	SetEmploymentType(userId uint, employmentTypeId *uint) (model.UserResponse, error)
+	SetRole(userId uint, role string) (model.UserResponse, error)
+	DeactivateUser(userId uint) error
+	ReactivateUser(userId uint) error
+	PurgeExpiredUsers(now time.Time) (int64, error)
+	CheckActive(userId uint) error
+	Authorize(userId uint, roles ...string) error
 }
 
+// 労働基準法上の記録保存期間（年）。USER_RETENTION_YEARS で上書きできる
+const defaultRetentionYears = 5
+
 type userUsecase struct {
 	ur repository.IUserRepository
 	uv validator.IUserValidator
@@ -32,6 +47,12 @@ func (uu *userUsecase) SignUp(user model.User) (model.UserResponse, error) {
 	if err := uu.uv.UserValidate(user); err != nil {
 		return model.UserResponse{}, err
 	}
+	existing := model.User{}
+	if err := uu.ur.GetUserByEmail(&existing, user.Email); err == nil {
+		return model.UserResponse{}, model.ErrEmailTaken
+	} else if !errors.Is(err, model.ErrNotFound) {
+		return model.UserResponse{}, err
+	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), 10)
 	if err != nil {
 		return model.UserResponse{}, err
@@ -45,6 +66,7 @@ func (uu *userUsecase) SignUp(user model.User) (model.UserResponse, error) {
 		Email:      newUser.Email,
 		Name:       newUser.Name,
 		Department: newUser.Department,
+		IsActive:   newUser.IsActive(time.Now()),
 	}
 	return resUser, nil
 }
@@ -54,12 +76,19 @@ func (uu *userUsecase) Login(user model.User) (string, error) {
 		return "", err
 	}
 	storedUser := model.User{}
+	// 登録の有無が分からないよう、メールアドレスとパスワードのどちらが誤りでも同じエラーを返す
 	if err := uu.ur.GetUserByEmail(&storedUser, user.Email); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return "", model.ErrInvalidCredentials
+		}
 		return "", err
 	}
 	err := bcrypt.CompareHashAndPassword([]byte(storedUser.Password), []byte(user.Password))
 	if err != nil {
-		return "", err
+		return "", model.ErrInvalidCredentials
+	}
+	if !storedUser.IsActive(time.Now()) {
+		return "", model.NewForbiddenError("account_deactivated", "account is deactivated")
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": storedUser.ID,
@@ -93,12 +122,21 @@ func (uu *userUsecase) UpdateUser(user model.User) (model.UserResponse, error) {
 	if err := uu.ur.UpdateUser(&newUser); err != nil {
 		return model.UserResponse{}, err
 	}
+	storedUser := model.User{}
+	if err := uu.ur.GetUserById(&storedUser, newUser.ID); err != nil {
+		return model.UserResponse{}, err
+	}
 
 	resUser := model.UserResponse{
-		ID:         newUser.ID,
-		Email:      newUser.Email,
-		Name:       newUser.Name,
-		Department: newUser.Department,
+		ID:              storedUser.ID,
+		Email:           storedUser.Email,
+		Name:            storedUser.Name,
+		Department:      storedUser.Department,
+		Role:            storedUser.Role,
+		HireDate:        storedUser.HireDate,
+		TerminationDate: storedUser.TerminationDate,
+		EmploymentType:  storedUser.EmploymentTypeCode(),
+		IsActive:        storedUser.IsActive(time.Now()),
 	}
 
 	return resUser, nil
@@ -121,3 +159,127 @@ func (uu *userUsecase) DeleteUser(user model.User) error {
 	}
 	return nil
 }
+
+func (uu *userUsecase) UpdateEmployment(userId uint, hireDate *time.Time, terminationDate *time.Time) (model.UserResponse, error) {
+	if hireDate != nil && terminationDate != nil && terminationDate.Before(*hireDate) {
+		return model.UserResponse{}, model.NewInvalidError("termination_before_hire", "termination_date cannot be before hire_date")
+	}
+	if err := uu.ur.UpdateEmployment(userId, hireDate, terminationDate); err != nil {
+		return model.UserResponse{}, err
+	}
+	storedUser := model.User{}
+	if err := uu.ur.GetUserById(&storedUser, userId); err != nil {
+		return model.UserResponse{}, err
+	}
+	return model.UserResponse{
+		ID:              storedUser.ID,
+		Email:           storedUser.Email,
+		Name:            storedUser.Name,
+		Department:      storedUser.Department,
+		Role:            storedUser.Role,
+		HireDate:        storedUser.HireDate,
+		TerminationDate: storedUser.TerminationDate,
+		EmploymentType:  storedUser.EmploymentTypeCode(),
+		IsActive:        storedUser.IsActive(time.Now()),
+	}, nil
+}
+
+func (uu *userUsecase) SetEmploymentType(userId uint, employmentTypeId *uint) (model.UserResponse, error) {
+	if err := uu.ur.SetEmploymentType(userId, employmentTypeId); err != nil {
+		return model.UserResponse{}, err
+	}
+	storedUser := model.User{}
+	if err := uu.ur.GetUserById(&storedUser, userId); err != nil {
+		return model.UserResponse{}, err
+	}
+	return model.UserResponse{
+		ID:              storedUser.ID,
+		Email:           storedUser.Email,
+		Name:            storedUser.Name,
+		Department:      storedUser.Department,
+		Role:            storedUser.Role,
+		HireDate:        storedUser.HireDate,
+		TerminationDate: storedUser.TerminationDate,
+		EmploymentType:  storedUser.EmploymentTypeCode(),
+		IsActive:        storedUser.IsActive(time.Now()),
+	}, nil
+}
+
+func (uu *userUsecase) SetRole(userId uint, role string) (model.UserResponse, error) {
+	if role != model.RoleAdmin && role != model.RoleManager && role != model.RoleMember {
+		return model.UserResponse{}, model.NewInvalidError("invalid_role", "role must be admin, manager or member")
+	}
+	if err := uu.ur.SetRole(userId, role); err != nil {
+		return model.UserResponse{}, err
+	}
+	storedUser := model.User{}
+	if err := uu.ur.GetUserById(&storedUser, userId); err != nil {
+		return model.UserResponse{}, err
+	}
+	return model.UserResponse{
+		ID:              storedUser.ID,
+		Email:           storedUser.Email,
+		Name:            storedUser.Name,
+		Department:      storedUser.Department,
+		Role:            storedUser.Role,
+		HireDate:        storedUser.HireDate,
+		TerminationDate: storedUser.TerminationDate,
+		EmploymentType:  storedUser.EmploymentTypeCode(),
+		IsActive:        storedUser.IsActive(time.Now()),
+	}, nil
+}
+
+func (uu *userUsecase) DeactivateUser(userId uint) error {
+	now := time.Now()
+	return uu.ur.SetDeactivatedAt(userId, &now)
+}
+
+func (uu *userUsecase) ReactivateUser(userId uint) error {
+	return uu.ur.SetDeactivatedAt(userId, nil)
+}
+
+func (uu *userUsecase) PurgeExpiredUsers(now time.Time) (int64, error) {
+	years := defaultRetentionYears
+	if v := os.Getenv("USER_RETENTION_YEARS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid USER_RETENTION_YEARS: %s", v)
+		}
+		years = n
+	}
+	return uu.ur.PurgeUsers(now.AddDate(-years, 0, 0))
+}
+
+var errAccountInactive = model.NewUnauthorizedError("account_deactivated", "account is deactivated")
+
+// CheckActive は発行済みのトークンの持ち主が削除・無効化されていないかを確認する
+func (uu *userUsecase) CheckActive(userId uint) error {
+	user := model.User{}
+	if err := uu.ur.GetUserById(&user, userId); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return errAccountInactive
+		}
+		return err
+	}
+	if !user.IsActive(time.Now()) {
+		return errAccountInactive
+	}
+	return nil
+}
+
+// Authorize は呼び出し元のロールが roles のいずれかであるかを確認する
+func (uu *userUsecase) Authorize(userId uint, roles ...string) error {
+	user := model.User{}
+	if err := uu.ur.GetUserById(&user, userId); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrForbidden
+		}
+		return err
+	}
+	for _, v := range roles {
+		if user.Role == v {
+			return nil
+		}
+	}
+	return model.NewForbiddenError("insufficient_role", "this operation requires one of the roles: %s", strings.Join(roles, ", "))
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"go-rest-api/webhook"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type IWebhookUsecase interface {
+	GetSubscriptions() ([]model.WebhookSubscriptionResponse, error)
+	CreateSubscription(subscription model.WebhookSubscription) (model.WebhookSubscriptionResponse, error)
+	UpdateSubscription(subscription model.WebhookSubscription, active *bool, subscriptionId uint) (model.WebhookSubscriptionResponse, error)
+	DeleteSubscription(subscriptionId uint) error
+	GetDeliveries(subscriptionId uint) ([]model.WebhookDelivery, error)
+	Redeliver(deliveryId uint) (model.WebhookDelivery, error)
+	HandleEvent(event model.OutboxEvent) error
+	DispatchPending(now time.Time) (int, error)
+}
+
+type webhookUsecase struct {
+	wr repository.IWebhookRepository
+	wv validator.IWebhookValidator
+	wc webhook.IWebhookClient
+}
+
+func NewWebhookUsecase(wr repository.IWebhookRepository, wv validator.IWebhookValidator, wc webhook.IWebhookClient) IWebhookUsecase {
+	return &webhookUsecase{wr, wv, wc}
+}
+
+// 配信ログの一覧で返す件数
+const webhookDeliveryLogLimit = 100
+
+// webhookMaxAttempts は配信を諦めるまでの試行回数。WEBHOOK_MAX_ATTEMPTS で変更できる
+func webhookMaxAttempts() int {
+	return envInt("WEBHOOK_MAX_ATTEMPTS", 6)
+}
+
+// webhookBackoff は n 回目の失敗後に次の試行までに待つ時間。WEBHOOK_RETRY_BASE_SECONDS（既定 30 秒）から倍々に延ばし、最大 1 時間
+func webhookBackoff(attempts int) time.Duration {
+	backoff := time.Duration(envInt("WEBHOOK_RETRY_BASE_SECONDS", 30)) * time.Second
+	for i := 1; i < attempts && backoff < time.Hour; i++ {
+		backoff *= 2
+	}
+	if backoff > time.Hour {
+		backoff = time.Hour
+	}
+	return backoff
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func toWebhookSubscriptionResponse(subscription model.WebhookSubscription) model.WebhookSubscriptionResponse {
+	return model.WebhookSubscriptionResponse{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		Description: subscription.Description,
+		Events:      subscription.EventList(),
+		Active:      subscription.Active,
+		CreatedBy:   subscription.CreatedBy,
+		CreatedAt:   subscription.CreatedAt,
+		UpdatedAt:   subscription.UpdatedAt,
+	}
+}
+
+// normalizeEvents はイベント種別の重複と空白を取り除く
+func normalizeEvents(subscription *model.WebhookSubscription) {
+	seen := map[string]bool{}
+	events := []string{}
+	for _, v := range subscription.EventList() {
+		if !seen[v] {
+			seen[v] = true
+			events = append(events, v)
+		}
+	}
+	subscription.Events = strings.Join(events, ",")
+}
+
+func (wu *webhookUsecase) GetSubscriptions() ([]model.WebhookSubscriptionResponse, error) {
+	subscriptions := []model.WebhookSubscription{}
+	if err := wu.wr.GetSubscriptions(&subscriptions); err != nil {
+		return nil, err
+	}
+	resSubscriptions := make([]model.WebhookSubscriptionResponse, len(subscriptions))
+	for i, v := range subscriptions {
+		resSubscriptions[i] = toWebhookSubscriptionResponse(v)
+	}
+	return resSubscriptions, nil
+}
+
+// CreateSubscription は署名用のシークレットを発行する。シークレットは作成時の応答でのみ返す
+func (wu *webhookUsecase) CreateSubscription(subscription model.WebhookSubscription) (model.WebhookSubscriptionResponse, error) {
+	normalizeEvents(&subscription)
+	if err := wu.wv.WebhookSubscriptionValidate(subscription); err != nil {
+		return model.WebhookSubscriptionResponse{}, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return model.WebhookSubscriptionResponse{}, err
+	}
+	subscription.Secret = secret
+	subscription.Active = true
+	if err := wu.wr.CreateSubscription(&subscription); err != nil {
+		return model.WebhookSubscriptionResponse{}, err
+	}
+	res := toWebhookSubscriptionResponse(subscription)
+	res.Secret = secret
+	return res, nil
+}
+
+// UpdateSubscription は active が指定された場合だけ有効・無効を切り替える
+func (wu *webhookUsecase) UpdateSubscription(subscription model.WebhookSubscription, active *bool, subscriptionId uint) (model.WebhookSubscriptionResponse, error) {
+	normalizeEvents(&subscription)
+	if err := wu.wv.WebhookSubscriptionValidate(subscription); err != nil {
+		return model.WebhookSubscriptionResponse{}, err
+	}
+	if active != nil {
+		subscription.Active = *active
+	}
+	if err := wu.wr.UpdateSubscription(&subscription, subscriptionId, active != nil); err != nil {
+		return model.WebhookSubscriptionResponse{}, err
+	}
+	return toWebhookSubscriptionResponse(subscription), nil
+}
+
+func (wu *webhookUsecase) DeleteSubscription(subscriptionId uint) error {
+	return wu.wr.DeleteSubscription(subscriptionId)
+}
+
+func (wu *webhookUsecase) GetDeliveries(subscriptionId uint) ([]model.WebhookDelivery, error) {
+	subscription := model.WebhookSubscription{}
+	if err := wu.wr.GetSubscriptionById(&subscription, subscriptionId); err != nil {
+		return nil, err
+	}
+	deliveries := []model.WebhookDelivery{}
+	if err := wu.wr.GetDeliveries(&deliveries, subscriptionId, webhookDeliveryLogLimit); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Redeliver は同じ本文で新しい配信を作成する。元の配信の記録はそのまま残す
+func (wu *webhookUsecase) Redeliver(deliveryId uint) (model.WebhookDelivery, error) {
+	original := model.WebhookDelivery{}
+	if err := wu.wr.GetDeliveryById(&original, deliveryId); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	if !original.Subscription.Active {
+		return model.WebhookDelivery{}, model.NewConflictError("subscription_inactive", "subscription is inactive")
+	}
+	deliveries := []model.WebhookDelivery{{
+		SubscriptionID: original.SubscriptionID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         model.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOf:   &original.ID,
+	}}
+	if err := wu.wr.CreateDeliveries(deliveries); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return deliveries[0], nil
+}
+
+// HandleEvent はドメインイベントを購読している送信先ごとに配信を登録する。送信はディスパッチャーが行う。
+// 同じイベントが再処理されても登録済みの送信先には重複して作らない
+func (wu *webhookUsecase) HandleEvent(event model.OutboxEvent) error {
+	subscriptions := []model.WebhookSubscription{}
+	if err := wu.wr.GetActiveSubscriptions(&subscriptions); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(model.WebhookPayload{
+		EventID:    event.ID,
+		Event:      event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	deliveries := []model.WebhookDelivery{}
+	for _, v := range subscriptions {
+		if v.Subscribes(event.Type) {
+			deliveries = append(deliveries, model.WebhookDelivery{
+				SubscriptionID: v.ID,
+				OutboxEventID:  &event.ID,
+				Event:          event.Type,
+				Payload:        string(payload),
+				Status:         model.WebhookDeliveryPending,
+				NextAttemptAt:  now,
+			})
+		}
+	}
+	return wu.wr.CreateDeliveries(deliveries)
+}
+
+// deliver は1回送信を試み、結果に応じて成功・再試行待ち・失敗のいずれかにする
+func (wu *webhookUsecase) deliver(delivery *model.WebhookDelivery, now time.Time) {
+	timestamp := now.Unix()
+	body := []byte(delivery.Payload)
+	status, err := wu.wc.Deliver(delivery.Subscription.URL, body, map[string]string{
+		"X-Webhook-Event":     delivery.Event,
+		"X-Webhook-Delivery":  strconv.FormatUint(uint64(delivery.ID), 10),
+		"X-Webhook-Timestamp": strconv.FormatInt(timestamp, 10),
+		"X-Webhook-Signature": webhook.Sign(delivery.Subscription.Secret, timestamp, body),
+	})
+	delivery.Attempts++
+	delivery.LastStatusCode = status
+	if err == nil {
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts() {
+		delivery.Status = model.WebhookDeliveryFailed
+		return
+	}
+	delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+}
+
+// DispatchPending は送信時刻を迎えた配信を送信し、成功した件数を返す
+func (wu *webhookUsecase) DispatchPending(now time.Time) (int, error) {
+	deliveries := []model.WebhookDelivery{}
+	if err := wu.wr.GetDueDeliveries(&deliveries, now, 100); err != nil {
+		return 0, err
+	}
+	succeeded := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if !delivery.Subscription.Active {
+			// 無効化された送信先への未送信分は送らずに失敗として残す
+			delivery.Status = model.WebhookDeliveryFailed
+			delivery.LastError = "subscription is inactive"
+		} else {
+			wu.deliver(delivery, time.Now())
+		}
+		if err := wu.wr.UpdateDelivery(delivery); err != nil {
+			log.Printf("failed to update webhook delivery %d: %v", delivery.ID, err)
+			continue
+		}
+		if delivery.Status == model.WebhookDeliverySucceeded {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IEmploymentTypeValidator interface {
+	EmploymentTypeValidate(employmentType model.EmploymentType) error
+}
+
+type employmentTypeValidator struct{}
+
+func NewEmploymentTypeValidator() IEmploymentTypeValidator {
+	return &employmentTypeValidator{}
+}
+
+func (etv *employmentTypeValidator) EmploymentTypeValidate(employmentType model.EmploymentType) error {
+	return validation.ValidateStruct(&employmentType,
+		validation.Field(
+			&employmentType.Code,
+			validation.Required.Error("code is required"),
+			validation.In(model.EmploymentTypeFullTime, model.EmploymentTypePartTime, model.EmploymentTypeContract, model.EmploymentTypeDispatched).Error("code must be full_time, part_time, contract or dispatched"),
+		),
+		validation.Field(
+			&employmentType.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 50).Error("limited max 50 char"),
+		),
+		validation.Field(
+			&employmentType.ScheduledMinutesPerDay,
+			validation.Min(0).Error("scheduled minutes cannot be negative"),
+			validation.Max(24*60).Error("scheduled minutes cannot exceed 24 hours"),
+		),
+		validation.Field(
+			&employmentType.ScheduledStartTime,
+			validation.Match(regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)).Error("scheduled start time must be HH:MM"),
+		),
+		validation.Field(
+			&employmentType.OvertimeThresholdMinutes,
+			validation.Min(0).Error("overtime threshold cannot be negative"),
+			validation.Max(24*60).Error("overtime threshold cannot exceed 24 hours"),
+		),
+		validation.Field(
+			&employmentType.WeeklyOvertimeThreshold,
+			validation.Min(0).Error("weekly overtime threshold cannot be negative"),
+			validation.Max(7*24*60).Error("weekly overtime threshold cannot exceed 7 days"),
+		),
+		validation.Field(
+			&employmentType.LeaveGrants,
+			validation.Each(validation.By(func(value interface{}) error {
+				grant := value.(model.EmploymentTypeLeaveGrant)
+				if grant.ServiceMonths < 0 || grant.Days < 0 {
+					return validation.NewError("validation", "leave grant values cannot be negative")
+				}
+				return nil
+			})),
+		),
+	)
+}
@@ -0,0 +1,30 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IHolidayWorkRequestValidator interface {
+	HolidayWorkRequestValidate(request model.HolidayWorkRequest) error
+}
+
+type holidayWorkRequestValidator struct{}
+
+func NewHolidayWorkRequestValidator() IHolidayWorkRequestValidator {
+	return &holidayWorkRequestValidator{}
+}
+
+func (hv *holidayWorkRequestValidator) HolidayWorkRequestValidate(request model.HolidayWorkRequest) error {
+	return validation.ValidateStruct(&request,
+		validation.Field(
+			&request.Date,
+			validation.Required.Error("date is required"),
+		),
+		validation.Field(
+			&request.Reason,
+			validation.RuneLength(0, 500).Error("limited max 500 char"),
+		),
+	)
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type IInvitationValidator interface {
+	InvitationValidate(invitation model.Invitation) error
+	PasswordValidate(password string) error
+}
+
+type invitationValidator struct{}
+
+func NewInvitationValidator() IInvitationValidator {
+	return &invitationValidator{}
+}
+
+func (iv *invitationValidator) InvitationValidate(invitation model.Invitation) error {
+	return validation.ValidateStruct(&invitation,
+		validation.Field(
+			&invitation.Email,
+			validation.Required.Error("email is required"),
+			validation.RuneLength(1, 30).Error("limited max 30 char"),
+			is.Email.Error("is not valid email format"),
+		),
+		validation.Field(
+			&invitation.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 50).Error("limited max 50 char"),
+		),
+		validation.Field(
+			&invitation.Department,
+			validation.RuneLength(0, 50).Error("limited max 50 char"),
+		),
+		validation.Field(
+			&invitation.Role,
+			validation.Required.Error("role is required"),
+			validation.In(model.RoleAdmin, model.RoleManager, model.RoleMember).Error("role must be admin, manager or member"),
+		),
+	)
+}
+
+func (iv *invitationValidator) PasswordValidate(password string) error {
+	return validation.Validate(password,
+		validation.Required.Error("password is required"),
+		validation.RuneLength(6, 30).Error("limited min 6 max 30 char"),
+	)
+}
@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type ILeaveValidator interface {
+	LeaveValidate(leave model.LeaveRecord) error
+}
+
+type leaveValidator struct{}
+
+func NewLeaveValidator() ILeaveValidator {
+	return &leaveValidator{}
+}
+
+func (lv *leaveValidator) LeaveValidate(leave model.LeaveRecord) error {
+	return validation.ValidateStruct(&leave,
+		validation.Field(
+			&leave.Date,
+			validation.Required.Error("date is required"),
+		),
+		validation.Field(
+			&leave.Days,
+			validation.Required.Error("days is required"),
+			validation.In(0.5, 1.0).Error("days must be 0.5 or 1"),
+		),
+		validation.Field(
+			&leave.Note,
+			validation.RuneLength(0, 200).Error("limited max 200 char"),
+		),
+	)
+}
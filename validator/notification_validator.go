@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type INotificationValidator interface {
+	NotificationSettingValidate(setting model.NotificationSetting) error
+	NotificationPreferenceValidate(preference model.NotificationPreference) error
+}
+
+type notificationValidator struct{}
+
+func NewNotificationValidator() INotificationValidator {
+	return &notificationValidator{}
+}
+
+func (nv *notificationValidator) NotificationSettingValidate(setting model.NotificationSetting) error {
+	return validation.ValidateStruct(&setting,
+		validation.Field(
+			&setting.Locale,
+			validation.Required.Error("locale is required"),
+			validation.In(model.LocaleJa, model.LocaleEn).Error("locale must be ja or en"),
+		),
+		validation.Field(
+			&setting.WebhookURL,
+			is.URL.Error("webhook_url is not a valid URL"),
+			validation.Match(regexp.MustCompile(`^https://`)).Error("webhook_url must use https"),
+		),
+		validation.Field(
+			&setting.ClockInReminderMinutes,
+			validation.Min(0).Error("clock_in_reminder_minutes must be 0 or more"),
+			validation.Max(12*60).Error("clock_in_reminder_minutes must be 720 or less"),
+		),
+		validation.Field(
+			&setting.ClockOutReminderMinutes,
+			validation.Min(0).Error("clock_out_reminder_minutes must be 0 or more"),
+			validation.Max(12*60).Error("clock_out_reminder_minutes must be 720 or less"),
+		),
+	)
+}
+
+func (nv *notificationValidator) NotificationPreferenceValidate(preference model.NotificationPreference) error {
+	events := make([]interface{}, len(model.NotificationEvents))
+	for i, v := range model.NotificationEvents {
+		events[i] = v
+	}
+	return validation.ValidateStruct(&preference,
+		validation.Field(
+			&preference.Event,
+			validation.Required.Error("event is required"),
+			validation.In(events...).Error("unknown notification event"),
+		),
+		validation.Field(
+			&preference.Channel,
+			validation.Required.Error("channel is required"),
+			validation.In(model.NotificationChannelEmail, model.NotificationChannelWebhook, model.NotificationChannelInApp).Error("channel must be email, webhook or in_app"),
+		),
+	)
+}
@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IProjectValidator interface {
+	ProjectValidate(project model.Project) error
+	TimeAllocationValidate(allocation model.TimeAllocation) error
+	ProjectRateValidate(rate model.ProjectRate) error
+	ProjectMemberValidate(member model.ProjectMember) error
+}
+
+type projectValidator struct{}
+
+func NewProjectValidator() IProjectValidator {
+	return &projectValidator{}
+}
+
+func (pv *projectValidator) ProjectValidate(project model.Project) error {
+	return validation.ValidateStruct(&project,
+		validation.Field(
+			&project.Code,
+			validation.Required.Error("code is required"),
+			validation.RuneLength(1, 20).Error("limited max 20 char"),
+		),
+		validation.Field(
+			&project.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 100).Error("limited max 100 char"),
+		),
+		validation.Field(
+			&project.Client,
+			validation.RuneLength(0, 100).Error("limited max 100 char"),
+		),
+		validation.Field(
+			&project.BudgetHours,
+			validation.Min(0.0).Error("budget hours cannot be negative"),
+		),
+		validation.Field(
+			&project.HourlyRate,
+			validation.Min(0.0).Error("hourly rate cannot be negative"),
+		),
+		validation.Field(
+			&project.EndDate,
+			validation.By(func(value interface{}) error {
+				endDate := value.(*time.Time)
+				if endDate != nil && project.StartDate != nil && endDate.Before(*project.StartDate) {
+					return validation.NewError("validation", "end date cannot be before start date")
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+func (pv *projectValidator) TimeAllocationValidate(allocation model.TimeAllocation) error {
+	return validation.ValidateStruct(&allocation,
+		validation.Field(
+			&allocation.Minutes,
+			validation.Required.Error("minutes is required"),
+			validation.Min(1).Error("minutes must be positive"),
+			validation.Max(24*60).Error("minutes cannot exceed 24 hours"),
+		),
+		validation.Field(
+			&allocation.ProjectID,
+			validation.By(func(value interface{}) error {
+				if value.(*uint) == nil && allocation.TaskID == nil {
+					return validation.NewError("validation", "task_id or project_id is required")
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&allocation.Note,
+			validation.RuneLength(0, 200).Error("limited max 200 char"),
+		),
+	)
+}
+
+func (pv *projectValidator) ProjectRateValidate(rate model.ProjectRate) error {
+	return validation.ValidateStruct(&rate,
+		validation.Field(
+			&rate.Role,
+			validation.Required.Error("role is required"),
+			validation.RuneLength(1, 50).Error("limited max 50 char"),
+		),
+		validation.Field(
+			&rate.HourlyRate,
+			validation.Min(0.0).Error("hourly rate cannot be negative"),
+		),
+	)
+}
+
+func (pv *projectValidator) ProjectMemberValidate(member model.ProjectMember) error {
+	return validation.ValidateStruct(&member,
+		validation.Field(
+			&member.UserID,
+			validation.Required.Error("user_id is required"),
+		),
+		validation.Field(
+			&member.BillingRole,
+			validation.RuneLength(0, 50).Error("limited max 50 char"),
+		),
+	)
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IReportingLineValidator interface {
+	ReportingLineValidate(line model.ReportingLine) error
+}
+
+type reportingLineValidator struct{}
+
+func NewReportingLineValidator() IReportingLineValidator {
+	return &reportingLineValidator{}
+}
+
+func (rlv *reportingLineValidator) ReportingLineValidate(line model.ReportingLine) error {
+	return validation.ValidateStruct(&line,
+		validation.Field(
+			&line.UserID,
+			validation.Required.Error("user_id is required"),
+		),
+		validation.Field(
+			&line.ManagerID,
+			validation.Required.Error("manager_id is required"),
+			validation.NotIn(line.UserID).Error("user cannot report to themselves"),
+		),
+		validation.Field(
+			&line.Type,
+			validation.Required.Error("type is required"),
+			validation.In(model.ReportingLineTypePrimary, model.ReportingLineTypeActing, model.ReportingLineTypeDelegate).Error("type must be primary, acting or delegate"),
+		),
+		validation.Field(
+			&line.EffectiveFrom,
+			validation.Required.Error("effective_from is required"),
+		),
+		validation.Field(
+			&line.EffectiveTo,
+			validation.By(func(value interface{}) error {
+				effectiveTo := value.(*time.Time)
+				if effectiveTo != nil && effectiveTo.Before(line.EffectiveFrom) {
+					return validation.NewError("validation", "effective_to cannot be before effective_from")
+				}
+				return nil
+			}),
+		),
+	)
+}
@@ -2,12 +2,15 @@ package validator
 
 import (
 	"go-rest-api/model"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 type ITaskValidator interface {
 	TaskValidate(task model.Task) error
+	TaskCommentValidate(comment model.TaskComment) error
+	TaskRecurrenceValidate(recurrence model.TaskRecurrence) error
 }
 
 type taskValidator struct{}
@@ -21,7 +24,70 @@ func (tv *taskValidator) TaskValidate(task model.Task) error {
 		validation.Field(
 			&task.Title,
 			validation.Required.Error("title is required"),
-			validation.RuneLength(1, 10).Error("limited max 10 char"),
+			validation.RuneLength(1, 100).Error("limited max 100 char"),
+		),
+		validation.Field(
+			&task.Description,
+			validation.RuneLength(0, 2000).Error("limited max 2000 char"),
+		),
+		validation.Field(
+			&task.Status,
+			validation.In(model.TaskStatusTodo, model.TaskStatusInProgress, model.TaskStatusDone, model.TaskStatusBlocked).Error("status must be todo, in_progress, done or blocked"),
+		),
+		validation.Field(
+			&task.Priority,
+			validation.In(model.TaskPriorityLow, model.TaskPriorityMedium, model.TaskPriorityHigh, model.TaskPriorityUrgent).Error("priority must be low, medium, high or urgent"),
+		),
+		validation.Field(
+			&task.Visibility,
+			validation.In(model.TaskVisibilityPrivate, model.TaskVisibilityDepartment).Error("visibility must be private or department"),
+		),
+		validation.Field(
+			&task.Department,
+			validation.When(task.Visibility == model.TaskVisibilityDepartment, validation.Required.Error("department is required for shared tasks")),
+		),
+		validation.Field(
+			&task.EstimatedHours,
+			validation.Min(0.0).Error("estimated hours cannot be negative"),
+		),
+	)
+}
+
+func (tv *taskValidator) TaskCommentValidate(comment model.TaskComment) error {
+	return validation.ValidateStruct(&comment,
+		validation.Field(
+			&comment.Body,
+			validation.Required.Error("body is required"),
+			validation.RuneLength(1, 2000).Error("limited max 2000 char"),
+		),
+	)
+}
+
+func (tv *taskValidator) TaskRecurrenceValidate(recurrence model.TaskRecurrence) error {
+	return validation.ValidateStruct(&recurrence,
+		validation.Field(
+			&recurrence.RRule,
+			validation.Required.Error("rrule is required"),
+			validation.By(func(value interface{}) error {
+				if _, err := model.ParseRecurrenceRule(value.(string)); err != nil {
+					return validation.NewError("validation", err.Error())
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&recurrence.StartDate,
+			validation.Required.Error("start_date is required"),
+		),
+		validation.Field(
+			&recurrence.Until,
+			validation.By(func(value interface{}) error {
+				until := value.(*time.Time)
+				if until != nil && until.Before(recurrence.StartDate) {
+					return validation.NewError("validation", "until cannot be before start date")
+				}
+				return nil
+			}),
 		),
 	)
 }
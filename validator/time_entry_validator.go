@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type ITimeEntryValidator interface {
+	ManualEntryValidate(entry model.TimeEntry) error
+}
+
+type timeEntryValidator struct{}
+
+func NewTimeEntryValidator() ITimeEntryValidator {
+	return &timeEntryValidator{}
+}
+
+func (tev *timeEntryValidator) ManualEntryValidate(entry model.TimeEntry) error {
+	return validation.ValidateStruct(&entry,
+		validation.Field(
+			&entry.TaskID,
+			validation.Required.Error("task_id is required"),
+		),
+		validation.Field(
+			&entry.StartedAt,
+			validation.Required.Error("started_at is required"),
+			validation.Max(time.Now()).Error("started_at cannot be in the future"),
+		),
+		validation.Field(
+			&entry.EndedAt,
+			validation.Required.Error("ended_at is required"),
+			validation.By(func(value interface{}) error {
+				endedAt := value.(*time.Time)
+				if endedAt == nil {
+					return nil
+				}
+				if endedAt.After(time.Now()) {
+					return validation.NewError("validation", "ended_at cannot be in the future")
+				}
+				if !endedAt.After(entry.StartedAt) {
+					return validation.NewError("validation", "ended_at must be after started_at")
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&entry.Note,
+			validation.RuneLength(0, 200).Error("limited max 200 char"),
+		),
+	)
+}
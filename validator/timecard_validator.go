@@ -11,6 +11,7 @@ type IAttendanceRecordValidator interface {
 	Validate(record model.AttendanceRecord) error
 	ValidateClockIn(record model.AttendanceRecord) error
 	ValidateClockOut(record model.AttendanceRecord) error
+	ValidateBreakTime(at time.Time) error
 }
 
 type attendanceRecordValidator struct{}
@@ -63,3 +64,11 @@ func (arv *attendanceRecordValidator) ValidateClockOut(record model.AttendanceRe
 		),
 	)
 }
+
+// ValidateBreakTime は休憩の開始・終了の打刻時刻を検証する
+func (arv *attendanceRecordValidator) ValidateBreakTime(at time.Time) error {
+	return validation.Validate(at,
+		validation.Required.Error("break time is required"),
+		validation.Max(time.Now()).Error("break time cannot be in the future"),
+	)
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type IWebhookValidator interface {
+	WebhookSubscriptionValidate(subscription model.WebhookSubscription) error
+}
+
+type webhookValidator struct{}
+
+func NewWebhookValidator() IWebhookValidator {
+	return &webhookValidator{}
+}
+
+func (wv *webhookValidator) WebhookSubscriptionValidate(subscription model.WebhookSubscription) error {
+	events := make([]interface{}, len(model.WebhookEvents))
+	for i, v := range model.WebhookEvents {
+		events[i] = v
+	}
+	return validation.ValidateStruct(&subscription,
+		validation.Field(
+			&subscription.URL,
+			validation.Required.Error("url is required"),
+			is.URL.Error("url is not a valid URL"),
+		),
+		validation.Field(
+			&subscription.Events,
+			validation.Required.Error("events is required"),
+			validation.By(func(value interface{}) error {
+				return validation.Validate(subscription.EventList(),
+					validation.Required.Error("events is required"),
+					validation.Each(validation.In(events...).Error("unknown webhook event")),
+				)
+			}),
+		),
+	)
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type IWebhookClient interface {
+	Post(url string, payload interface{}) error
+	Deliver(url string, body []byte, headers map[string]string) (int, error)
+}
+
+type httpWebhookClient struct {
+	client *http.Client
+}
+
+// NewWebhookClient は送信先が利用者の指定した URL になるため、内部のアドレスには接続しないクライアントを返す。
+// 開発環境でローカルの受信先を使う場合は WEBHOOK_ALLOW_PRIVATE_NETWORKS=true にする
+func NewWebhookClient() IWebhookClient {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS") != "true" {
+		dialer.Control = denyPrivateAddress
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+	return &httpWebhookClient{client: &http.Client{Timeout: 10 * time.Second, Transport: transport}}
+}
+
+// deniedNetworks は IsGlobalUnicast でも外部から到達できない共有・予約済みのアドレス範囲
+var deniedNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",     // このネットワーク
+		"100.64.0.0/10", // キャリアグレード NAT
+		"192.0.0.0/24",  // IETF プロトコル割り当て
+		"198.18.0.0/15", // ベンチマーク用
+		"240.0.0.0/4",   // 予約済み
+		"64:ff9b::/96",  // NAT64
+	}
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, v := range cidrs {
+		_, network, err := net.ParseCIDR(v)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}()
+
+// denyPrivateAddress は名前解決後の接続先を確認し、グローバルユニキャスト以外とプライベート・共有アドレスへの接続を拒否する。
+// 接続のたびに確認するため、リダイレクト先や DNS の応答が変わった場合も対象になる
+func denyPrivateAddress(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !allowedAddress(net.ParseIP(host)) {
+		return fmt.Errorf("webhook destination %s is not allowed", host)
+	}
+	return nil
+}
+
+func allowedAddress(ip net.IP) bool {
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, v := range deniedNetworks {
+		if v.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign は受信側が検証する署名を返す。署名対象は "タイムスタンプ.本文" で、
+// X-Webhook-Signature ヘッダーに sha256=<HMAC-SHA256 の16進数> の形式で付ける
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Post は payload を JSON にして送信する。2xx 以外の応答はエラーにする
+func (w *httpWebhookClient) Post(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = w.Deliver(url, body, nil)
+	return err
+}
+
+// Deliver は本文をそのまま送信し、応答のステータスコードを返す。2xx 以外の応答はエラーにする
+func (w *httpWebhookClient) Deliver(url string, body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}